@@ -15,6 +15,13 @@ import (
 	"rubick/internal/database"
 	"rubick/internal/docker"
 	"rubick/internal/handler"
+	"rubick/internal/inventory"
+	"rubick/internal/logstore"
+	"rubick/internal/monitor"
+	"rubick/internal/recording"
+	"rubick/internal/scheduler"
+	"rubick/internal/stream"
+	"rubick/internal/updater"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -60,6 +67,12 @@ func main() {
 	}
 	defer database.Close()
 
+	// 初始化日志存储数据库
+	if _, err := database.InitializeLogStore(cfg.LogStore.Path); err != nil {
+		log.Fatalf("初始化日志存储失败: %v", err)
+	}
+	defer database.CloseLogStore()
+
 	// 输出启动信息
 	log.Printf("Rubick %s 启动中...", Version)
 	log.Printf("数据库: %s", cfg.Database.Path)
@@ -68,6 +81,34 @@ func main() {
 	dockerManager := docker.GetManager()
 	defer dockerManager.CloseAll()
 
+	// 启动自动更新调度
+	imageUpdater := updater.GetUpdater()
+	imageUpdater.Start()
+
+	// 启动定时任务调度
+	taskScheduler := scheduler.GetScheduler()
+	taskScheduler.Start()
+
+	// 启动主机健康检查
+	hostMonitor := monitor.GetMonitor()
+	hostMonitor.Start()
+
+	// 启动资源清单缓存刷新
+	resourceInventory := inventory.GetInventory()
+	resourceInventory.Start()
+
+	// 启动集中日志收集
+	logCollector := logstore.GetCollector()
+	logCollector.Start()
+
+	// 启动实时数据主题分发
+	streamHub := stream.GetHub()
+	streamHub.Start()
+
+	// 启动过期终端会话录像清理
+	recordingPruner := recording.GetPruner()
+	recordingPruner.Start()
+
 	// 创建路由
 	router := handler.NewRouter()
 	engine := router.Setup()
@@ -108,6 +149,15 @@ func main() {
 		log.Printf("服务器关闭错误: %v", err)
 	}
 
+	// 停止后台调度
+	imageUpdater.Stop()
+	taskScheduler.Stop()
+	hostMonitor.Stop()
+	resourceInventory.Stop()
+	logCollector.Stop()
+	streamHub.Stop()
+	recordingPruner.Stop()
+
 	// 关闭数据库连接
 	if sqlDB, err := db.DB(); err == nil {
 		sqlDB.Close()
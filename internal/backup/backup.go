@@ -0,0 +1,248 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"rubick/internal/config"
+	"rubick/internal/docker"
+	"rubick/internal/model"
+	"rubick/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// 备份来源
+const (
+	SourceManual   = "manual"
+	SourceSchedule = "schedule"
+	SourceUpload   = "upload"
+)
+
+// volumeNameRegexp Docker 卷名称格式
+var volumeNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// ValidateVolumeName 校验卷名称，卷名称会用于备份文件名
+func ValidateVolumeName(name string) error {
+	if !volumeNameRegexp.MatchString(name) {
+		return fmt.Errorf("无效的卷名称: %q", name)
+	}
+	return nil
+}
+
+// Path 返回备份文件的完整路径
+func Path(b *model.VolumeBackup) string {
+	return filepath.Join(config.Get().Backup.Dir, filepath.FromSlash(b.FileName))
+}
+
+// Create 备份主机上的卷，保存备份文件和记录
+func Create(ctx context.Context, host *model.Host, volume, source, taskID string) (*model.VolumeBackup, error) {
+	if err := ValidateVolumeName(volume); err != nil {
+		return nil, err
+	}
+
+	cli, err := docker.GetManager().GetDockerClient(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	b := newBackup(host.ID, volume, source, ".tar.gz")
+	b.TaskID = taskID
+
+	err = writeFile(b, func(w io.Writer) error {
+		return docker.NewVolumeService(cli).Backup(ctx, volume, config.Get().Backup.HelperImage, w)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, save(b)
+}
+
+// Import 保存上传的 tar 归档（可为 gzip 压缩）作为卷备份
+func Import(hostID, volume string, r io.Reader) (*model.VolumeBackup, error) {
+	if err := ValidateVolumeName(volume); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(2)
+	ext := ".tar"
+	if docker.IsGzip(header) {
+		ext = ".tar.gz"
+	}
+
+	b := newBackup(hostID, volume, SourceUpload, ext)
+	err := writeFile(b, func(w io.Writer) error {
+		_, err := io.Copy(w, br)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Size == 0 {
+		os.Remove(Path(b))
+		return nil, fmt.Errorf("上传的文件为空")
+	}
+	if err := validateArchive(Path(b)); err != nil {
+		os.Remove(Path(b))
+		return nil, err
+	}
+	return b, save(b)
+}
+
+// Restore 将备份恢复到主机上的卷中，卷不存在时自动创建
+func Restore(ctx context.Context, b *model.VolumeBackup, host *model.Host, volume string) error {
+	if err := ValidateVolumeName(volume); err != nil {
+		return err
+	}
+
+	cli, err := docker.GetManager().GetDockerClient(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(Path(b))
+	if err != nil {
+		return fmt.Errorf("打开备份文件失败: %w", err)
+	}
+	defer f.Close()
+
+	return docker.NewVolumeService(cli).Restore(ctx, volume, config.Get().Backup.HelperImage, f)
+}
+
+// Delete 删除备份文件和记录
+func Delete(b *model.VolumeBackup) error {
+	if err := os.Remove(Path(b)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除备份文件失败: %w", err)
+	}
+	return repository.DeleteVolumeBackup(b.ID)
+}
+
+// Prune 只保留定时任务最近的 retain 个备份，返回删除的数量
+func Prune(taskID string, retain int) (int, error) {
+	if retain <= 0 {
+		return 0, nil
+	}
+
+	backups, err := repository.ListVolumeBackupsByTask(taskID)
+	if err != nil {
+		return 0, err
+	}
+	if len(backups) <= retain {
+		return 0, nil
+	}
+
+	deleted := 0
+	for i := range backups[retain:] {
+		if err := Delete(&backups[retain+i]); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// newBackup 创建备份记录并生成文件名：<主机 ID>/<卷名>-<时间>-<ID 前缀><扩展名>
+func newBackup(hostID, volume, source, ext string) *model.VolumeBackup {
+	id := uuid.New().String()
+	return &model.VolumeBackup{
+		ID:       id,
+		HostID:   hostID,
+		Volume:   volume,
+		Source:   source,
+		FileName: path.Join(hostID, fmt.Sprintf("%s-%s-%s%s", volume, time.Now().Format("20060102-150405"), id[:8], ext)),
+	}
+}
+
+// writeFile 写入备份文件，同时计算大小和校验和
+// 先写入临时文件，完成后再重命名，避免留下不完整的备份
+func writeFile(b *model.VolumeBackup, write func(io.Writer) error) error {
+	full := Path(b)
+	if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+		return fmt.Errorf("创建备份目录失败: %w", err)
+	}
+
+	tmp := full + ".part"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return fmt.Errorf("创建备份文件失败: %w", err)
+	}
+
+	hash := sha256.New()
+	counter := &countingWriter{}
+	err = write(io.MultiWriter(f, hash, counter))
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("写入备份文件失败: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, full); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("保存备份文件失败: %w", err)
+	}
+
+	b.Size = counter.n
+	b.Checksum = hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+// save 保存备份记录，失败时删除备份文件
+func save(b *model.VolumeBackup) error {
+	if err := repository.CreateVolumeBackup(b); err != nil {
+		os.Remove(Path(b))
+		return fmt.Errorf("保存备份记录失败: %w", err)
+	}
+	return nil
+}
+
+// validateArchive 校验备份文件是否为完整的 tar 归档
+func validateArchive(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = bufio.NewReader(f)
+	if path.Ext(file) == ".gz" {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("无效的 gzip 文件: %w", err)
+		}
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		if _, err := tr.Next(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("无效的 tar 归档: %w", err)
+		}
+	}
+}
+
+// countingWriter 统计写入的字节数
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
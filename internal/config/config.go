@@ -3,16 +3,22 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config 应用配置结构
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Docker   DockerConfig   `mapstructure:"docker"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Docker    DockerConfig    `mapstructure:"docker"`
+	Logging   LoggingConfig   `mapstructure:"logging"`
+	Backup    BackupConfig    `mapstructure:"backup"`
+	Monitor   MonitorConfig   `mapstructure:"monitor"`
+	Inventory InventoryConfig `mapstructure:"inventory"`
+	LogStore  LogStoreConfig  `mapstructure:"log_store"`
+	Recording RecordingConfig `mapstructure:"recording"`
 }
 
 // ServerConfig 服务器配置
@@ -39,6 +45,41 @@ type LoggingConfig struct {
 	Format string `mapstructure:"format"` // json, text
 }
 
+// BackupConfig 卷备份配置
+type BackupConfig struct {
+	Dir         string `mapstructure:"dir"`          // 备份文件保存目录
+	HelperImage string `mapstructure:"helper_image"` // 读写卷数据的辅助容器镜像
+}
+
+// MonitorConfig 主机健康检查配置
+type MonitorConfig struct {
+	Interval  time.Duration `mapstructure:"interval"`  // 检查间隔
+	Timeout   time.Duration `mapstructure:"timeout"`   // 单次检查超时
+	Retention time.Duration `mapstructure:"retention"` // 检查记录保留时长
+}
+
+// InventoryConfig 资源清单缓存配置
+type InventoryConfig struct {
+	Interval time.Duration `mapstructure:"interval"` // 全量同步间隔，两次同步之间通过 Docker 事件增量更新
+	Timeout  time.Duration `mapstructure:"timeout"`  // 单台主机同步超时
+}
+
+// LogStoreConfig 集中日志存储配置
+type LogStoreConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Path         string        `mapstructure:"path"`          // 日志数据库文件路径，与业务数据库分开存放
+	Selectors    []string      `mapstructure:"selectors"`     // 容器标签选择器，如 app=web,tier=backend，满足任一选择器的容器会被收集
+	Retention    time.Duration `mapstructure:"retention"`     // 日志保留时长
+	MaxSizeMB    int64         `mapstructure:"max_size_mb"`   // 存储大小上限，超出时删除最早的日志
+	SyncInterval time.Duration `mapstructure:"sync_interval"` // 重新发现匹配容器的间隔
+}
+
+// RecordingConfig 终端会话录像配置
+type RecordingConfig struct {
+	Dir       string        `mapstructure:"dir"`       // 录像文件保存目录
+	Retention time.Duration `mapstructure:"retention"` // 默认保留时长，主机可单独设置，0 表示永久保留
+}
+
 var cfg *Config
 
 // Load 加载配置文件
@@ -97,6 +138,31 @@ func setDefaults(v *viper.Viper) {
 	// 日志配置
 	v.SetDefault("logging.level", "info")
 	v.SetDefault("logging.format", "json")
+
+	// 备份配置
+	v.SetDefault("backup.dir", "./data/backups")
+	v.SetDefault("backup.helper_image", "busybox:latest")
+
+	// 健康检查配置
+	v.SetDefault("monitor.interval", "30s")
+	v.SetDefault("monitor.timeout", "5s")
+	v.SetDefault("monitor.retention", "168h")
+
+	// 资源清单缓存配置
+	v.SetDefault("inventory.interval", "5m")
+	v.SetDefault("inventory.timeout", "30s")
+
+	// 集中日志存储配置
+	v.SetDefault("log_store.enabled", false)
+	v.SetDefault("log_store.path", "./data/logs.db")
+	v.SetDefault("log_store.selectors", []string{"rubick.logs=true"})
+	v.SetDefault("log_store.retention", "168h")
+	v.SetDefault("log_store.max_size_mb", 1024)
+	v.SetDefault("log_store.sync_interval", "30s")
+
+	// 终端会话录像配置
+	v.SetDefault("recording.dir", "./data/recordings")
+	v.SetDefault("recording.retention", "720h")
 }
 
 // Get 获取当前配置
@@ -124,6 +190,26 @@ func Get() *Config {
 					Level:  "info",
 					Format: "json",
 				},
+				Backup: BackupConfig{
+					Dir:         "./data/backups",
+					HelperImage: "busybox:latest",
+				},
+				Monitor: MonitorConfig{
+					Interval:  30 * time.Second,
+					Timeout:   5 * time.Second,
+					Retention: 7 * 24 * time.Hour,
+				},
+				Inventory: InventoryConfig{
+					Interval: 5 * time.Minute,
+					Timeout:  30 * time.Second,
+				},
+				LogStore: LogStoreConfig{
+					Path: "./data/logs.db",
+				},
+				Recording: RecordingConfig{
+					Dir:       "./data/recordings",
+					Retention: 30 * 24 * time.Hour,
+				},
 			}
 		}
 	}
@@ -0,0 +1,323 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// 内嵌时区数据，保证精简镜像中也能解析时区
+	_ "time/tzdata"
+)
+
+// Schedule 解析后的 cron 表达式
+// 支持标准 5 段格式（分 时 日 月 周）以及 @hourly、@daily 等描述符
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar/dowStar 标记日、周字段是否为 *，用于决定二者的组合方式
+	domStar, dowStar bool
+}
+
+// allHours 小时字段为 * 时的位图
+const allHours = 1<<24 - 1
+
+// bounds 字段取值范围
+type bounds struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	minuteBounds = bounds{0, 59, nil}
+	hourBounds   = bounds{0, 23, nil}
+	domBounds    = bounds{1, 31, nil}
+	monthBounds  = bounds{1, 12, map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 周字段允许用 7 表示周日，解析后合并到 0
+	dowBounds = bounds{0, 7, map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// descriptors 预定义的描述符
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse 解析 cron 表达式
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if spec, ok := descriptors[strings.ToLower(expr)]; ok {
+		expr = spec
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron 表达式必须包含 5 个字段，实际为 %d 个: %q", len(fields), expr)
+	}
+
+	s := &Schedule{
+		domStar: fields[2] == "*" || fields[2] == "?",
+		dowStar: fields[4] == "*" || fields[4] == "?",
+	}
+
+	var err error
+	if s.minute, err = parseField(fields[0], minuteBounds); err != nil {
+		return nil, fmt.Errorf("分钟字段无效: %w", err)
+	}
+	if s.hour, err = parseField(fields[1], hourBounds); err != nil {
+		return nil, fmt.Errorf("小时字段无效: %w", err)
+	}
+	if s.dom, err = parseField(fields[2], domBounds); err != nil {
+		return nil, fmt.Errorf("日期字段无效: %w", err)
+	}
+	if s.month, err = parseField(fields[3], monthBounds); err != nil {
+		return nil, fmt.Errorf("月份字段无效: %w", err)
+	}
+	if s.dow, err = parseField(fields[4], dowBounds); err != nil {
+		return nil, fmt.Errorf("星期字段无效: %w", err)
+	}
+	if has(s.dow, 7) {
+		s.dow = s.dow&^(1<<7) | 1<<0
+	}
+
+	return s, nil
+}
+
+// Validate 检查 cron 表达式是否有效
+func Validate(expr string) error {
+	_, err := Parse(expr)
+	return err
+}
+
+// Next 返回严格晚于 t 的下一次触发时间，时间计算使用 t 所在的时区
+// 如果 5 年内都没有匹配的时间（例如 2 月 30 日），返回零值
+//
+// 夏令时切换时与传统 cron 一致：指定了小时的计划，计划时间被跳过时在跳变后立即触发，
+// 重复的时段只在第一次经过时触发；小时为 * 的计划按实际经过的时间触发
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	yearLimit := t.Year() + 5
+
+WRAP:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for !has(s.month, uint(t.Month())) {
+		t = dayStart(t.Year(), t.Month()+1, 1, loc)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+
+	for !s.dayMatches(t) {
+		t = dayStart(t.Year(), t.Month(), t.Day()+1, loc)
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+
+	// 午夜被跳过时当天从跳变的时刻开始，被跳过的小时同样在跳变后触发
+	if t.Hour() != 0 && s.hour != allHours && t.Equal(dayStart(t.Year(), t.Month(), t.Day(), loc)) && s.skipped(-1, t.Hour()) {
+		return t
+	}
+
+	for !has(s.hour, uint(t.Hour())) {
+		// 按绝对时间前进到下一个整点，夏令时切换时也总是向前推进
+		prevHour, prevDay := t.Hour(), t.Day()
+		t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		if t.Day() != prevDay {
+			goto WRAP
+		}
+		if s.hour != allHours && s.skipped(prevHour, t.Hour()) {
+			return t
+		}
+	}
+
+	for !has(s.minute, uint(t.Minute())) {
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+
+	if s.hour != allHours {
+		if end := repeatedUntil(t); !end.IsZero() {
+			t = end
+			goto WRAP
+		}
+	}
+
+	return t
+}
+
+// NextIn 在指定时区中计算下一次触发时间，时区为空时使用 UTC
+func NextIn(expr, timezone string, after time.Time) (time.Time, error) {
+	schedule, err := Parse(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	loc, err := LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	next := schedule.Next(after.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron 表达式没有可触发的时间: %q", expr)
+	}
+	return next, nil
+}
+
+// LoadLocation 加载时区，为空时返回 UTC
+func LoadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("无效的时区 %q: %w", timezone, err)
+	}
+	return loc, nil
+}
+
+// skipped 判断从 from 时前进到 to 时是否跳过了计划中的小时
+func (s *Schedule) skipped(from, to int) bool {
+	for h := from + 1; h < to; h++ {
+		if has(s.hour, uint(h)) {
+			return true
+		}
+	}
+	return false
+}
+
+// dayStart 返回指定日期在 loc 中的第一个时刻
+// 午夜处于夏令时跳过的时段时，time.Date 会得到前一天跳变之前的时间，此时改为跳变的时刻
+func dayStart(year int, month time.Month, day int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if t.Hour() != 0 {
+		_, end := t.ZoneBounds()
+		t = end
+	}
+	return t
+}
+
+// repeatedUntil 判断 t 是否处于夏令时结束后第二次经过的时段，是则返回该时段结束的时刻，否则返回零值
+func repeatedUntil(t time.Time) time.Time {
+	start, _ := t.ZoneBounds()
+	if start.IsZero() {
+		return time.Time{}
+	}
+	_, offset := t.Zone()
+	_, prevOffset := start.Add(-time.Second).Zone()
+	end := start.Add(time.Duration(prevOffset-offset) * time.Second)
+	if t.Before(end) {
+		return end
+	}
+	return time.Time{}
+}
+
+// dayMatches 判断日期是否匹配日、周字段
+// 与传统 cron 一致：两个字段都被限制时满足其一即可
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := has(s.dom, uint(t.Day()))
+	dowMatch := has(s.dow, uint(t.Weekday()))
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseField 解析单个字段，返回位图
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		partBits, err := parseRange(part, b)
+		if err != nil {
+			return 0, err
+		}
+		bits |= partBits
+	}
+	return bits, nil
+}
+
+// parseRange 解析 "*"、"a"、"a-b"、"*/n"、"a-b/n" 等形式
+func parseRange(expr string, b bounds) (uint64, error) {
+	rangeExpr, step := expr, uint(1)
+	if i := strings.Index(expr, "/"); i >= 0 {
+		n, err := strconv.ParseUint(expr[i+1:], 10, 32)
+		if err != nil || n == 0 {
+			return 0, fmt.Errorf("无效的步长: %q", expr)
+		}
+		rangeExpr, step = expr[:i], uint(n)
+	}
+
+	var start, end uint
+	switch {
+	case rangeExpr == "*" || rangeExpr == "?":
+		start, end = b.min, b.max
+	case strings.Contains(rangeExpr, "-"):
+		parts := strings.SplitN(rangeExpr, "-", 2)
+		var err error
+		if start, err = parseValue(parts[0], b); err != nil {
+			return 0, err
+		}
+		if end, err = parseValue(parts[1], b); err != nil {
+			return 0, err
+		}
+	default:
+		v, err := parseValue(rangeExpr, b)
+		if err != nil {
+			return 0, err
+		}
+		start, end = v, v
+		// "a/n" 表示从 a 开始到最大值
+		if step > 1 {
+			end = b.max
+		}
+	}
+
+	if start > end {
+		return 0, fmt.Errorf("范围起点大于终点: %q", expr)
+	}
+
+	var bits uint64
+	for v := start; v <= end; v += step {
+		bits |= 1 << v
+	}
+	return bits, nil
+}
+
+// parseValue 解析单个数值或名称
+func parseValue(value string, b bounds) (uint, error) {
+	if b.names != nil {
+		if v, ok := b.names[strings.ToLower(value)]; ok {
+			return v, nil
+		}
+	}
+
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("无效的数值: %q", value)
+	}
+	if uint(n) < b.min || uint(n) > b.max {
+		return 0, fmt.Errorf("数值 %d 超出范围 %d-%d", n, b.min, b.max)
+	}
+	return uint(n), nil
+}
+
+// has 判断位图中是否包含指定值
+func has(bits uint64, v uint) bool {
+	return bits&(1<<v) != 0
+}
@@ -0,0 +1,259 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("加载时区 %s 失败: %v", name, err)
+	}
+	return loc
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"字段过少", "* * * *"},
+		{"字段过多", "* * * * * *"},
+		{"分钟越界", "60 * * * *"},
+		{"日期为零", "0 0 0 * *"},
+		{"步长为零", "*/0 * * * *"},
+		{"步长不是数字", "*/a * * * *"},
+		{"范围倒置", "0 10-5 * * *"},
+		{"未知名称", "0 0 * foo *"},
+		{"未知描述符", "@every"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.expr); err == nil {
+				t.Errorf("Parse(%q) 应返回错误", tt.expr)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	utc := time.UTC
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  []time.Time // 依次调用 Next 得到的时间
+	}{
+		{
+			name:  "每分钟跳过当前秒",
+			expr:  "* * * * *",
+			after: time.Date(2026, 1, 1, 10, 0, 30, 0, utc),
+			want:  []time.Time{time.Date(2026, 1, 1, 10, 1, 0, 0, utc), time.Date(2026, 1, 1, 10, 2, 0, 0, utc)},
+		},
+		{
+			name:  "星号步长",
+			expr:  "*/20 * * * *",
+			after: time.Date(2026, 1, 1, 10, 40, 0, 0, utc),
+			want:  []time.Time{time.Date(2026, 1, 1, 11, 0, 0, 0, utc), time.Date(2026, 1, 1, 11, 20, 0, 0, utc)},
+		},
+		{
+			name:  "范围步长",
+			expr:  "0 9-17/4 * * *",
+			after: time.Date(2026, 1, 1, 9, 0, 0, 0, utc),
+			want: []time.Time{
+				time.Date(2026, 1, 1, 13, 0, 0, 0, utc),
+				time.Date(2026, 1, 1, 17, 0, 0, 0, utc),
+				time.Date(2026, 1, 2, 9, 0, 0, 0, utc),
+			},
+		},
+		{
+			name:  "起点步长到最大值",
+			expr:  "45/10 * * * *",
+			after: time.Date(2026, 1, 1, 10, 46, 0, 0, utc),
+			want:  []time.Time{time.Date(2026, 1, 1, 10, 55, 0, 0, utc), time.Date(2026, 1, 1, 11, 45, 0, 0, utc)},
+		},
+		{
+			name:  "列表与名称",
+			expr:  "0 8 * jan,jul mon-fri",
+			after: time.Date(2026, 1, 30, 8, 0, 0, 0, utc), // 周五
+			want:  []time.Time{time.Date(2026, 7, 1, 8, 0, 0, 0, utc)},
+		},
+		{
+			name:  "日和周同时限制时满足其一",
+			expr:  "0 0 13 * 5",
+			after: time.Date(2026, 2, 1, 0, 0, 0, 0, utc),
+			want: []time.Time{
+				time.Date(2026, 2, 6, 0, 0, 0, 0, utc),  // 周五
+				time.Date(2026, 2, 13, 0, 0, 0, 0, utc), // 13 日，同时也是周五
+				time.Date(2026, 2, 20, 0, 0, 0, 0, utc),
+			},
+		},
+		{
+			name:  "日期为星号时只看周",
+			expr:  "0 0 * * 0",
+			after: time.Date(2026, 2, 1, 0, 0, 0, 0, utc), // 周日
+			want:  []time.Time{time.Date(2026, 2, 8, 0, 0, 0, 0, utc)},
+		},
+		{
+			name:  "周为星号时只看日期",
+			expr:  "0 0 31 * *",
+			after: time.Date(2026, 1, 31, 0, 0, 0, 0, utc),
+			want:  []time.Time{time.Date(2026, 3, 31, 0, 0, 0, 0, utc)},
+		},
+		{
+			name:  "周日可以写作 7",
+			expr:  "0 12 * * 7",
+			after: time.Date(2026, 2, 2, 0, 0, 0, 0, utc),
+			want:  []time.Time{time.Date(2026, 2, 8, 12, 0, 0, 0, utc)},
+		},
+		{
+			name:  "闰年 2 月 29 日",
+			expr:  "0 0 29 2 *",
+			after: time.Date(2026, 1, 1, 0, 0, 0, 0, utc),
+			want:  []time.Time{time.Date(2028, 2, 29, 0, 0, 0, 0, utc)},
+		},
+		{
+			name:  "描述符",
+			expr:  "@weekly",
+			after: time.Date(2026, 2, 3, 0, 0, 0, 0, utc),
+			want:  []time.Time{time.Date(2026, 2, 8, 0, 0, 0, 0, utc)},
+		},
+		{
+			name:  "不存在的日期",
+			expr:  "0 0 30 2 *",
+			after: time.Date(2026, 1, 1, 0, 0, 0, 0, utc),
+			want:  []time.Time{{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) 失败: %v", tt.expr, err)
+			}
+			got := tt.after
+			for i, want := range tt.want {
+				got = s.Next(got)
+				if !got.Equal(want) {
+					t.Fatalf("第 %d 次 Next = %v，期望 %v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNextDST(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+	havana := mustLoad(t, "America/Havana")
+	est := time.FixedZone("EST", -5*3600)
+	edt := time.FixedZone("EDT", -4*3600)
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  []time.Time
+	}{
+		{
+			// 2026-03-08 02:00 EST 跳到 03:00 EDT
+			name:  "计划时间被跳过时在跳变后触发",
+			expr:  "30 2 * * *",
+			after: time.Date(2026, 3, 8, 0, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 3, 8, 3, 0, 0, 0, edt),
+				time.Date(2026, 3, 9, 2, 30, 0, 0, edt),
+			},
+		},
+		{
+			name:  "跳变前后的小时不受影响",
+			expr:  "30 1,3 * * *",
+			after: time.Date(2026, 3, 8, 0, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 3, 8, 1, 30, 0, 0, est),
+				time.Date(2026, 3, 8, 3, 30, 0, 0, edt),
+			},
+		},
+		{
+			name:  "每小时的计划按实际时间触发",
+			expr:  "30 * * * *",
+			after: time.Date(2026, 3, 8, 1, 30, 0, 0, ny),
+			want:  []time.Time{time.Date(2026, 3, 8, 3, 30, 0, 0, edt)},
+		},
+		{
+			// 2026-11-01 02:00 EDT 回到 01:00 EST，01:00-02:00 经过两次
+			name:  "重复的时段只触发一次",
+			expr:  "30 1 * * *",
+			after: time.Date(2026, 11, 1, 0, 0, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 1, 30, 0, 0, edt),
+				time.Date(2026, 11, 2, 1, 30, 0, 0, est),
+			},
+		},
+		{
+			name:  "每小时的计划在重复时段触发两次",
+			expr:  "0 * * * *",
+			after: time.Date(2026, 11, 1, 0, 30, 0, 0, ny),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 1, 0, 0, 0, edt),
+				time.Date(2026, 11, 1, 1, 0, 0, 0, est),
+				time.Date(2026, 11, 1, 2, 0, 0, 0, est),
+			},
+		},
+		{
+			// 古巴在 3 月第二个周日 00:00 跳到 01:00，当天没有午夜
+			name:  "午夜被跳过",
+			expr:  "0 * * * *",
+			after: time.Date(2026, 3, 7, 23, 0, 0, 0, havana),
+			want:  []time.Time{time.Date(2026, 3, 8, 1, 0, 0, 0, havana)},
+		},
+		{
+			name:  "午夜被跳过时的每日计划",
+			expr:  "@daily",
+			after: time.Date(2026, 3, 7, 12, 0, 0, 0, havana),
+			want: []time.Time{
+				time.Date(2026, 3, 8, 1, 0, 0, 0, havana),
+				time.Date(2026, 3, 9, 0, 0, 0, 0, havana),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) 失败: %v", tt.expr, err)
+			}
+			got := tt.after
+			for i, want := range tt.want {
+				got = s.Next(got)
+				if !got.Equal(want) {
+					t.Fatalf("第 %d 次 Next = %v，期望 %v", i+1, got, want)
+				}
+				if got.Location() != tt.after.Location() {
+					t.Fatalf("第 %d 次 Next 的时区为 %v，期望 %v", i+1, got.Location(), tt.after.Location())
+				}
+			}
+		})
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	after := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	next, err := NextRun("0 9 * * *", "Asia/Shanghai", true, after)
+	if err != nil {
+		t.Fatalf("NextRun 失败: %v", err)
+	}
+	want := time.Date(2026, 6, 2, 1, 0, 0, 0, time.UTC)
+	if next == nil || !next.Equal(want) || next.Location() != time.UTC {
+		t.Fatalf("NextRun = %v，期望 %v", next, want)
+	}
+
+	if next, err := NextRun("0 9 * * *", "", false, after); err != nil || next != nil {
+		t.Fatalf("未启用时 NextRun = %v, %v，期望 nil", next, err)
+	}
+	if _, err := NextRun("0 9 * * *", "Mars/Olympus", true, after); err == nil {
+		t.Fatal("无效时区应返回错误")
+	}
+}
@@ -0,0 +1,165 @@
+package cron
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// NextRun 计算作业的下一次执行时间（UTC），作业未启用时返回 nil
+func NextRun(expr, timezone string, enabled bool, after time.Time) (*time.Time, error) {
+	if !enabled {
+		return nil, nil
+	}
+	next, err := NextIn(expr, timezone, after)
+	if err != nil {
+		return nil, err
+	}
+	// 统一以 UTC 保存，保证抢占执行时的条件比较与存储格式一致
+	next = next.UTC()
+	return &next, nil
+}
+
+// Job 到期作业的调度信息
+type Job struct {
+	ID        string
+	Name      string
+	Expr      string
+	Timezone  string
+	Enabled   bool
+	Scheduled time.Time // 本次计划执行时间，即读取时的 next_run_at
+}
+
+// Jobs 调度循环读取和抢占作业所需的操作
+type Jobs[T any] struct {
+	// Due 返回 next_run_at 不晚于 now 的作业
+	Due func(now time.Time) ([]T, error)
+	// Describe 返回作业的调度信息
+	Describe func(item *T) Job
+	// Claim 仅当 next_run_at 仍为 scheduled 时更新为 next，返回是否抢占成功
+	Claim func(id string, scheduled time.Time, next *time.Time, now time.Time) (bool, error)
+	// Fire 抢占成功后调用，由调用方决定执行、跳过或记录
+	Fire func(item *T, scheduled, now time.Time)
+}
+
+// Runner 按 cron 表达式执行数据库中保存的作业
+// 定期读取到期作业，计算下一次执行时间并通过条件更新抢占本次执行，
+// 避免重启或多实例时重复执行；同一作业同时只执行一次
+type Runner[T any] struct {
+	name     string
+	interval time.Duration
+	jobs     Jobs[T]
+
+	mu      sync.Mutex
+	running map[string]bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewRunner 创建调度循环，name 用于日志，interval 为检查到期作业的间隔
+func NewRunner[T any](name string, interval time.Duration, jobs Jobs[T]) *Runner[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner[T]{
+		name:     name,
+		interval: interval,
+		jobs:     jobs,
+		running:  make(map[string]bool),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start 启动调度循环
+func (r *Runner[T]) Start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			r.runDue(time.Now())
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止调度并等待正在执行的作业结束
+func (r *Runner[T]) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// Acquire 标记作业开始执行，作业正在执行时返回 false
+// 成功后必须调用 Go 执行作业，执行结束时释放标记
+func (r *Runner[T]) Acquire(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running[id] {
+		return false
+	}
+	r.running[id] = true
+	return true
+}
+
+// Go 在后台执行已通过 Acquire 标记的作业，ctx 在超时或调度停止时取消
+func (r *Runner[T]) Go(id string, timeout time.Duration, fn func(ctx context.Context)) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer func() {
+			r.mu.Lock()
+			delete(r.running, id)
+			r.mu.Unlock()
+		}()
+
+		ctx, cancel := context.WithTimeout(r.ctx, timeout)
+		defer cancel()
+		fn(ctx)
+	}()
+}
+
+// IsRunning 判断作业是否正在执行
+func (r *Runner[T]) IsRunning(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.running[id]
+}
+
+// runDue 抢占并触发所有到期的作业
+func (r *Runner[T]) runDue(now time.Time) {
+	items, err := r.jobs.Due(now)
+	if err != nil {
+		log.Printf("获取到期的%s失败: %v", r.name, err)
+		return
+	}
+
+	for i := range items {
+		item := &items[i]
+		job := r.jobs.Describe(item)
+
+		next, err := NextRun(job.Expr, job.Timezone, job.Enabled, now)
+		if err != nil {
+			log.Printf("计算%s %s 的下次执行时间失败: %v", r.name, job.Name, err)
+			continue
+		}
+
+		claimed, err := r.jobs.Claim(job.ID, job.Scheduled, next, now)
+		if err != nil {
+			log.Printf("更新%s %s 状态失败: %v", r.name, job.Name, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		r.jobs.Fire(item, job.Scheduled, now)
+	}
+}
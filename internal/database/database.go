@@ -57,6 +57,17 @@ func autoMigrate() error {
 		&model.Certificate{},
 		&model.ComposeProject{},
 		&model.AuditLog{},
+		&model.UpdatePolicy{},
+		&model.UpdateRecord{},
+		&model.NotificationHook{},
+		&model.ScheduledTask{},
+		&model.TaskRun{},
+		&model.VolumeBackup{},
+		&model.HostCheck{},
+		&model.HostGroup{},
+		&model.HostGroupMember{},
+		&model.LogForwarder{},
+		&model.TerminalRecording{},
 	)
 }
 
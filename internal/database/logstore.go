@@ -0,0 +1,55 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"rubick/internal/model"
+)
+
+var logDB *gorm.DB
+
+// InitializeLogStore 初始化集中日志存储的数据库
+// 日志写入量大，使用独立的数据库文件，避免与业务数据争用写锁，并且可以单独统计和回收空间
+func InitializeLogStore(path string) (*gorm.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建日志存储目录失败: %w", err)
+	}
+
+	// auto_vacuum 必须在建表之前设置，之后删除日志释放的页可以通过 incremental_vacuum 归还给文件系统
+	dsn := path + "?_auto_vacuum=incremental&_journal_mode=WAL"
+
+	var err error
+	logDB, err = gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		// 日志批量写入频繁，只记录警告和错误
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("连接日志存储数据库失败: %w", err)
+	}
+
+	if err = logDB.AutoMigrate(&model.LogEntry{}); err != nil {
+		return nil, fmt.Errorf("日志存储数据库迁移失败: %w", err)
+	}
+
+	return logDB, nil
+}
+
+// GetLogDB 获取日志存储的数据库连接
+func GetLogDB() *gorm.DB {
+	return logDB
+}
+
+// CloseLogStore 关闭日志存储的数据库连接
+func CloseLogStore() error {
+	sqlDB, err := logDB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -2,19 +2,35 @@ package docker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"rubick/internal/model"
+
+	"github.com/docker/docker/client"
 )
 
+// ErrHostDown 健康检查判定主机不可用，请求直接失败而不再等待连接超时
+var ErrHostDown = errors.New("主机当前不可用")
+
 // ClientManager Docker 客户端管理器
+// 建立连接可能耗时较长（如 SSH 主机不可达），在锁外进行，同一主机同时只建立一个连接
 type ClientManager struct {
 	connections map[string]Connection
+	dialing     map[string]*dialCall // 正在建立连接的主机
+	down        map[string]error     // 健康检查失败的主机及其最近一次错误
 	mu          sync.RWMutex
 }
 
+// dialCall 正在进行的连接建立，其他调用等待 done 后共享结果
+type dialCall struct {
+	done chan struct{}
+	conn Connection
+	err  error
+}
+
 var manager *ClientManager
 var once sync.Once
 
@@ -23,13 +39,44 @@ func GetManager() *ClientManager {
 	once.Do(func() {
 		manager = &ClientManager{
 			connections: make(map[string]Connection),
+			dialing:     make(map[string]*dialCall),
+			down:        make(map[string]error),
 		}
 	})
 	return manager
 }
 
 // GetClient 获取 Docker 客户端
+// 主机已被健康检查判定为不可用时立即返回 ErrHostDown
 func (m *ClientManager) GetClient(ctx context.Context, host *model.Host) (Connection, error) {
+	m.mu.RLock()
+	lastErr, down := m.down[host.ID]
+	m.mu.RUnlock()
+	if down {
+		return nil, fmt.Errorf("%w: %v", ErrHostDown, lastErr)
+	}
+	return m.connect(ctx, host)
+}
+
+// Ping 检查主机是否可用，不受不可用标记影响，成功时复用或缓存连接
+func (m *ClientManager) Ping(ctx context.Context, host *model.Host) error {
+	_, err := m.connect(ctx, host)
+	return err
+}
+
+// SetHostStatus 记录健康检查结果，err 为 nil 表示主机可用
+func (m *ClientManager) SetHostStatus(hostID string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err == nil {
+		delete(m.down, hostID)
+		return
+	}
+	m.down[hostID] = err
+}
+
+// connect 获取或创建主机连接
+func (m *ClientManager) connect(ctx context.Context, host *model.Host) (Connection, error) {
 	m.mu.RLock()
 	conn, exists := m.connections[host.ID]
 	m.mu.RUnlock()
@@ -39,43 +86,82 @@ func (m *ClientManager) GetClient(ctx context.Context, host *model.Host) (Connec
 		if err := conn.Test(ctx); err == nil {
 			return conn, nil
 		}
-		// 连接失效，关闭并删除
+		// 连接失效，关闭并删除（期间可能已被其他调用替换）
 		m.mu.Lock()
-		conn.Close()
-		delete(m.connections, host.ID)
+		if m.connections[host.ID] == conn {
+			conn.Close()
+			delete(m.connections, host.ID)
+		}
 		m.mu.Unlock()
 	}
 
-	// 创建新连接
+	return m.dial(ctx, host)
+}
+
+// dial 建立并测试新连接，不持有 m.mu
+// 同一主机已有连接正在建立时等待其结果，避免并发重复连接
+func (m *ClientManager) dial(ctx context.Context, host *model.Host) (Connection, error) {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	if conn, exists := m.connections[host.ID]; exists {
+		m.mu.Unlock()
+		return conn, nil
+	}
+	call, dialing := m.dialing[host.ID]
+	if !dialing {
+		call = &dialCall{done: make(chan struct{})}
+		m.dialing[host.ID] = call
+	}
+	m.mu.Unlock()
 
-	// 双重检查
-	if conn, exists = m.connections[host.ID]; exists {
-		if err := conn.Test(ctx); err == nil {
-			return conn, nil
+	if !dialing {
+		call.conn, call.err = m.newConnection(ctx, host)
+
+		m.mu.Lock()
+		// 建立期间主机被移除（如配置已更新）时不缓存旧配置的连接
+		if m.dialing[host.ID] == call {
+			delete(m.dialing, host.ID)
+			if call.err == nil {
+				m.connections[host.ID] = call.conn
+			}
 		}
-		conn.Close()
-		delete(m.connections, host.ID)
+		m.mu.Unlock()
+		close(call.done)
+		return call.conn, call.err
 	}
 
+	select {
+	case <-call.done:
+		return call.conn, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// newConnection 创建并测试连接
+func (m *ClientManager) newConnection(ctx context.Context, host *model.Host) (Connection, error) {
 	conn, err := m.createConnection(host)
 	if err != nil {
 		return nil, err
 	}
 
-	// 测试新连接
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 	if err := conn.Test(ctx); err != nil {
 		conn.Close()
 		return nil, fmt.Errorf("连接测试失败: %w", err)
 	}
-
-	m.connections[host.ID] = conn
 	return conn, nil
 }
 
+// GetDockerClient 获取主机对应的 Docker SDK 客户端
+func (m *ClientManager) GetDockerClient(ctx context.Context, host *model.Host) (*client.Client, error) {
+	conn, err := m.GetClient(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	return conn.Connect(ctx)
+}
+
 // createConnection 根据主机配置创建连接
 func (m *ClientManager) createConnection(host *model.Host) (Connection, error) {
 	switch host.Type {
@@ -105,11 +191,14 @@ func (m *ClientManager) createConnection(host *model.Host) (Connection, error) {
 	}
 }
 
-// RemoveClient 移除客户端连接
+// RemoveClient 移除客户端连接，同时清除不可用标记以便使用新配置重试
 func (m *ClientManager) RemoveClient(hostID string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	delete(m.down, hostID)
+	delete(m.dialing, hostID)
+
 	conn, exists := m.connections[hostID]
 	if !exists {
 		return nil
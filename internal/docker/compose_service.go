@@ -9,6 +9,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"rubick/internal/model"
 )
 
 // ComposeOptions Compose 命令通用选项
@@ -25,6 +27,22 @@ type ComposeOptions struct {
 	UseWorkDir bool
 }
 
+// ComposeOptionsForProject 根据 Compose 项目构建 ComposeOptions
+func ComposeOptionsForProject(project *model.ComposeProject) ComposeOptions {
+	opts := ComposeOptions{
+		ProjectName: project.Name,
+	}
+
+	if project.SourceType == "directory" {
+		opts.UseWorkDir = true
+		opts.WorkDir = project.WorkDir
+		opts.ComposeFile = project.ComposeFile
+		opts.EnvFile = project.EnvFile
+	}
+
+	return opts
+}
+
 // UpOptions docker compose up 选项
 type UpOptions struct {
 	ComposeOptions
@@ -36,6 +54,8 @@ type UpOptions struct {
 	RemoveOrphans bool
 	// Timeout 超时时间（秒）
 	Timeout int
+	// NoDeps 不启动关联的服务
+	NoDeps bool
 	// Services 指定服务
 	Services []string
 }
@@ -86,6 +106,20 @@ type PortPublisher struct {
 	Protocol      string `json:"protocol"`
 }
 
+// ProjectConfig docker compose config 解析结果
+type ProjectConfig struct {
+	Name     string                   `json:"name"`
+	Services map[string]ServiceConfig `json:"services"`
+}
+
+// ServiceConfig Compose 服务配置
+type ServiceConfig struct {
+	Image         string            `json:"image"`
+	Build         json.RawMessage   `json:"build,omitempty"`
+	ContainerName string            `json:"container_name,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+}
+
 // ComposeService Compose 服务
 type ComposeService struct {
 	executor CommandExecutor
@@ -130,6 +164,12 @@ func (s *ComposeService) Up(ctx context.Context, composeYAML string, opts UpOpti
 	if opts.Timeout > 0 {
 		args = append(args, "--timeout", fmt.Sprintf("%d", opts.Timeout))
 	}
+	if opts.NoDeps {
+		args = append(args, "--no-deps")
+	}
+	if opts.Detach {
+		args = append(args, "--detach")
+	}
 
 	// 如果不是后台模式，添加 --abort-on-container-exit 以便在容器退出时结束
 	if !opts.Detach {
@@ -395,6 +435,42 @@ func (s *ComposeService) Ps(ctx context.Context, composeYAML string, opts Compos
 	return parsePsOutput(string(output))
 }
 
+// Config 解析 Compose 项目的最终配置
+func (s *ComposeService) Config(ctx context.Context, composeYAML string, opts ComposeOptions) (*ProjectConfig, error) {
+	var filePath string
+	var needsCleanup bool
+
+	if opts.UseWorkDir {
+		filePath = ""
+		needsCleanup = false
+	} else {
+		var err error
+		filePath, err = s.executor.WriteFile(ctx, composeYAML, "docker-compose.yml")
+		if err != nil {
+			return nil, fmt.Errorf("写入 compose 文件失败: %w", err)
+		}
+		needsCleanup = true
+	}
+
+	if needsCleanup {
+		defer s.cleanupFile(ctx, filePath)
+	}
+
+	args := s.buildBaseArgs(filePath, opts)
+	args = append(args, "config", "--format", "json")
+
+	output, err := s.executor.Execute(ctx, "docker", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var config ProjectConfig
+	if err := json.Unmarshal(output, &config); err != nil {
+		return nil, fmt.Errorf("解析 compose 配置失败: %w", err)
+	}
+	return &config, nil
+}
+
 // buildBaseArgs 构建基础命令参数
 func (s *ComposeService) buildBaseArgs(filePath string, opts ComposeOptions) []string {
 	var args []string
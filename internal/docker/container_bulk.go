@@ -0,0 +1,109 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// 批量操作类型
+const (
+	BulkStart   = "start"
+	BulkStop    = "stop"
+	BulkRestart = "restart"
+	BulkRemove  = "remove"
+	BulkPause   = "pause"
+	BulkUnpause = "unpause"
+)
+
+// defaultBulkConcurrency 批量操作默认并发数
+const defaultBulkConcurrency = 8
+
+// maxBulkConcurrency 批量操作最大并发数
+const maxBulkConcurrency = 32
+
+// BulkOptions 批量操作选项
+type BulkOptions struct {
+	Timeout       *int `json:"timeout"`        // stop/restart 超时（秒）
+	Force         bool `json:"force"`          // remove 时强制删除运行中的容器
+	RemoveVolumes bool `json:"remove_volumes"` // remove 时删除匿名卷
+	Concurrency   int  `json:"concurrency"`    // 并发数，0 使用默认值
+}
+
+// BulkResult 单个容器的操作结果
+type BulkResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ValidBulkAction 判断是否为支持的批量操作
+func ValidBulkAction(action string) bool {
+	switch action {
+	case BulkStart, BulkStop, BulkRestart, BulkRemove, BulkPause, BulkUnpause:
+		return true
+	}
+	return false
+}
+
+// Bulk 以有限并发对多个容器执行同一操作，结果顺序与 ids 一致
+// 单个容器失败不影响其他容器
+func (s *ContainerService) Bulk(ctx context.Context, action string, ids []string, opts BulkOptions) ([]BulkResult, error) {
+	if !ValidBulkAction(action) {
+		return nil, fmt.Errorf("不支持的批量操作: %s", action)
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+	if concurrency > maxBulkConcurrency {
+		concurrency = maxBulkConcurrency
+	}
+
+	results := make([]BulkResult, len(ids))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = BulkResult{ID: id, Success: true}
+			if err := s.runBulkAction(ctx, action, id, opts); err != nil {
+				results[i].Success = false
+				results[i].Error = err.Error()
+			}
+		}(i, id)
+	}
+
+	wg.Wait()
+	return results, nil
+}
+
+// runBulkAction 对单个容器执行操作
+func (s *ContainerService) runBulkAction(ctx context.Context, action, id string, opts BulkOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	switch action {
+	case BulkStart:
+		return s.Start(ctx, id)
+	case BulkStop:
+		return s.Stop(ctx, id, opts.Timeout)
+	case BulkRestart:
+		return s.Restart(ctx, id, opts.Timeout)
+	case BulkRemove:
+		return s.Remove(ctx, id, opts.Force, opts.RemoveVolumes)
+	case BulkPause:
+		return s.Pause(ctx, id)
+	case BulkUnpause:
+		return s.Unpause(ctx, id)
+	}
+	return fmt.Errorf("不支持的批量操作: %s", action)
+}
@@ -0,0 +1,145 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// ExecOptions 非交互式执行命令选项
+type ExecOptions struct {
+	Cmd        []string `json:"cmd"`
+	User       string   `json:"user"`
+	Env        []string `json:"env"`
+	WorkingDir string   `json:"working_dir"`
+	// Stdin 写入命令标准输入的内容，写完后关闭标准输入；为空时不连接标准输入
+	Stdin io.Reader `json:"-"`
+	// MaxOutput stdout、stderr 各自保留的最大字节数，0 表示不限制
+	MaxOutput int `json:"-"`
+}
+
+// ExecResult 执行结果
+type ExecResult struct {
+	ExitCode   *int   `json:"exit_code"` // 超时或进程仍在运行时为空
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	TimedOut   bool   `json:"timed_out,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// ExecRun 在容器中以非 TTY 方式执行命令并等待结束，分别返回 stdout 和 stderr
+// ctx 取消或超时时返回已读取的输出和 ctx 的错误，已启动的进程不会被终止
+func (s *ContainerService) ExecRun(ctx context.Context, containerID string, opts ExecOptions) (*ExecResult, error) {
+	if len(opts.Cmd) == 0 {
+		return nil, fmt.Errorf("命令不能为空")
+	}
+
+	start := time.Now()
+	created, err := s.client.ContainerExecCreate(ctx, containerID, containerTypes.ExecOptions{
+		AttachStdin:  opts.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          opts.Cmd,
+		User:         opts.User,
+		Env:          opts.Env,
+		WorkingDir:   opts.WorkingDir,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建执行命令失败: %w", err)
+	}
+
+	resp, err := s.client.ContainerExecAttach(ctx, created.ID, containerTypes.ExecAttachOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("连接执行命令失败: %w", err)
+	}
+	defer resp.Close()
+
+	// 写完标准输入后半关闭连接，命令读到 EOF
+	// 命令不读取标准输入时写入可能阻塞，连接关闭时结束
+	if opts.Stdin != nil {
+		go func() {
+			io.Copy(resp.Conn, opts.Stdin)
+			resp.CloseWrite()
+		}()
+	}
+
+	stdout := &limitedBuffer{limit: opts.MaxOutput}
+	stderr := &limitedBuffer{limit: opts.MaxOutput}
+	result := func() *ExecResult {
+		return &ExecResult{
+			Stdout:     stdout.String(),
+			Stderr:     stderr.String(),
+			Truncated:  stdout.truncated || stderr.truncated,
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := stdcopy.StdCopy(stdout, stderr, resp.Reader)
+		done <- err
+	}()
+
+	select {
+	case <-ctx.Done():
+		// 关闭连接后等待读取结束，再返回已读取的输出
+		resp.Close()
+		<-done
+		r := result()
+		r.TimedOut = errors.Is(ctx.Err(), context.DeadlineExceeded)
+		return r, ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return nil, fmt.Errorf("读取命令输出失败: %w", err)
+		}
+	}
+
+	// 输出流关闭后进程状态可能尚未更新，短暂等待退出码
+	// 进程关闭了输出但仍在运行（如转入后台）时不返回退出码
+	r := result()
+	for i := 0; i < 20; i++ {
+		inspect, err := s.client.ContainerExecInspect(ctx, created.ID)
+		if err != nil {
+			// 输出已完整读取，超时时仍返回输出，只是没有退出码
+			if ctx.Err() != nil {
+				r.TimedOut = errors.Is(ctx.Err(), context.DeadlineExceeded)
+				return r, ctx.Err()
+			}
+			return nil, fmt.Errorf("获取命令执行状态失败: %w", err)
+		}
+		if !inspect.Running {
+			r.ExitCode = &inspect.ExitCode
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return r, nil
+}
+
+// limitedBuffer 超出上限后丢弃后续内容的缓冲区
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 {
+		remaining := b.limit - b.Len()
+		if remaining < len(p) {
+			b.truncated = true
+			if remaining > 0 {
+				b.Buffer.Write(p[:remaining])
+			}
+			return len(p), nil
+		}
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,439 @@
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	containerTypes "github.com/docker/docker/api/types/container"
+)
+
+const (
+	// maxListEntries 浏览目录时最多读取的条目数
+	maxListEntries = 200000
+	// maxListBytes 浏览目录时最多读取的归档大小
+	// Docker 只能以 tar 形式导出目录，子目录中的文件内容也会被传输，超出时返回已读取的部分
+	maxListBytes = 64 << 20
+	// maxListOutput 通过 exec 浏览目录时最多读取的输出大小
+	maxListOutput = 16 << 20
+)
+
+// listScript 输出目录每个直接子项的原始模式、大小、修改时间和名称，再输出符号链接的目标
+// 只依赖 sh、stat 和 readlink，GNU coreutils 和 busybox 均可用；不支持包含换行的文件名
+const listScript = `command -v stat >/dev/null && command -v readlink >/dev/null || exit 127
+cd -- "$1" || exit 1
+stat -c '%f/%s/%Y/%n' -- * .[!.]* ..?* 2>/dev/null
+for f in * .[!.]* ..?*; do
+	[ -L "$f" ] && printf 'L/%s\n%s\n' "$f" "$(readlink -- "$f")"
+done
+exit 0`
+
+// PathStat 容器内路径的状态
+type PathStat struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Size       int64  `json:"size"`
+	Mode       string `json:"mode"`
+	IsDir      bool   `json:"is_dir"`
+	ModTime    int64  `json:"mod_time"`
+	LinkTarget string `json:"link_target,omitempty"`
+}
+
+// ContainerChange 容器文件系统相对镜像的变更
+type ContainerChange struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"` // modified, added, deleted
+}
+
+// UploadFile 上传到容器的文件
+type UploadFile struct {
+	Name   string // 相对于目标目录的路径
+	Size   int64
+	Mode   int64
+	Reader io.Reader
+}
+
+// StatPath 获取容器内路径的状态，容器停止时同样可用
+func (s *ContainerService) StatPath(ctx context.Context, containerID, filePath string) (*PathStat, error) {
+	stat, err := s.client.ContainerStatPath(ctx, containerID, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("获取路径信息失败: %w", err)
+	}
+	return &PathStat{
+		Name:       stat.Name,
+		Path:       filePath,
+		Size:       stat.Size,
+		Mode:       stat.Mode.String(),
+		IsDir:      stat.Mode.IsDir(),
+		ModTime:    stat.Mtime.Unix(),
+		LinkTarget: stat.LinkTarget,
+	}, nil
+}
+
+// ListFiles 列出容器内目录的直接子项，容器停止时同样可用
+// 目录过大时只返回已读取到的部分，truncated 为 true
+// useExec 为 true 且容器正在运行时，以 root 在容器内执行 sh 和 stat 读取目录本身，避免 tar 导出整个子树；
+// 该命令会出现在 docker top 和 exec 审计中，因此需要调用方显式开启
+func (s *ContainerService) ListFiles(ctx context.Context, containerID, dirPath string, useExec bool) ([]FileInfo, bool, error) {
+	dirPath = path.Clean("/" + dirPath)
+
+	stat, err := s.client.ContainerStatPath(ctx, containerID, dirPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("获取路径信息失败: %w", err)
+	}
+	// 符号链接指向目录时浏览链接目标
+	if stat.LinkTarget != "" {
+		dirPath = path.Clean("/" + stat.LinkTarget)
+		if stat, err = s.client.ContainerStatPath(ctx, containerID, dirPath); err != nil {
+			return nil, false, fmt.Errorf("获取路径信息失败: %w", err)
+		}
+	}
+	if !stat.Mode.IsDir() {
+		return nil, false, fmt.Errorf("%s 不是目录", dirPath)
+	}
+
+	// 容器未运行或镜像中没有 sh、stat（如 distroless）时使用 tar
+	if useExec {
+		if info, err := s.client.ContainerInspect(ctx, containerID); err == nil && info.State != nil && info.State.Running && !info.State.Paused {
+			if files, truncated, ok := s.listFilesExec(ctx, containerID, dirPath); ok {
+				return files, truncated, nil
+			}
+		}
+	}
+	return s.listFilesArchive(ctx, containerID, dirPath)
+}
+
+// listFilesExec 在容器内执行 stat 列出目录，命令不可用或执行失败时 ok 为 false
+func (s *ContainerService) listFilesExec(ctx context.Context, containerID, dirPath string) (files []FileInfo, truncated bool, ok bool) {
+	result, err := s.ExecRun(ctx, containerID, ExecOptions{
+		Cmd:       []string{"sh", "-c", listScript, "sh", dirPath},
+		User:      "0", // 与 tar 导出一致，不受容器用户的权限限制
+		MaxOutput: maxListOutput,
+	})
+	if err != nil || result.ExitCode == nil || *result.ExitCode != 0 {
+		return nil, false, false
+	}
+
+	output := result.Stdout
+	if result.Truncated {
+		// 丢弃被截断的最后一行
+		output = output[:strings.LastIndex(output, "\n")+1]
+	}
+	files, links := parseListOutput(dirPath, output)
+	for i := range files {
+		files[i].LinkTarget = links[files[i].Name]
+	}
+	if len(files) > maxListEntries {
+		files = files[:maxListEntries]
+		result.Truncated = true
+	}
+
+	sortFiles(files)
+	return files, result.Truncated, true
+}
+
+// parseListOutput 解析 listScript 的输出，返回目录子项和符号链接名称到目标的映射
+func parseListOutput(dirPath, output string) ([]FileInfo, map[string]string) {
+	files := []FileInfo{}
+	links := make(map[string]string)
+
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if name, ok := strings.CutPrefix(line, "L/"); ok {
+			if i+1 < len(lines) {
+				links[name] = lines[i+1]
+				i++
+			}
+			continue
+		}
+
+		fields := strings.SplitN(line, "/", 4)
+		if len(fields) != 4 || fields[3] == "" {
+			continue
+		}
+		raw, err := strconv.ParseUint(fields[0], 16, 32)
+		if err != nil {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[1], 10, 64)
+		mtime, _ := strconv.ParseInt(fields[2], 10, 64)
+
+		mode := unixFileMode(uint32(raw))
+		files = append(files, FileInfo{
+			Name:    fields[3],
+			Path:    path.Join(dirPath, fields[3]),
+			IsDir:   mode.IsDir(),
+			Size:    size,
+			Mode:    mode.String(),
+			ModTime: mtime,
+		})
+	}
+	return files, links
+}
+
+// unixFileMode 将 stat 返回的原始模式转换为 os.FileMode
+func unixFileMode(m uint32) os.FileMode {
+	mode := os.FileMode(m & 0o777)
+	switch m & 0o170000 {
+	case 0o040000:
+		mode |= os.ModeDir
+	case 0o120000:
+		mode |= os.ModeSymlink
+	case 0o010000:
+		mode |= os.ModeNamedPipe
+	case 0o140000:
+		mode |= os.ModeSocket
+	case 0o020000:
+		mode |= os.ModeDevice | os.ModeCharDevice
+	case 0o060000:
+		mode |= os.ModeDevice
+	}
+	if m&0o4000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if m&0o2000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if m&0o1000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode
+}
+
+// listFilesArchive 通过 tar 导出目录并读取直接子项，容器停止时同样可用
+func (s *ContainerService) listFilesArchive(ctx context.Context, containerID, dirPath string) ([]FileInfo, bool, error) {
+	reader, _, err := s.client.CopyFromContainer(ctx, containerID, dirPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("读取容器目录失败: %w", err)
+	}
+	defer reader.Close()
+
+	// 归档中的条目以目录名为前缀，根目录除外
+	prefix := ""
+	if dirPath != "/" {
+		prefix = path.Base(dirPath) + "/"
+	}
+
+	counter := &countingReader{r: reader}
+	tr := tar.NewReader(counter)
+	files := []FileInfo{}
+	truncated := false
+	for entries := 0; ; entries++ {
+		if entries >= maxListEntries || counter.n >= maxListBytes {
+			truncated = true
+			break
+		}
+
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("解析容器目录失败: %w", err)
+		}
+
+		name := strings.Trim(strings.TrimPrefix(hdr.Name, "./"), "/")
+		if prefix != "" {
+			if !strings.HasPrefix(name+"/", prefix) || name+"/" == prefix {
+				continue
+			}
+			name = strings.TrimPrefix(name, prefix)
+		}
+		if name == "" || name == "." || strings.Contains(name, "/") {
+			continue
+		}
+
+		info := hdr.FileInfo()
+		files = append(files, FileInfo{
+			Name:       name,
+			Path:       path.Join(dirPath, name),
+			IsDir:      info.IsDir(),
+			Size:       hdr.Size,
+			Mode:       info.Mode().String(),
+			ModTime:    hdr.ModTime.Unix(),
+			LinkTarget: hdr.Linkname,
+		})
+	}
+
+	sortFiles(files)
+	return files, truncated, nil
+}
+
+// sortFiles 目录在前，同类按名称排序
+func sortFiles(files []FileInfo) {
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].IsDir != files[j].IsDir {
+			return files[i].IsDir
+		}
+		return files[i].Name < files[j].Name
+	})
+}
+
+// CopyFrom 以 tar 归档形式读取容器内的文件或目录，容器停止时同样可用
+func (s *ContainerService) CopyFrom(ctx context.Context, containerID, srcPath string) (io.ReadCloser, *PathStat, error) {
+	reader, stat, err := s.client.CopyFromContainer(ctx, containerID, srcPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取容器文件失败: %w", err)
+	}
+	return reader, &PathStat{
+		Name:       stat.Name,
+		Path:       srcPath,
+		Size:       stat.Size,
+		Mode:       stat.Mode.String(),
+		IsDir:      stat.Mode.IsDir(),
+		ModTime:    stat.Mtime.Unix(),
+		LinkTarget: stat.LinkTarget,
+	}, nil
+}
+
+// ReadFile 读取容器内单个普通文件的内容
+func (s *ContainerService) ReadFile(ctx context.Context, containerID, filePath string) (io.ReadCloser, *PathStat, error) {
+	reader, stat, err := s.CopyFrom(ctx, containerID, filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tr := tar.NewReader(reader)
+	hdr, err := tr.Next()
+	if err != nil {
+		reader.Close()
+		return nil, nil, fmt.Errorf("解析容器文件失败: %w", err)
+	}
+	if hdr.Typeflag != tar.TypeReg {
+		reader.Close()
+		return nil, nil, fmt.Errorf("%s 不是普通文件", filePath)
+	}
+
+	return &readCloser{Reader: tr, Closer: reader}, stat, nil
+}
+
+// CopyTo 将 tar 归档解压到容器内的目录，容器停止时同样可用
+func (s *ContainerService) CopyTo(ctx context.Context, containerID, dstDir string, archive io.Reader) error {
+	err := s.client.CopyToContainer(ctx, containerID, dstDir, archive, containerTypes.CopyToContainerOptions{})
+	if err != nil {
+		return fmt.Errorf("写入容器文件失败: %w", err)
+	}
+	return nil
+}
+
+// UploadFiles 将文件打包为 tar 流式写入容器内的目录
+func (s *ContainerService) UploadFiles(ctx context.Context, containerID, dstDir string, files []UploadFile) error {
+	for _, f := range files {
+		if _, err := cleanUploadName(f.Name); err != nil {
+			return err
+		}
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeUploadArchive(pw, files))
+	}()
+	defer pr.Close()
+
+	return s.CopyTo(ctx, containerID, dstDir, pr)
+}
+
+// Diff 获取容器文件系统相对镜像的变更
+func (s *ContainerService) Diff(ctx context.Context, containerID string) ([]ContainerChange, error) {
+	changes, err := s.client.ContainerDiff(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("获取容器变更失败: %w", err)
+	}
+
+	result := make([]ContainerChange, 0, len(changes))
+	for _, change := range changes {
+		kind := "modified"
+		switch change.Kind {
+		case containerTypes.ChangeAdd:
+			kind = "added"
+		case containerTypes.ChangeDelete:
+			kind = "deleted"
+		}
+		result = append(result, ContainerChange{Path: change.Path, Kind: kind})
+	}
+	return result, nil
+}
+
+// writeUploadArchive 将上传文件写为 tar 归档，自动补充中间目录
+func writeUploadArchive(w io.Writer, files []UploadFile) error {
+	tw := tar.NewWriter(w)
+	now := time.Now()
+	dirs := make(map[string]bool)
+
+	for _, f := range files {
+		name, err := cleanUploadName(f.Name)
+		if err != nil {
+			return err
+		}
+
+		// 先写父目录，再写子目录
+		var missing []string
+		for dir := path.Dir(name); dir != "." && !dirs[dir]; dir = path.Dir(dir) {
+			dirs[dir] = true
+			missing = append(missing, dir)
+		}
+		for i := len(missing) - 1; i >= 0; i-- {
+			if err := tw.WriteHeader(&tar.Header{
+				Typeflag: tar.TypeDir,
+				Name:     missing[i] + "/",
+				Mode:     0o755,
+				ModTime:  now,
+			}); err != nil {
+				return err
+			}
+		}
+
+		mode := f.Mode
+		if mode == 0 {
+			mode = 0o644
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Size:     f.Size,
+			Mode:     mode,
+			ModTime:  now,
+		}); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(tw, f.Reader, f.Size); err != nil {
+			return fmt.Errorf("读取上传文件 %s 失败: %w", f.Name, err)
+		}
+	}
+	return tw.Close()
+}
+
+// cleanUploadName 规范化上传文件名，结果总是位于目标目录之内
+func cleanUploadName(name string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+	if cleaned == "" {
+		return "", fmt.Errorf("无效的文件名: %q", name)
+	}
+	return cleaned, nil
+}
+
+// countingReader 统计已读取字节数
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// readCloser 组合 Reader 与 Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
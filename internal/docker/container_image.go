@@ -0,0 +1,136 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/distribution/reference"
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// commitChangeRegexp 允许在 commit 时修改的 Dockerfile 指令
+var commitChangeRegexp = regexp.MustCompile(`(?i)^\s*(CMD|ENTRYPOINT|ENV|EXPOSE|LABEL|ONBUILD|USER|VOLUME|WORKDIR|STOPSIGNAL|HEALTHCHECK|SHELL)\s+\S`)
+
+// CommitOptions 将容器提交为镜像的选项
+type CommitOptions struct {
+	Reference string   `json:"reference"` // 新镜像名称，如 myapp:debug，为空时生成无标签镜像
+	Author    string   `json:"author"`
+	Message   string   `json:"message"`
+	Changes   []string `json:"changes"` // Dockerfile 指令，如 ENV DEBUG=1
+	Pause     *bool    `json:"pause"`   // 提交时是否暂停容器，默认 true
+}
+
+// LoadResult 加载镜像的结果
+type LoadResult struct {
+	Images []string `json:"images"`
+	Output string   `json:"output"`
+}
+
+// Validate 校验提交选项
+func (o *CommitOptions) Validate() error {
+	if o.Reference != "" {
+		if _, err := reference.ParseNormalizedNamed(o.Reference); err != nil {
+			return fmt.Errorf("reference: 无效的镜像名称: %w", err)
+		}
+	}
+	for _, change := range o.Changes {
+		if !commitChangeRegexp.MatchString(change) {
+			return fmt.Errorf("changes: 不支持的指令: %q", change)
+		}
+	}
+	return nil
+}
+
+// Commit 将容器的当前状态提交为新镜像，返回镜像 ID
+func (s *ContainerService) Commit(ctx context.Context, containerID string, opts CommitOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+
+	pause := true
+	if opts.Pause != nil {
+		pause = *opts.Pause
+	}
+
+	resp, err := s.client.ContainerCommit(ctx, containerID, containerTypes.CommitOptions{
+		Reference: opts.Reference,
+		Comment:   opts.Message,
+		Author:    opts.Author,
+		Changes:   opts.Changes,
+		Pause:     pause,
+	})
+	if err != nil {
+		return "", fmt.Errorf("提交容器失败: %w", err)
+	}
+	return resp.ID, nil
+}
+
+// Export 将容器文件系统导出为 tar 流
+func (s *ContainerService) Export(ctx context.Context, containerID string) (io.ReadCloser, error) {
+	reader, err := s.client.ContainerExport(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("导出容器失败: %w", err)
+	}
+	return reader, nil
+}
+
+// Save 将一个或多个镜像保存为 tar 流
+func (s *ImageService) Save(ctx context.Context, images []string) (io.ReadCloser, error) {
+	if len(images) == 0 {
+		return nil, fmt.Errorf("至少需要指定一个镜像")
+	}
+	reader, err := s.client.ImageSave(ctx, images)
+	if err != nil {
+		return nil, fmt.Errorf("保存镜像失败: %w", err)
+	}
+	return reader, nil
+}
+
+// Load 从 tar 流加载镜像，返回加载的镜像名称
+func (s *ImageService) Load(ctx context.Context, input io.Reader) (*LoadResult, error) {
+	resp, err := s.client.ImageLoad(ctx, input, client.ImageLoadWithQuiet(true))
+	if err != nil {
+		return nil, fmt.Errorf("加载镜像失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result := &LoadResult{Images: []string{}}
+	var output strings.Builder
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var msg struct {
+			Stream string `json:"stream"`
+			Error  string `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			output.Write(scanner.Bytes())
+			output.WriteByte('\n')
+			continue
+		}
+		if msg.Error != "" {
+			return nil, fmt.Errorf("加载镜像失败: %s", msg.Error)
+		}
+		output.WriteString(msg.Stream)
+
+		line := strings.TrimSpace(msg.Stream)
+		for _, prefix := range []string{"Loaded image: ", "Loaded image ID: "} {
+			if name, ok := strings.CutPrefix(line, prefix); ok {
+				result.Images = append(result.Images, name)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取加载结果失败: %w", err)
+	}
+
+	result.Output = output.String()
+	return result, nil
+}
@@ -0,0 +1,246 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// 日志输出流
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// maxLogLineBytes 单行日志的最大长度，超出部分截断，避免无换行的输出占满内存
+const maxLogLineBytes = 256 * 1024
+
+// errStopLogs 回调要求停止读取
+var errStopLogs = errors.New("stop reading logs")
+
+// LogOptions 容器日志查询选项
+type LogOptions struct {
+	Since      string `json:"since"`      // 开始时间，RFC3339、Unix 时间戳或相对时长（如 10m）
+	Until      string `json:"until"`      // 结束时间，格式同 Since
+	Tail       string `json:"tail"`       // 只读取最后 N 行，all 表示全部
+	Timestamps bool   `json:"timestamps"` // 是否返回每行的时间戳
+	Stdout     bool   `json:"stdout"`
+	Stderr     bool   `json:"stderr"`
+	Follow     bool   `json:"follow"`
+	Grep       string `json:"grep"`        // 只保留包含该内容的行
+	Regex      bool   `json:"regex"`       // Grep 按正则表达式匹配
+	IgnoreCase bool   `json:"ignore_case"` // 匹配时忽略大小写
+
+	matcher func(string) bool
+}
+
+// LogLine 单行日志
+type LogLine struct {
+	Stream    string `json:"stream"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Text      string `json:"text"`
+}
+
+// String 以文本形式输出日志行，包含时间戳时放在行首
+func (l LogLine) String() string {
+	if l.Timestamp != "" {
+		return l.Timestamp + " " + l.Text
+	}
+	return l.Text
+}
+
+// Validate 校验选项并编译过滤条件，stdout 和 stderr 都未选择时视为全部选择
+func (o *LogOptions) Validate() error {
+	if !o.Stdout && !o.Stderr {
+		o.Stdout, o.Stderr = true, true
+	}
+
+	o.matcher = nil
+	if o.Grep == "" {
+		return nil
+	}
+	if o.Regex {
+		pattern := o.Grep
+		if o.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("无效的正则表达式: %w", err)
+		}
+		o.matcher = re.MatchString
+		return nil
+	}
+	if o.IgnoreCase {
+		needle := strings.ToLower(o.Grep)
+		o.matcher = func(s string) bool { return strings.Contains(strings.ToLower(s), needle) }
+		return nil
+	}
+	needle := o.Grep
+	o.matcher = func(s string) bool { return strings.Contains(s, needle) }
+	return nil
+}
+
+// Match 判断日志行是否满足过滤条件
+func (o *LogOptions) Match(line LogLine) bool {
+	return o.matcher == nil || o.matcher(line.Text)
+}
+
+// StreamLogs 逐行读取容器日志并回调，只回调满足过滤条件的行
+// 非 TTY 容器的 stdout/stderr 多路复用流会被拆分，回调返回错误时停止读取
+func (s *ContainerService) StreamLogs(ctx context.Context, containerID string, opts LogOptions, fn func(LogLine) error) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	inspect, err := s.client.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return fmt.Errorf("获取容器信息失败: %w", err)
+	}
+	tty := inspect.Config != nil && inspect.Config.Tty
+
+	reader, err := s.Logs(ctx, containerID, containerTypes.LogsOptions{
+		ShowStdout: opts.Stdout,
+		ShowStderr: opts.Stderr,
+		Since:      opts.Since,
+		Until:      opts.Until,
+		Timestamps: opts.Timestamps,
+		Follow:     opts.Follow,
+		Tail:       opts.Tail,
+	})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// 回调出错时写入返回 errStopLogs，使复制立即停止
+	var cbErr error
+	emit := func(line LogLine) error {
+		if !opts.Match(line) {
+			return nil
+		}
+		if err := fn(line); err != nil {
+			cbErr = err
+			return errStopLogs
+		}
+		return nil
+	}
+
+	stdout := &lineWriter{stream: StreamStdout, timestamps: opts.Timestamps, emit: emit}
+	stderr := &lineWriter{stream: StreamStderr, timestamps: opts.Timestamps, emit: emit}
+
+	if tty {
+		// TTY 容器的输出未复用，全部视为 stdout
+		_, err = io.Copy(stdout, reader)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, reader)
+	}
+	if err == nil {
+		if err = stdout.flush(); err == nil {
+			err = stderr.flush()
+		}
+	}
+
+	if cbErr != nil {
+		return cbErr
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("读取容器日志失败: %w", err)
+	}
+	return nil
+}
+
+// ReadLogs 读取满足条件的日志，limit 大于 0 时只保留最后 limit 行
+// truncated 表示是否有更早的匹配行被丢弃
+func (s *ContainerService) ReadLogs(ctx context.Context, containerID string, opts LogOptions, limit int) (lines []LogLine, truncated bool, err error) {
+	lines = []LogLine{}
+	err = s.StreamLogs(ctx, containerID, opts, func(line LogLine) error {
+		lines = append(lines, line)
+		if limit > 0 && len(lines) > limit {
+			// 定期压缩，避免切片无限增长
+			if len(lines) >= 2*limit {
+				lines = append(lines[:0], lines[len(lines)-limit:]...)
+			}
+			truncated = true
+		}
+		return nil
+	})
+	if limit > 0 && len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+	return lines, truncated, err
+}
+
+// lineWriter 将写入的数据按行拆分并回调，跨多次写入的行会被拼接
+type lineWriter struct {
+	stream     string
+	timestamps bool
+	buf        []byte
+	emit       func(LogLine) error
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			if room := maxLogLineBytes - len(w.buf); room > 0 {
+				if len(p) > room {
+					w.buf = append(w.buf, p[:room]...)
+				} else {
+					w.buf = append(w.buf, p...)
+				}
+			}
+			break
+		}
+		if room := maxLogLineBytes - len(w.buf); room > 0 {
+			if i > room {
+				w.buf = append(w.buf, p[:room]...)
+			} else {
+				w.buf = append(w.buf, p[:i]...)
+			}
+		}
+		p = p[i+1:]
+		if err := w.emitLine(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// flush 输出缓冲中未以换行结束的行
+func (w *lineWriter) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	return w.emitLine()
+}
+
+// emitLine 将缓冲作为一行输出并清空
+func (w *lineWriter) emitLine() error {
+	text := strings.TrimSuffix(string(w.buf), "\r")
+	w.buf = w.buf[:0]
+
+	line := LogLine{Stream: w.stream, Text: text}
+	if w.timestamps {
+		// Docker 在每行开头加上 RFC3339Nano 格式的时间戳和一个空格
+		if i := strings.IndexByte(text, ' '); i > 0 {
+			if _, err := time.Parse(time.RFC3339Nano, text[:i]); err == nil {
+				line.Timestamp = text[:i]
+				line.Text = text[i+1:]
+			}
+		}
+	}
+	return w.emit(line)
+}
@@ -0,0 +1,489 @@
+package docker
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/docker/go-units"
+)
+
+var (
+	// capabilityRegexp Linux capability 名称，可带 CAP_ 前缀
+	capabilityRegexp = regexp.MustCompile(`^(CAP_)?[A-Z][A-Z0-9_]*$`)
+	// cpusetRegexp cpuset 格式，如 0-3,5
+	cpusetRegexp = regexp.MustCompile(`^\d+(-\d+)?(,\d+(-\d+)?)*$`)
+	// containerNameRegexp 容器名称，与 Docker 的校验规则一致
+	containerNameRegexp = regexp.MustCompile(`^/?[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+	// signalRegexp 信号名称（SIGTERM、TERM、SIGRTMIN+1）或编号
+	signalRegexp = regexp.MustCompile(`^(SIG)?[A-Z][A-Z0-9]*([+-]\d+)?$|^\d+$`)
+)
+
+// ValidateSignal 校验信号名称或编号，空字符串表示使用默认信号
+func ValidateSignal(signal string) error {
+	if signal != "" && !signalRegexp.MatchString(signal) {
+		return fmt.Errorf("无效的信号: %q", signal)
+	}
+	return nil
+}
+
+// ValidateContainerName 校验容器名称
+func ValidateContainerName(name string) error {
+	if !containerNameRegexp.MatchString(name) {
+		return fmt.Errorf("无效的容器名称: %q", name)
+	}
+	return nil
+}
+
+// Build 校验选项并转换为 Docker API 的容器配置
+// 返回的错误以 JSON 字段名开头，便于定位无效参数
+func (o *CreateContainerOptions) Build() (*containerTypes.Config, *containerTypes.HostConfig, error) {
+	if strings.TrimSpace(o.Image) == "" {
+		return nil, nil, fmt.Errorf("image: 镜像不能为空")
+	}
+	if o.Name != "" {
+		if err := ValidateContainerName(o.Name); err != nil {
+			return nil, nil, fmt.Errorf("name: %w", err)
+		}
+	}
+
+	config := &containerTypes.Config{
+		Image:      o.Image,
+		Cmd:        o.Cmd,
+		Entrypoint: o.Entrypoint,
+		Env:        o.Env,
+		WorkingDir: o.WorkingDir,
+		User:       o.User,
+		Labels:     o.Labels,
+		StopSignal: o.StopSignal,
+	}
+	hostConfig := &containerTypes.HostConfig{
+		NetworkMode:    containerTypes.NetworkMode(o.Network),
+		Binds:          o.Volumes,
+		Tmpfs:          o.Tmpfs,
+		Privileged:     o.Privileged,
+		CapAdd:         o.CapAdd,
+		CapDrop:        o.CapDrop,
+		ReadonlyRootfs: o.ReadOnly,
+		ExtraHosts:     o.ExtraHosts,
+		DNS:            o.DNS,
+		DNSSearch:      o.DNSSearch,
+		DNSOptions:     o.DNSOptions,
+		Init:           o.Init,
+		LogConfig: containerTypes.LogConfig{
+			Type:   o.LogDriver,
+			Config: o.LogOptions,
+		},
+	}
+
+	for _, e := range o.Env {
+		if key, _, _ := strings.Cut(e, "="); strings.TrimSpace(key) == "" {
+			return nil, nil, fmt.Errorf("env: 环境变量缺少名称: %q", e)
+		}
+	}
+	if o.WorkingDir != "" && !path.IsAbs(o.WorkingDir) {
+		return nil, nil, fmt.Errorf("working_dir: 工作目录必须是绝对路径: %q", o.WorkingDir)
+	}
+
+	var err error
+	if config.ExposedPorts, hostConfig.PortBindings, err = buildPorts(o.Ports); err != nil {
+		return nil, nil, fmt.Errorf("ports: %w", err)
+	}
+	if hostConfig.RestartPolicy, err = parseRestartPolicy(o.Restart); err != nil {
+		return nil, nil, fmt.Errorf("restart: %w", err)
+	}
+	if err := validateBinds(o.Volumes); err != nil {
+		return nil, nil, fmt.Errorf("volumes: %w", err)
+	}
+	for target := range o.Tmpfs {
+		if !path.IsAbs(target) {
+			return nil, nil, fmt.Errorf("tmpfs: 挂载路径必须是绝对路径: %q", target)
+		}
+	}
+
+	if err := o.buildResources(&hostConfig.Resources); err != nil {
+		return nil, nil, err
+	}
+
+	if o.Healthcheck != nil {
+		if config.Healthcheck, err = o.Healthcheck.build(); err != nil {
+			return nil, nil, fmt.Errorf("healthcheck: %w", err)
+		}
+	}
+
+	for _, c := range append(append([]string(nil), o.CapAdd...), o.CapDrop...) {
+		if c != "ALL" && !capabilityRegexp.MatchString(c) {
+			return nil, nil, fmt.Errorf("cap_add/cap_drop: 无效的 capability: %q", c)
+		}
+	}
+
+	for _, d := range o.Devices {
+		device, err := parseDevice(d)
+		if err != nil {
+			return nil, nil, fmt.Errorf("devices: %w", err)
+		}
+		hostConfig.Devices = append(hostConfig.Devices, device)
+	}
+
+	for _, h := range o.ExtraHosts {
+		name, ip, ok := strings.Cut(h, ":")
+		if !ok || name == "" || (ip != "host-gateway" && net.ParseIP(ip) == nil) {
+			return nil, nil, fmt.Errorf("extra_hosts: 格式应为 主机名:IP: %q", h)
+		}
+	}
+	for _, ip := range o.DNS {
+		if net.ParseIP(ip) == nil {
+			return nil, nil, fmt.Errorf("dns: 无效的 IP 地址: %q", ip)
+		}
+	}
+
+	if len(o.LogOptions) > 0 && o.LogDriver == "" {
+		return nil, nil, fmt.Errorf("log_options: 指定日志选项时必须指定 log_driver")
+	}
+
+	if o.StopSignal != "" && !signalRegexp.MatchString(o.StopSignal) {
+		return nil, nil, fmt.Errorf("stop_signal: 无效的信号: %q", o.StopSignal)
+	}
+	if o.StopTimeout != nil {
+		if *o.StopTimeout < 0 {
+			return nil, nil, fmt.Errorf("stop_timeout: 不能为负数")
+		}
+		config.StopTimeout = o.StopTimeout
+	}
+
+	return config, hostConfig, nil
+}
+
+// buildResources 转换资源限制
+func (o *CreateContainerOptions) buildResources(r *containerTypes.Resources) error {
+	if o.CPUs < 0 {
+		return fmt.Errorf("cpus: 不能为负数")
+	}
+	r.NanoCPUs = int64(o.CPUs * 1e9)
+
+	if o.CPUShares < 0 {
+		return fmt.Errorf("cpu_shares: 不能为负数")
+	}
+	r.CPUShares = o.CPUShares
+
+	if o.CpusetCpus != "" && !cpusetRegexp.MatchString(o.CpusetCpus) {
+		return fmt.Errorf("cpuset_cpus: 格式应为 0-3 或 0,1: %q", o.CpusetCpus)
+	}
+	r.CpusetCpus = o.CpusetCpus
+
+	var err error
+	if r.Memory, err = parseMemory(o.Memory); err != nil {
+		return fmt.Errorf("memory: %w", err)
+	}
+	if r.MemoryReservation, err = parseMemory(o.MemoryReservation); err != nil {
+		return fmt.Errorf("memory_reservation: %w", err)
+	}
+	if o.MemorySwap == "-1" {
+		r.MemorySwap = -1
+	} else if r.MemorySwap, err = parseMemory(o.MemorySwap); err != nil {
+		return fmt.Errorf("memory_swap: %w", err)
+	}
+
+	if r.Memory > 0 && r.Memory < 6*1024*1024 {
+		return fmt.Errorf("memory: 内存限制不能小于 6MB")
+	}
+	if r.MemoryReservation > 0 && r.Memory > 0 && r.MemoryReservation > r.Memory {
+		return fmt.Errorf("memory_reservation: 不能大于 memory")
+	}
+	if r.MemorySwap > 0 {
+		if r.Memory == 0 {
+			return fmt.Errorf("memory_swap: 设置 memory_swap 时必须设置 memory")
+		}
+		if r.MemorySwap < r.Memory {
+			return fmt.Errorf("memory_swap: 不能小于 memory")
+		}
+	}
+
+	r.PidsLimit = o.PidsLimit
+
+	for _, u := range o.Ulimits {
+		ulimit, err := units.ParseUlimit(u)
+		if err != nil {
+			return fmt.Errorf("ulimits: %w", err)
+		}
+		r.Ulimits = append(r.Ulimits, &containerTypes.Ulimit{
+			Name: ulimit.Name,
+			Soft: ulimit.Soft,
+			Hard: ulimit.Hard,
+		})
+	}
+	return nil
+}
+
+// UpdateResourcesOptions 在线更新容器资源的选项，字段为空表示不修改
+type UpdateResourcesOptions struct {
+	CPUs              *float64 `json:"cpus"`
+	CPUShares         *int64   `json:"cpu_shares"`
+	CpusetCpus        *string  `json:"cpuset_cpus"`
+	Memory            *string  `json:"memory"`
+	MemoryReservation *string  `json:"memory_reservation"`
+	MemorySwap        *string  `json:"memory_swap"` // -1 表示不限制
+	PidsLimit         *int64   `json:"pids_limit"`
+	Restart           *string  `json:"restart"`
+}
+
+// Build 校验选项并转换为 ContainerUpdate 的参数
+func (o *UpdateResourcesOptions) Build() (containerTypes.UpdateConfig, error) {
+	var update containerTypes.UpdateConfig
+	r := &update.Resources
+
+	if o.CPUs != nil {
+		if *o.CPUs < 0 {
+			return update, fmt.Errorf("cpus: 不能为负数")
+		}
+		r.NanoCPUs = int64(*o.CPUs * 1e9)
+	}
+	if o.CPUShares != nil {
+		if *o.CPUShares < 0 {
+			return update, fmt.Errorf("cpu_shares: 不能为负数")
+		}
+		r.CPUShares = *o.CPUShares
+	}
+	if o.CpusetCpus != nil {
+		if !cpusetRegexp.MatchString(*o.CpusetCpus) {
+			return update, fmt.Errorf("cpuset_cpus: 格式应为 0-3 或 0,1: %q", *o.CpusetCpus)
+		}
+		r.CpusetCpus = *o.CpusetCpus
+	}
+
+	var err error
+	if o.Memory != nil {
+		if r.Memory, err = parseMemory(*o.Memory); err != nil {
+			return update, fmt.Errorf("memory: %w", err)
+		}
+		if r.Memory > 0 && r.Memory < 6*1024*1024 {
+			return update, fmt.Errorf("memory: 内存限制不能小于 6MB")
+		}
+	}
+	if o.MemoryReservation != nil {
+		if r.MemoryReservation, err = parseMemory(*o.MemoryReservation); err != nil {
+			return update, fmt.Errorf("memory_reservation: %w", err)
+		}
+	}
+	if o.MemorySwap != nil {
+		if *o.MemorySwap == "-1" {
+			r.MemorySwap = -1
+		} else if r.MemorySwap, err = parseMemory(*o.MemorySwap); err != nil {
+			return update, fmt.Errorf("memory_swap: %w", err)
+		}
+	}
+	if r.Memory > 0 && r.MemorySwap > 0 && r.MemorySwap < r.Memory {
+		return update, fmt.Errorf("memory_swap: 不能小于 memory")
+	}
+
+	if o.PidsLimit != nil {
+		r.PidsLimit = o.PidsLimit
+	}
+
+	if o.Restart != nil {
+		if update.RestartPolicy, err = parseRestartPolicy(*o.Restart); err != nil {
+			return update, fmt.Errorf("restart: %w", err)
+		}
+	}
+	return update, nil
+}
+
+// build 转换健康检查配置
+func (h *HealthcheckOptions) build() (*containerTypes.HealthConfig, error) {
+	test := h.Test
+	switch {
+	case len(test) == 0:
+		return nil, fmt.Errorf("test 不能为空")
+	case test[0] == "NONE":
+		if len(test) != 1 {
+			return nil, fmt.Errorf("NONE 之后不能有其他参数")
+		}
+	case test[0] == "CMD" || test[0] == "CMD-SHELL":
+		if len(test) < 2 {
+			return nil, fmt.Errorf("%s 之后必须指定命令", test[0])
+		}
+	case len(test) == 1:
+		test = []string{"CMD-SHELL", test[0]}
+	default:
+		return nil, fmt.Errorf("test 的第一个元素必须是 NONE、CMD 或 CMD-SHELL")
+	}
+
+	hc := &containerTypes.HealthConfig{Test: test, Retries: h.Retries}
+	if h.Retries < 0 {
+		return nil, fmt.Errorf("retries 不能为负数")
+	}
+
+	var err error
+	if hc.Interval, err = parseHealthDuration("interval", h.Interval); err != nil {
+		return nil, err
+	}
+	if hc.Timeout, err = parseHealthDuration("timeout", h.Timeout); err != nil {
+		return nil, err
+	}
+	if hc.StartPeriod, err = parseHealthDuration("start_period", h.StartPeriod); err != nil {
+		return nil, err
+	}
+	if hc.StartInterval, err = parseHealthDuration("start_interval", h.StartInterval); err != nil {
+		return nil, err
+	}
+	return hc, nil
+}
+
+// parseHealthDuration 解析健康检查时间，Docker 要求非零值至少为 1ms
+func parseHealthDuration(field, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s 格式无效（如 30s、1m）: %q", field, value)
+	}
+	if d != 0 && d < time.Millisecond {
+		return 0, fmt.Errorf("%s 不能小于 1ms", field)
+	}
+	return d, nil
+}
+
+// parseMemory 解析内存大小（如 512m、1g），空字符串表示不限制
+func parseMemory(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := units.RAMInBytes(value)
+	if err != nil {
+		return 0, fmt.Errorf("无法解析内存大小 %q", value)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("内存大小不能为负数")
+	}
+	return n, nil
+}
+
+// parseRestartPolicy 解析重启策略，支持 on-failure:N
+func parseRestartPolicy(value string) (containerTypes.RestartPolicy, error) {
+	name, count, hasCount := strings.Cut(value, ":")
+	policy := containerTypes.RestartPolicy{Name: containerTypes.RestartPolicyMode(name)}
+
+	switch name {
+	case "", "no":
+		policy.Name = containerTypes.RestartPolicyDisabled
+	case "always", "unless-stopped", "on-failure":
+	default:
+		return policy, fmt.Errorf("无效的重启策略 %q，可选 no、always、unless-stopped、on-failure[:N]", value)
+	}
+
+	if hasCount {
+		if name != "on-failure" {
+			return policy, fmt.Errorf("只有 on-failure 可以指定最大重试次数")
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 0 {
+			return policy, fmt.Errorf("无效的最大重试次数: %q", count)
+		}
+		policy.MaximumRetryCount = n
+	}
+	return policy, nil
+}
+
+// buildPorts 转换端口映射，容器端口未指定协议时默认 tcp
+func buildPorts(ports map[string]string) (nat.PortSet, nat.PortMap, error) {
+	portSet := make(nat.PortSet)
+	portMap := make(nat.PortMap)
+	for containerPort, hostPort := range ports {
+		proto, portStr := nat.SplitProtoPort(containerPort)
+		if _, err := nat.ParsePort(portStr); err != nil || portStr == "" {
+			return nil, nil, fmt.Errorf("无效的容器端口: %q", containerPort)
+		}
+		if proto != "tcp" && proto != "udp" && proto != "sctp" {
+			return nil, nil, fmt.Errorf("无效的端口协议: %q", containerPort)
+		}
+		port, err := nat.NewPort(proto, portStr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("无效的容器端口: %q", containerPort)
+		}
+
+		// 主机端口可以写成 IP:端口
+		binding := nat.PortBinding{HostPort: hostPort}
+		if i := strings.LastIndex(hostPort, ":"); i >= 0 {
+			binding.HostIP, binding.HostPort = strings.Trim(hostPort[:i], "[]"), hostPort[i+1:]
+			if net.ParseIP(binding.HostIP) == nil {
+				return nil, nil, fmt.Errorf("无效的主机 IP: %q", hostPort)
+			}
+		}
+		if binding.HostPort != "" {
+			if _, _, err := nat.ParsePortRange(binding.HostPort); err != nil {
+				return nil, nil, fmt.Errorf("无效的主机端口: %q", hostPort)
+			}
+		}
+
+		portSet[port] = struct{}{}
+		portMap[port] = append(portMap[port], binding)
+	}
+	return portSet, portMap, nil
+}
+
+// validateBinds 校验 source:target[:options] 格式的挂载
+func validateBinds(binds []string) error {
+	for _, bind := range binds {
+		parts := strings.Split(bind, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return fmt.Errorf("格式应为 源路径或卷名:容器路径[:选项]: %q", bind)
+		}
+		if !path.IsAbs(parts[1]) {
+			return fmt.Errorf("容器路径必须是绝对路径: %q", bind)
+		}
+	}
+	return nil
+}
+
+// parseDevice 解析 host[:container[:permissions]] 格式的设备映射
+func parseDevice(value string) (containerTypes.DeviceMapping, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 || parts[0] == "" {
+		return containerTypes.DeviceMapping{}, fmt.Errorf("格式应为 主机设备[:容器设备[:权限]]: %q", value)
+	}
+
+	device := containerTypes.DeviceMapping{
+		PathOnHost:        parts[0],
+		PathInContainer:   parts[0],
+		CgroupPermissions: "rwm",
+	}
+	if len(parts) >= 2 {
+		// 只有两段且第二段是权限时，表示 host:permissions
+		if len(parts) == 2 && isDevicePermissions(parts[1]) {
+			device.CgroupPermissions = parts[1]
+		} else {
+			device.PathInContainer = parts[1]
+		}
+	}
+	if len(parts) == 3 {
+		if !isDevicePermissions(parts[2]) {
+			return device, fmt.Errorf("设备权限只能由 r、w、m 组成: %q", value)
+		}
+		device.CgroupPermissions = parts[2]
+	}
+
+	if !path.IsAbs(device.PathOnHost) || !path.IsAbs(device.PathInContainer) {
+		return device, fmt.Errorf("设备路径必须是绝对路径: %q", value)
+	}
+	return device, nil
+}
+
+// isDevicePermissions 判断是否为有效的设备权限字符串
+func isDevicePermissions(s string) bool {
+	if s == "" || len(s) > 3 {
+		return false
+	}
+	for _, c := range s {
+		if c != 'r' && c != 'w' && c != 'm' {
+			return false
+		}
+	}
+	return true
+}
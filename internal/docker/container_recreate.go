@@ -0,0 +1,485 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	dockerspec "github.com/moby/docker-image-spec/specs-go/v1"
+)
+
+// RecreateOptions 重建容器选项
+type RecreateOptions struct {
+	// Image 新镜像，为空时沿用原镜像
+	Image string `json:"image"`
+	ContainerPatch
+}
+
+// ContainerPatch 重建时对原配置的修改，未指定的字段沿用原配置
+// map 字段按键合并，值为 null 表示删除该键
+type ContainerPatch struct {
+	Env        map[string]*string `json:"env"`
+	Labels     map[string]*string `json:"labels"`
+	Ports      map[string]*string `json:"ports"` // "80/tcp": "8080"
+	Cmd        *[]string          `json:"cmd"`
+	Entrypoint *[]string          `json:"entrypoint"`
+	User       *string            `json:"user"`
+	WorkingDir *string            `json:"working_dir"`
+	Volumes    *[]string          `json:"volumes"` // 替换全部 bind 挂载
+	Restart    *string            `json:"restart"`
+	CPUs       *float64           `json:"cpus"`
+	Memory     *string            `json:"memory"`
+}
+
+// RecreateResult 重建结果，用于确认或回滚
+type RecreateResult struct {
+	Name       string `json:"name"`
+	OldID      string `json:"old_id"`
+	NewID      string `json:"new_id"`
+	BackupName string `json:"backup_name"`
+	OldImage   string `json:"old_image"`
+	NewImage   string `json:"new_image"`
+	WasRunning bool   `json:"was_running"`
+}
+
+// Recreate 使用原容器的配置重建容器
+// 流程：停止旧容器 -> 重命名为备份名 -> 以原名称创建新容器 -> 启动
+// 任一步骤失败都会自动恢复旧容器。成功后旧容器仍被保留，
+// 调用方需要通过 CommitRecreate 删除，或通过 RollbackRecreate 回滚
+func (s *ContainerService) Recreate(ctx context.Context, containerID string, opts RecreateOptions) (*RecreateResult, error) {
+	old, err := s.client.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("获取容器详情失败: %w", err)
+	}
+
+	config, hostConfig, endpoints, err := s.cloneContainerConfig(ctx, old, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimPrefix(old.Name, "/")
+	result := &RecreateResult{
+		Name:       name,
+		OldID:      old.ID,
+		BackupName: fmt.Sprintf("%s_old_%d", name, time.Now().Unix()),
+		OldImage:   old.Config.Image,
+		NewImage:   config.Image,
+		WasRunning: old.State.Running,
+	}
+
+	if result.WasRunning {
+		if err := s.Stop(ctx, old.ID, old.Config.StopTimeout); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.client.ContainerRename(ctx, old.ID, result.BackupName); err != nil {
+		s.restoreOld(result)
+		return nil, fmt.Errorf("重命名旧容器失败: %w", err)
+	}
+
+	// 创建时只能指定一个网络，其余网络在创建后连接
+	// 网络模式为 default 时容器创建在 bridge 网络上，端点以 bridge 为键
+	primary := string(hostConfig.NetworkMode)
+	if primary == "" || hostConfig.NetworkMode.IsDefault() {
+		primary = network.NetworkBridge
+	}
+	var networkingConfig *network.NetworkingConfig
+	if ep, ok := endpoints[primary]; ok {
+		networkingConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{primary: ep},
+		}
+	}
+
+	resp, err := s.client.ContainerCreate(ctx, config, hostConfig, networkingConfig, nil, name)
+	if err != nil {
+		s.rollback(result)
+		return nil, fmt.Errorf("创建新容器失败: %w", err)
+	}
+	result.NewID = resp.ID
+
+	for netName, ep := range endpoints {
+		if netName == primary {
+			continue
+		}
+		if err := s.client.NetworkConnect(ctx, netName, resp.ID, ep); err != nil {
+			s.rollback(result)
+			return nil, fmt.Errorf("连接网络 %s 失败: %w", netName, err)
+		}
+	}
+
+	if result.WasRunning {
+		if err := s.client.ContainerStart(ctx, resp.ID, containerTypes.StartOptions{}); err != nil {
+			s.rollback(result)
+			return nil, fmt.Errorf("启动新容器失败: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// CommitRecreate 确认重建结果，删除旧容器
+func (s *ContainerService) CommitRecreate(ctx context.Context, result *RecreateResult) error {
+	err := s.client.ContainerRemove(ctx, result.OldID, containerTypes.RemoveOptions{Force: true})
+	if err != nil {
+		return fmt.Errorf("删除旧容器失败: %w", err)
+	}
+	return nil
+}
+
+// RollbackRecreate 回滚重建：删除新容器并恢复旧容器
+func (s *ContainerService) RollbackRecreate(ctx context.Context, result *RecreateResult) error {
+	if result.NewID != "" {
+		err := s.client.ContainerRemove(ctx, result.NewID, containerTypes.RemoveOptions{Force: true})
+		if err != nil {
+			return fmt.Errorf("删除新容器失败: %w", err)
+		}
+	}
+
+	if err := s.client.ContainerRename(ctx, result.OldID, result.Name); err != nil {
+		return fmt.Errorf("恢复旧容器名称失败: %w", err)
+	}
+
+	if result.WasRunning {
+		if err := s.client.ContainerStart(ctx, result.OldID, containerTypes.StartOptions{}); err != nil {
+			return fmt.Errorf("启动旧容器失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// WaitHealthy 在宽限期内持续观察容器状态
+// 容器退出、不断重启或健康检查为 unhealthy 时返回错误；
+// 配置了健康检查的容器在宽限期结束时必须为 healthy
+func (s *ContainerService) WaitHealthy(ctx context.Context, containerID string, grace time.Duration) error {
+	deadline := time.Now().Add(grace)
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		c, err := s.client.ContainerInspect(ctx, containerID)
+		if err != nil {
+			return fmt.Errorf("获取容器详情失败: %w", err)
+		}
+
+		state := c.State
+		if state.Restarting || !state.Running {
+			return fmt.Errorf("容器未正常运行: %s", buildStatusString(state))
+		}
+		if state.Health != nil && state.Health.Status == containerTypes.Unhealthy {
+			return fmt.Errorf("容器健康检查失败")
+		}
+
+		if !time.Now().Before(deadline) {
+			if state.Health != nil && state.Health.Status != containerTypes.Healthy {
+				return fmt.Errorf("容器在宽限期内未通过健康检查: %s", state.Health.Status)
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// cloneContainerConfig 根据 inspect 结果复制创建容器所需的配置
+func (s *ContainerService) cloneContainerConfig(ctx context.Context, old containerTypes.InspectResponse, opts RecreateOptions) (*containerTypes.Config, *containerTypes.HostConfig, map[string]*network.EndpointSettings, error) {
+	config := *old.Config
+	hostConfig := *old.HostConfig
+
+	// 主机名默认为容器 ID 前缀，新容器应生成自己的主机名
+	if len(old.ID) >= 12 && config.Hostname == old.ID[:12] {
+		config.Hostname = ""
+	}
+
+	if opts.Image != "" {
+		// inspect 中的配置已合并了旧镜像的默认值，需要去掉这些继承值使新镜像的默认值生效
+		s.stripImageDefaults(ctx, &config, old.Image)
+		config.Image = opts.Image
+	}
+
+	if err := opts.ContainerPatch.apply(&config, &hostConfig); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// 保留匿名卷，避免新容器丢失旧容器中的数据
+	// 旧配置中显式指定的命名卷由 Binds 和 Mounts 决定，补丁移除后不再挂载
+	hostConfig.Mounts = append([]mount.Mount(nil), hostConfig.Mounts...)
+	for _, m := range old.Mounts {
+		if m.Type != mount.TypeVolume || m.Name == "" || volumeNamed(old.HostConfig, m.Name) || mountCovered(&hostConfig, m.Destination) {
+			continue
+		}
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+			Type:     mount.TypeVolume,
+			Source:   m.Name,
+			Target:   m.Destination,
+			ReadOnly: !m.RW,
+		})
+	}
+
+	endpoints := make(map[string]*network.EndpointSettings)
+	if old.NetworkSettings != nil {
+		for name, ep := range old.NetworkSettings.Networks {
+			if ep == nil {
+				continue
+			}
+			endpoints[name] = &network.EndpointSettings{
+				IPAMConfig: ep.IPAMConfig,
+				Links:      ep.Links,
+				Aliases:    slices.DeleteFunc(slices.Clone(ep.Aliases), func(a string) bool { return strings.HasPrefix(old.ID, a) }),
+				DriverOpts: ep.DriverOpts,
+			}
+		}
+	}
+
+	return &config, &hostConfig, endpoints, nil
+}
+
+// stripImageDefaults 去掉从旧镜像继承的配置，使新镜像的默认值生效
+// 旧镜像已被删除时无法区分继承值，保留原配置
+func (s *ContainerService) stripImageDefaults(ctx context.Context, config *containerTypes.Config, imageID string) {
+	img, _, err := s.client.ImageInspectWithRaw(ctx, imageID)
+	if err != nil || img.Config == nil {
+		return
+	}
+	imgConfig := img.Config
+
+	config.Env = slices.DeleteFunc(slices.Clone(config.Env), func(e string) bool { return slices.Contains(imgConfig.Env, e) })
+	if slices.Equal(config.Cmd, imgConfig.Cmd) {
+		config.Cmd = nil
+	}
+	if slices.Equal(config.Entrypoint, imgConfig.Entrypoint) {
+		config.Entrypoint = nil
+	}
+	if config.WorkingDir == imgConfig.WorkingDir {
+		config.WorkingDir = ""
+	}
+	if config.User == imgConfig.User {
+		config.User = ""
+	}
+	if config.StopSignal == imgConfig.StopSignal {
+		config.StopSignal = ""
+	}
+	if sameHealthcheck(config.Healthcheck, imgConfig.Healthcheck) {
+		config.Healthcheck = nil
+	}
+	if len(config.Labels) > 0 {
+		labels := make(map[string]string, len(config.Labels))
+		for k, v := range config.Labels {
+			if imgValue, ok := imgConfig.Labels[k]; !ok || imgValue != v {
+				labels[k] = v
+			}
+		}
+		config.Labels = labels
+	}
+	if len(config.Volumes) > 0 {
+		volumes := make(map[string]struct{}, len(config.Volumes))
+		for vol := range config.Volumes {
+			if _, ok := imgConfig.Volumes[vol]; !ok {
+				volumes[vol] = struct{}{}
+			}
+		}
+		config.Volumes = volumes
+	}
+}
+
+// sameHealthcheck 判断容器健康检查配置是否与镜像中的一致
+func sameHealthcheck(hc *containerTypes.HealthConfig, imgHC *dockerspec.HealthcheckConfig) bool {
+	if hc == nil || imgHC == nil {
+		return hc == nil && imgHC == nil
+	}
+	return slices.Equal(hc.Test, imgHC.Test) &&
+		hc.Interval == imgHC.Interval &&
+		hc.Timeout == imgHC.Timeout &&
+		hc.StartPeriod == imgHC.StartPeriod &&
+		hc.StartInterval == imgHC.StartInterval &&
+		hc.Retries == imgHC.Retries
+}
+
+// Validate 校验修改内容，不访问 Docker
+func (p *ContainerPatch) Validate() error {
+	return p.apply(&containerTypes.Config{}, &containerTypes.HostConfig{})
+}
+
+// apply 将修改应用到复制出的配置上，会替换而不是修改共享的 map 和切片
+func (p *ContainerPatch) apply(config *containerTypes.Config, hostConfig *containerTypes.HostConfig) error {
+	if len(p.Env) > 0 {
+		env := make([]string, 0, len(config.Env)+len(p.Env))
+		for _, e := range config.Env {
+			key, _, _ := strings.Cut(e, "=")
+			if _, ok := p.Env[key]; !ok {
+				env = append(env, e)
+			}
+		}
+		keys := make([]string, 0, len(p.Env))
+		for key := range p.Env {
+			if strings.TrimSpace(key) == "" || strings.Contains(key, "=") {
+				return fmt.Errorf("env: 无效的环境变量名: %q", key)
+			}
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			if value := p.Env[key]; value != nil {
+				env = append(env, key+"="+*value)
+			}
+		}
+		config.Env = env
+	}
+
+	if len(p.Labels) > 0 {
+		labels := make(map[string]string, len(config.Labels)+len(p.Labels))
+		for k, v := range config.Labels {
+			labels[k] = v
+		}
+		for k, v := range p.Labels {
+			if v == nil {
+				delete(labels, k)
+			} else {
+				labels[k] = *v
+			}
+		}
+		config.Labels = labels
+	}
+
+	if len(p.Ports) > 0 {
+		exposed := make(nat.PortSet, len(config.ExposedPorts))
+		for port := range config.ExposedPorts {
+			exposed[port] = struct{}{}
+		}
+		bindings := make(nat.PortMap, len(hostConfig.PortBindings))
+		for port, b := range hostConfig.PortBindings {
+			bindings[port] = b
+		}
+
+		for containerPort, hostPort := range p.Ports {
+			if hostPort == nil {
+				proto, portStr := nat.SplitProtoPort(containerPort)
+				port, err := nat.NewPort(proto, portStr)
+				if err != nil {
+					return fmt.Errorf("ports: 无效的容器端口: %q", containerPort)
+				}
+				delete(exposed, port)
+				delete(bindings, port)
+				continue
+			}
+			portSet, portMap, err := buildPorts(map[string]string{containerPort: *hostPort})
+			if err != nil {
+				return fmt.Errorf("ports: %w", err)
+			}
+			for port := range portSet {
+				exposed[port] = struct{}{}
+				bindings[port] = portMap[port]
+			}
+		}
+		config.ExposedPorts = exposed
+		hostConfig.PortBindings = bindings
+	}
+
+	if p.Cmd != nil {
+		config.Cmd = *p.Cmd
+	}
+	if p.Entrypoint != nil {
+		config.Entrypoint = *p.Entrypoint
+	}
+	if p.User != nil {
+		config.User = *p.User
+	}
+	if p.WorkingDir != nil {
+		config.WorkingDir = *p.WorkingDir
+	}
+
+	if p.Volumes != nil {
+		if err := validateBinds(*p.Volumes); err != nil {
+			return fmt.Errorf("volumes: %w", err)
+		}
+		hostConfig.Binds = *p.Volumes
+	}
+
+	if p.Restart != nil {
+		policy, err := parseRestartPolicy(*p.Restart)
+		if err != nil {
+			return fmt.Errorf("restart: %w", err)
+		}
+		hostConfig.RestartPolicy = policy
+	}
+
+	if p.CPUs != nil {
+		if *p.CPUs < 0 {
+			return fmt.Errorf("cpus: 不能为负数")
+		}
+		hostConfig.NanoCPUs = int64(*p.CPUs * 1e9)
+	}
+
+	if p.Memory != nil {
+		memory, err := parseMemory(*p.Memory)
+		if err != nil {
+			return fmt.Errorf("memory: %w", err)
+		}
+		hostConfig.Memory = memory
+		// 原有的交换分区限制可能小于新的内存限制，交由 Docker 按默认规则计算
+		if hostConfig.MemorySwap > 0 {
+			hostConfig.MemorySwap = 0
+		}
+	}
+
+	return nil
+}
+
+// restoreOld 重命名前失败时，恢复旧容器的运行状态
+func (s *ContainerService) restoreOld(result *RecreateResult) {
+	if !result.WasRunning {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	s.client.ContainerStart(ctx, result.OldID, containerTypes.StartOptions{})
+}
+
+// rollback 重建过程中失败时回滚，使用独立的 context 以免请求取消导致回滚中断
+func (s *ContainerService) rollback(result *RecreateResult) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	s.RollbackRecreate(ctx, result)
+}
+
+// volumeNamed 判断卷是否在 Binds 或 Mounts 中按名称指定
+func volumeNamed(hostConfig *containerTypes.HostConfig, name string) bool {
+	for _, m := range hostConfig.Mounts {
+		if m.Type == mount.TypeVolume && m.Source == name {
+			return true
+		}
+	}
+	for _, bind := range hostConfig.Binds {
+		parts := strings.Split(bind, ":")
+		if len(parts) >= 2 && parts[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// mountCovered 判断目标路径是否已被 Binds 或 Mounts 覆盖
+func mountCovered(hostConfig *containerTypes.HostConfig, destination string) bool {
+	for _, m := range hostConfig.Mounts {
+		if m.Target == destination {
+			return true
+		}
+	}
+	for _, bind := range hostConfig.Binds {
+		parts := strings.Split(bind, ":")
+		if len(parts) >= 2 && parts[1] == destination {
+			return true
+		}
+	}
+	return false
+}
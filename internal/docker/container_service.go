@@ -2,6 +2,7 @@ package docker
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
@@ -9,8 +10,8 @@ import (
 
 	"github.com/docker/docker/api/types"
 	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
-	"github.com/docker/go-connections/nat"
 )
 
 // ContainerService 容器服务
@@ -76,13 +77,63 @@ type CreateContainerOptions struct {
 	Name       string            `json:"name"`
 	Image      string            `json:"image"`
 	Cmd        []string          `json:"cmd"`
+	Entrypoint []string          `json:"entrypoint"`
 	Env        []string          `json:"env"`
 	WorkingDir string            `json:"working_dir"`
+	User       string            `json:"user"`
 	Ports      map[string]string `json:"ports"` // "80/tcp": "8080"
 	Volumes    []string          `json:"volumes"`
+	Tmpfs      map[string]string `json:"tmpfs"` // "/run": "rw,size=64m"
 	Network    string            `json:"network"`
 	Labels     map[string]string `json:"labels"`
-	Restart    string            `json:"restart"`
+	Restart    string            `json:"restart"` // no, always, unless-stopped, on-failure[:N]
+
+	// 资源限制
+	CPUs              float64  `json:"cpus"`               // CPU 核数，如 1.5
+	CPUShares         int64    `json:"cpu_shares"`         // CPU 相对权重
+	CpusetCpus        string   `json:"cpuset_cpus"`        // 如 0-3 或 0,1
+	Memory            string   `json:"memory"`             // 如 512m、1g
+	MemoryReservation string   `json:"memory_reservation"` // 内存软限制
+	MemorySwap        string   `json:"memory_swap"`        // 内存+交换分区总量，-1 表示不限制
+	PidsLimit         *int64   `json:"pids_limit"`
+	Ulimits           []string `json:"ulimits"` // 如 nofile=1024:2048
+
+	// 健康检查
+	Healthcheck *HealthcheckOptions `json:"healthcheck"`
+
+	// 安全
+	Privileged bool     `json:"privileged"`
+	CapAdd     []string `json:"cap_add"`
+	CapDrop    []string `json:"cap_drop"`
+	Devices    []string `json:"devices"`   // 如 /dev/sda:/dev/xvda:rwm
+	ReadOnly   bool     `json:"read_only"` // 只读根文件系统
+
+	// 网络
+	ExtraHosts []string `json:"extra_hosts"` // 如 db:10.0.0.2、host.docker.internal:host-gateway
+	DNS        []string `json:"dns"`
+	DNSSearch  []string `json:"dns_search"`
+	DNSOptions []string `json:"dns_options"`
+
+	// 日志
+	LogDriver  string            `json:"log_driver"`
+	LogOptions map[string]string `json:"log_options"`
+
+	// 进程
+	Init        *bool  `json:"init"`
+	StopSignal  string `json:"stop_signal"`
+	StopTimeout *int   `json:"stop_timeout"` // 秒
+}
+
+// HealthcheckOptions 健康检查选项，时间字段使用 Go duration 格式（如 30s、1m）
+type HealthcheckOptions struct {
+	// Test 为 ["NONE"] 禁用镜像中的健康检查；["CMD", ...] 直接执行；
+	// ["CMD-SHELL", "cmd"] 或只有一个元素时通过 shell 执行
+	Test          []string `json:"test"`
+	Interval      string   `json:"interval"`
+	Timeout       string   `json:"timeout"`
+	StartPeriod   string   `json:"start_period"`
+	StartInterval string   `json:"start_interval"`
+	Retries       int      `json:"retries"`
 }
 
 // ContainerStats 容器资源统计
@@ -99,8 +150,19 @@ type ContainerStats struct {
 
 // List 列出容器
 func (s *ContainerService) List(ctx context.Context, all bool) ([]ContainerInfo, error) {
+	return s.ListByLabel(ctx, all)
+}
+
+// ListByLabel 按标签筛选容器，selectors 格式为 "key" 或 "key=value"
+func (s *ContainerService) ListByLabel(ctx context.Context, all bool, selectors ...string) ([]ContainerInfo, error) {
+	args := filters.NewArgs()
+	for _, selector := range selectors {
+		args.Add("label", selector)
+	}
+
 	containers, err := s.client.ContainerList(ctx, containerTypes.ListOptions{
-		All: all,
+		All:     all,
+		Filters: args,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("获取容器列表失败: %w", err)
@@ -139,6 +201,17 @@ func (s *ContainerService) List(ctx context.Context, all bool) ([]ContainerInfo,
 	return result, nil
 }
 
+// ParseLabelSelector 将逗号分隔的标签选择器（如 "app=web,tier"）拆分为 ListByLabel 的参数
+func ParseLabelSelector(selector string) []string {
+	var selectors []string
+	for _, s := range strings.Split(selector, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			selectors = append(selectors, s)
+		}
+	}
+	return selectors
+}
+
 // Get 获取容器详情
 func (s *ContainerService) Get(ctx context.Context, containerID string) (*ContainerInfo, error) {
 	c, err := s.client.ContainerInspect(ctx, containerID)
@@ -207,44 +280,9 @@ func (s *ContainerService) Get(ctx context.Context, containerID string) (*Contai
 
 // Create 创建容器
 func (s *ContainerService) Create(ctx context.Context, opts CreateContainerOptions) (containerTypes.CreateResponse, error) {
-	// 构建端口绑定配置
-	portSet := make(nat.PortSet)
-	portMap := make(nat.PortMap)
-	for containerPort, hostPort := range opts.Ports {
-		port := nat.Port(containerPort)
-		portSet[port] = struct{}{}
-		portMap[port] = []nat.PortBinding{
-			{HostPort: hostPort},
-		}
-	}
-
-	// 构建重启策略
-	var restartPolicy containerTypes.RestartPolicy
-	switch opts.Restart {
-	case "always":
-		restartPolicy = containerTypes.RestartPolicy{Name: "always"}
-	case "on-failure":
-		restartPolicy = containerTypes.RestartPolicy{Name: "on-failure"}
-	case "unless-stopped":
-		restartPolicy = containerTypes.RestartPolicy{Name: "unless-stopped"}
-	default:
-		restartPolicy = containerTypes.RestartPolicy{Name: "no"}
-	}
-
-	config := &containerTypes.Config{
-		Image:        opts.Image,
-		Cmd:          opts.Cmd,
-		Env:          opts.Env,
-		WorkingDir:   opts.WorkingDir,
-		Labels:       opts.Labels,
-		ExposedPorts: portSet,
-	}
-
-	hostConfig := &containerTypes.HostConfig{
-		PortBindings:  portMap,
-		RestartPolicy: restartPolicy,
-		NetworkMode:   containerTypes.NetworkMode(opts.Network),
-		Binds:         opts.Volumes,
+	config, hostConfig, err := opts.Build()
+	if err != nil {
+		return containerTypes.CreateResponse{}, err
 	}
 
 	resp, err := s.client.ContainerCreate(ctx, config, hostConfig, nil, nil, opts.Name)
@@ -302,6 +340,79 @@ func (s *ContainerService) Remove(ctx context.Context, containerID string, force
 	return nil
 }
 
+// Pause 暂停容器
+func (s *ContainerService) Pause(ctx context.Context, containerID string) error {
+	if err := s.client.ContainerPause(ctx, containerID); err != nil {
+		return fmt.Errorf("暂停容器失败: %w", err)
+	}
+	return nil
+}
+
+// Unpause 恢复已暂停的容器
+func (s *ContainerService) Unpause(ctx context.Context, containerID string) error {
+	if err := s.client.ContainerUnpause(ctx, containerID); err != nil {
+		return fmt.Errorf("恢复容器失败: %w", err)
+	}
+	return nil
+}
+
+// Kill 向容器发送信号，signal 为空时发送 SIGKILL
+func (s *ContainerService) Kill(ctx context.Context, containerID, signal string) error {
+	if err := ValidateSignal(signal); err != nil {
+		return err
+	}
+	if err := s.client.ContainerKill(ctx, containerID, signal); err != nil {
+		return fmt.Errorf("发送信号失败: %w", err)
+	}
+	return nil
+}
+
+// Rename 重命名容器
+func (s *ContainerService) Rename(ctx context.Context, containerID, newName string) error {
+	if err := ValidateContainerName(newName); err != nil {
+		return err
+	}
+	if err := s.client.ContainerRename(ctx, containerID, newName); err != nil {
+		return fmt.Errorf("重命名容器失败: %w", err)
+	}
+	return nil
+}
+
+// UpdateResources 在线更新容器的资源限制和重启策略
+func (s *ContainerService) UpdateResources(ctx context.Context, containerID string, opts UpdateResourcesOptions) ([]string, error) {
+	updateConfig, err := opts.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.ContainerUpdate(ctx, containerID, updateConfig)
+	if err != nil {
+		return nil, fmt.Errorf("更新容器资源失败: %w", err)
+	}
+	return resp.Warnings, nil
+}
+
+// Wait 等待容器达到指定状态，返回退出码
+// condition 可选 not-running（默认）、next-exit、removed
+func (s *ContainerService) Wait(ctx context.Context, containerID, condition string) (int64, error) {
+	switch containerTypes.WaitCondition(condition) {
+	case "", containerTypes.WaitConditionNotRunning, containerTypes.WaitConditionNextExit, containerTypes.WaitConditionRemoved:
+	default:
+		return 0, fmt.Errorf("无效的等待条件: %q", condition)
+	}
+
+	statusCh, errCh := s.client.ContainerWait(ctx, containerID, containerTypes.WaitCondition(condition))
+	select {
+	case status := <-statusCh:
+		if status.Error != nil && status.Error.Message != "" {
+			return status.StatusCode, fmt.Errorf("等待容器失败: %s", status.Error.Message)
+		}
+		return status.StatusCode, nil
+	case err := <-errCh:
+		return 0, fmt.Errorf("等待容器失败: %w", err)
+	}
+}
+
 // GetImageID 获取容器使用的镜像ID
 func (s *ContainerService) GetImageID(ctx context.Context, containerID string) (string, error) {
 	c, err := s.client.ContainerInspect(ctx, containerID)
@@ -328,13 +439,11 @@ func (s *ContainerService) Stats(ctx context.Context, containerID string) (*Cont
 	}
 	defer resp.Body.Close()
 
-	// 简化实现：返回基本统计信息
-	// 完整实现需要解析 JSON 响应
-	return &ContainerStats{
-		CPUPercent:  0,
-		MemoryUsage: 0,
-		MemoryLimit: 0,
-	}, nil
+	var raw containerTypes.StatsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("解析容器统计失败: %w", err)
+	}
+	return statsFromResponse(&raw), nil
 }
 
 // ExecCreate 创建执行命令
@@ -371,6 +480,15 @@ func (s *ContainerService) ExecAttach(ctx context.Context, execID string) (types
 	return resp, nil
 }
 
+// ExecInspect 获取执行命令的状态
+func (s *ContainerService) ExecInspect(ctx context.Context, execID string) (containerTypes.ExecInspect, error) {
+	inspect, err := s.client.ContainerExecInspect(ctx, execID)
+	if err != nil {
+		return containerTypes.ExecInspect{}, fmt.Errorf("获取执行命令状态失败: %w", err)
+	}
+	return inspect, nil
+}
+
 // ExecResize 调整执行命令终端大小
 func (s *ContainerService) ExecResize(ctx context.Context, execID string, width, height uint) error {
 	err := s.client.ContainerExecResize(ctx, execID, containerTypes.ResizeOptions{
@@ -0,0 +1,81 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	containerTypes "github.com/docker/docker/api/types/container"
+)
+
+// StreamStats 持续读取容器资源统计并回调，直到容器停止、ctx 取消或回调返回错误
+// Docker 大约每秒推送一次统计
+func (s *ContainerService) StreamStats(ctx context.Context, containerID string, fn func(*ContainerStats) error) error {
+	resp, err := s.client.ContainerStats(ctx, containerID, true)
+	if err != nil {
+		return fmt.Errorf("获取容器统计失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var raw containerTypes.StatsResponse
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("读取容器统计失败: %w", err)
+		}
+		if err := fn(statsFromResponse(&raw)); err != nil {
+			return err
+		}
+	}
+}
+
+// statsFromResponse 按 docker stats 的方式计算资源使用率
+// 内存使用量不含页缓存（cgroup v1 的 cache，cgroup v2 的 inactive_file）
+func statsFromResponse(raw *containerTypes.StatsResponse) *ContainerStats {
+	stats := &ContainerStats{
+		MemoryUsage: raw.MemoryStats.Usage,
+		MemoryLimit: raw.MemoryStats.Limit,
+	}
+
+	cpuDelta := float64(raw.CPUStats.CPUUsage.TotalUsage) - float64(raw.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(raw.CPUStats.SystemUsage) - float64(raw.PreCPUStats.SystemUsage)
+	cpus := float64(raw.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(raw.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		stats.CPUPercent = cpuDelta / systemDelta * cpus * 100
+	}
+
+	if cache, ok := raw.MemoryStats.Stats["inactive_file"]; ok && cache < stats.MemoryUsage {
+		stats.MemoryUsage -= cache
+	} else if cache, ok := raw.MemoryStats.Stats["cache"]; ok && cache < stats.MemoryUsage {
+		stats.MemoryUsage -= cache
+	}
+	if stats.MemoryLimit > 0 {
+		stats.MemoryPercent = float64(stats.MemoryUsage) / float64(stats.MemoryLimit) * 100
+	}
+
+	for _, n := range raw.Networks {
+		stats.NetworkRx += n.RxBytes
+		stats.NetworkTx += n.TxBytes
+	}
+	for _, e := range raw.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(e.Op) {
+		case "read":
+			stats.BlockRead += e.Value
+		case "write":
+			stats.BlockWrite += e.Value
+		}
+	}
+	return stats
+}
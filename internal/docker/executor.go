@@ -2,7 +2,10 @@ package docker
 
 import (
 	"context"
+	"fmt"
 	"io"
+
+	"rubick/internal/model"
 )
 
 // FileInfo 文件信息
@@ -11,6 +14,10 @@ type FileInfo struct {
 	Path  string `json:"path"`
 	IsDir bool   `json:"is_dir"`
 	Size  int64  `json:"size"`
+	// 以下字段仅在浏览容器文件时返回
+	Mode       string `json:"mode,omitempty"`
+	ModTime    int64  `json:"mod_time,omitempty"`
+	LinkTarget string `json:"link_target,omitempty"`
 }
 
 // CommandExecutor 命令执行器接口
@@ -49,3 +56,23 @@ type CommandExecutor interface {
 	// FileExists 检查文件是否存在
 	FileExists(ctx context.Context, filepath string) (bool, error)
 }
+
+// NewHostExecutor 根据主机类型创建对应的命令执行器
+func NewHostExecutor(host *model.Host) (CommandExecutor, error) {
+	switch host.Type {
+	case string(ConnectionTypeLocal):
+		return NewLocalExecutor()
+	case string(ConnectionTypeSSH):
+		return NewSSHExecutor(&ConnectionConfig{
+			Type:          ConnectionTypeSSH,
+			Host:          host.Host,
+			SSHUser:       host.SSHUser,
+			SSHAuthType:   host.SSHAuthType,
+			SSHPrivateKey: host.SSHPrivateKey,
+			SSHPassword:   host.SSHPassword,
+			SSHPort:       host.SSHPort,
+		})
+	default:
+		return nil, fmt.Errorf("不支持的主机类型: %s", host.Type)
+	}
+}
@@ -8,10 +8,12 @@ import (
 	"io"
 	"sort"
 
+	"github.com/distribution/reference"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
 )
 
 // ImageService 镜像服务
@@ -59,6 +61,15 @@ type SearchResult struct {
 	Automated   bool   `json:"automated"`
 }
 
+// ImageUpdateStatus 镜像更新检查结果
+type ImageUpdateStatus struct {
+	Image           string `json:"image"`
+	LocalID         string `json:"local_id,omitempty"`
+	LocalDigest     string `json:"local_digest"`
+	RemoteDigest    string `json:"remote_digest"`
+	UpdateAvailable bool   `json:"update_available"`
+}
+
 // List 列出镜像
 func (s *ImageService) List(ctx context.Context, all bool) ([]ImageInfo, error) {
 	images, err := s.client.ImageList(ctx, image.ListOptions{
@@ -113,18 +124,11 @@ func (s *ImageService) Pull(ctx context.Context, opts PullOptions) (io.ReadClose
 	}
 
 	// 设置认证信息
-	if opts.Username != "" && opts.Password != "" {
-		auth := registry.AuthConfig{
-			Username:      opts.Username,
-			Password:      opts.Password,
-			ServerAddress: opts.Registry,
-		}
-		authBytes, err := json.Marshal(auth)
-		if err != nil {
-			return nil, fmt.Errorf("编码认证信息失败: %w", err)
-		}
-		pullOpts.RegistryAuth = base64.StdEncoding.EncodeToString(authBytes)
+	auth, err := encodeRegistryAuth(opts)
+	if err != nil {
+		return nil, err
 	}
+	pullOpts.RegistryAuth = auth
 
 	reader, err := s.client.ImagePull(ctx, opts.Image, pullOpts)
 	if err != nil {
@@ -134,6 +138,65 @@ func (s *ImageService) Pull(ctx context.Context, opts PullOptions) (io.ReadClose
 	return reader, nil
 }
 
+// PullAndWait 拉取镜像并等待完成
+func (s *ImageService) PullAndWait(ctx context.Context, opts PullOptions) error {
+	reader, err := s.Pull(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// 拉取进度中的 errorDetail 会在这里转换为错误返回
+	if err := jsonmessage.DisplayJSONMessagesStream(reader, io.Discard, 0, false, nil); err != nil {
+		return fmt.Errorf("拉取镜像失败: %w", err)
+	}
+	return nil
+}
+
+// CheckUpdate 比较本地镜像与镜像仓库中的摘要，判断是否有更新
+func (s *ImageService) CheckUpdate(ctx context.Context, opts PullOptions) (*ImageUpdateStatus, error) {
+	named, err := reference.ParseNormalizedNamed(opts.Image)
+	if err != nil {
+		return nil, fmt.Errorf("解析镜像名称失败: %w", err)
+	}
+
+	status := &ImageUpdateStatus{Image: opts.Image}
+
+	// 通过摘要固定的镜像不会有更新
+	if digested, ok := named.(reference.Digested); ok {
+		status.LocalDigest = digested.Digest().String()
+		status.RemoteDigest = status.LocalDigest
+		return status, nil
+	}
+	named = reference.TagNameOnly(named)
+
+	auth, err := encodeRegistryAuth(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	dist, err := s.client.DistributionInspect(ctx, reference.FamiliarString(named), auth)
+	if err != nil {
+		return nil, fmt.Errorf("获取镜像仓库摘要失败: %w", err)
+	}
+	status.RemoteDigest = dist.Descriptor.Digest.String()
+
+	inspect, _, err := s.client.ImageInspectWithRaw(ctx, reference.FamiliarString(named))
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			// 本地不存在该镜像，拉取即视为更新
+			status.UpdateAvailable = true
+			return status, nil
+		}
+		return nil, fmt.Errorf("获取镜像详情失败: %w", err)
+	}
+
+	status.LocalID = inspect.ID
+	status.LocalDigest = findRepoDigest(inspect.RepoDigests, named)
+	status.UpdateAvailable = status.LocalDigest != status.RemoteDigest
+	return status, nil
+}
+
 // Remove 删除镜像
 func (s *ImageService) Remove(ctx context.Context, imageID string, force bool) ([]image.DeleteResponse, error) {
 	resp, err := s.client.ImageRemove(ctx, imageID, image.RemoveOptions{
@@ -202,3 +265,37 @@ func (s *ImageService) Prune(ctx context.Context, all bool) (image.PruneReport,
 
 	return report, nil
 }
+
+// encodeRegistryAuth 编码镜像仓库认证信息
+func encodeRegistryAuth(opts PullOptions) (string, error) {
+	if opts.Username == "" || opts.Password == "" {
+		return "", nil
+	}
+
+	auth := registry.AuthConfig{
+		Username:      opts.Username,
+		Password:      opts.Password,
+		ServerAddress: opts.Registry,
+	}
+	authBytes, err := json.Marshal(auth)
+	if err != nil {
+		return "", fmt.Errorf("编码认证信息失败: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(authBytes), nil
+}
+
+// findRepoDigest 从 RepoDigests 中找到与镜像仓库名称匹配的摘要
+func findRepoDigest(repoDigests []string, named reference.Named) string {
+	for _, rd := range repoDigests {
+		ref, err := reference.ParseNormalizedNamed(rd)
+		if err != nil {
+			continue
+		}
+		digested, ok := ref.(reference.Canonical)
+		if !ok || ref.Name() != named.Name() {
+			continue
+		}
+		return digested.Digest().String()
+	}
+	return ""
+}
@@ -0,0 +1,530 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/distribution/reference"
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+)
+
+// 镜像在目标主机上的获取方式
+const (
+	ImagePresent     = "present"     // 目标主机已存在
+	ImagePulled      = "pulled"      // 目标主机从镜像仓库拉取
+	ImageTransferred = "transferred" // 经 Rubick 从源主机 save/load 传输
+)
+
+// helperMountPath 复制卷数据时辅助容器中的挂载路径
+const helperMountPath = "/rubick-volume"
+
+// MigrateOptions 迁移选项
+type MigrateOptions struct {
+	// Name 目标容器名称，为空时沿用源容器名称（仅容器迁移）
+	Name string `json:"name"`
+	// CopyVolumes 是否复制命名卷中的数据
+	CopyVolumes bool `json:"copy_volumes"`
+	// StopSource 迁移成功后是否停止源容器或源项目
+	// 同时复制卷时会在复制前停止，保证数据一致
+	StopSource bool `json:"stop_source"`
+}
+
+// ImageTransfer 镜像迁移结果
+type ImageTransfer struct {
+	Image  string `json:"image"`
+	Method string `json:"method"`
+}
+
+// VolumeTransfer 卷迁移结果
+type VolumeTransfer struct {
+	Name   string `json:"name"`
+	Copied bool   `json:"copied"`
+}
+
+// MigrateResult 迁移结果
+type MigrateResult struct {
+	ContainerID   string           `json:"container_id,omitempty"`
+	Images        []ImageTransfer  `json:"images"`
+	Volumes       []VolumeTransfer `json:"volumes"`
+	SourceStopped bool             `json:"source_stopped"`
+	Output        string           `json:"output,omitempty"`
+	Warnings      []string         `json:"warnings"`
+}
+
+// MigrateService 在两台主机之间迁移容器和 Compose 项目
+type MigrateService struct {
+	src    *client.Client
+	dst    *client.Client
+	source *ContainerService
+	target *ContainerService
+}
+
+// NewMigrateService 创建迁移服务
+func NewMigrateService(src, dst *client.Client) *MigrateService {
+	return &MigrateService{
+		src:    src,
+		dst:    dst,
+		source: NewContainerService(src),
+		target: NewContainerService(dst),
+	}
+}
+
+// newMigrateResult 创建空的迁移结果
+func newMigrateResult() *MigrateResult {
+	return &MigrateResult{Images: []ImageTransfer{}, Volumes: []VolumeTransfer{}, Warnings: []string{}}
+}
+
+// TransferImage 确保目标主机上存在镜像
+// 优先让目标主机自行拉取，无法拉取时（私有镜像、本地构建的镜像等）经 Rubick 流式 save/load
+func (s *MigrateService) TransferImage(ctx context.Context, image string) (string, error) {
+	if _, _, err := s.dst.ImageInspectWithRaw(ctx, image); err == nil {
+		return ImagePresent, nil
+	} else if !client.IsErrNotFound(err) {
+		return "", fmt.Errorf("获取目标主机镜像失败: %w", err)
+	}
+
+	// 以 ID 引用的镜像无法拉取
+	if _, err := reference.ParseNormalizedNamed(image); err == nil && !strings.HasPrefix(image, "sha256:") {
+		if err := NewImageService(s.dst).PullAndWait(ctx, PullOptions{Image: image}); err == nil {
+			return ImagePulled, nil
+		}
+	}
+
+	reader, err := NewImageService(s.src).Save(ctx, []string{image})
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	if _, err := NewImageService(s.dst).Load(ctx, reader); err != nil {
+		return "", err
+	}
+	return ImageTransferred, nil
+}
+
+// CopyVolume 在目标主机上创建同名卷，并通过辅助容器以 tar 流复制数据
+// 目标主机已存在同名卷时不覆盖，返回 false
+// helperImage 为两台主机上都存在的镜像，辅助容器只创建不启动
+func (s *MigrateService) CopyVolume(ctx context.Context, name, helperImage string) (copied bool, err error) {
+	vol, err := s.src.VolumeInspect(ctx, name)
+	if err != nil {
+		return false, fmt.Errorf("获取卷 %s 失败: %w", name, err)
+	}
+
+	if _, err := s.dst.VolumeInspect(ctx, name); err == nil {
+		return false, nil
+	} else if !client.IsErrNotFound(err) {
+		return false, fmt.Errorf("获取目标主机卷 %s 失败: %w", name, err)
+	}
+
+	if _, err := s.dst.VolumeCreate(ctx, volume.CreateOptions{
+		Name:       name,
+		Driver:     vol.Driver,
+		DriverOpts: vol.Options,
+		Labels:     vol.Labels,
+	}); err != nil {
+		return false, fmt.Errorf("创建卷 %s 失败: %w", name, err)
+	}
+	// 复制失败时删除目标卷，否则重试时会把空卷或不完整的卷当作已存在而跳过
+	// 先注册的 defer 后执行，此时辅助容器已删除，卷不再被占用
+	defer func() {
+		if err != nil {
+			removeVolume(s.dst, name)
+		}
+	}()
+
+	srcHelper, err := createVolumeHelper(ctx, s.src, name, helperImage, true)
+	if err != nil {
+		return false, err
+	}
+	defer removeVolumeHelper(s.src, srcHelper)
+
+	dstHelper, err := createVolumeHelper(ctx, s.dst, name, helperImage, false)
+	if err != nil {
+		return false, err
+	}
+	defer removeVolumeHelper(s.dst, dstHelper)
+
+	// 归档以挂载目录名为根，解压到目标辅助容器的根目录即写入卷中
+	reader, _, err := s.source.CopyFrom(ctx, srcHelper, helperMountPath)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+
+	if err := s.target.CopyTo(ctx, dstHelper, "/", reader); err != nil {
+		return false, fmt.Errorf("复制卷 %s 失败: %w", name, err)
+	}
+	return true, nil
+}
+
+// removeVolume 删除复制失败的卷
+func removeVolume(cli *client.Client, name string) {
+	cli.VolumeRemove(context.Background(), name, true)
+}
+
+// createVolumeHelper 创建挂载指定卷的辅助容器，用于读写卷中的文件
+func createVolumeHelper(ctx context.Context, cli *client.Client, volumeName, image string, readOnly bool) (string, error) {
+	resp, err := cli.ContainerCreate(ctx, &containerTypes.Config{
+		Image:      image,
+		Entrypoint: []string{"true"},
+		Cmd:        []string{},
+		Labels:     map[string]string{"rubick.helper": "volume"},
+	}, &containerTypes.HostConfig{
+		NetworkMode: network.NetworkNone,
+		Mounts: []mount.Mount{{
+			Type:     mount.TypeVolume,
+			Source:   volumeName,
+			Target:   helperMountPath,
+			ReadOnly: readOnly,
+		}},
+	}, nil, nil, "")
+	if err != nil {
+		return "", fmt.Errorf("创建辅助容器失败: %w", err)
+	}
+	return resp.ID, nil
+}
+
+// removeVolumeHelper 删除辅助容器，请求已取消时仍需清理
+func removeVolumeHelper(cli *client.Client, id string) {
+	cli.ContainerRemove(context.Background(), id, containerTypes.RemoveOptions{Force: true})
+}
+
+// MigrateContainer 以相同配置在目标主机上重建容器
+func (s *MigrateService) MigrateContainer(ctx context.Context, containerID string, opts MigrateOptions) (*MigrateResult, error) {
+	old, err := s.src.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("获取容器详情失败: %w", err)
+	}
+
+	config, hostConfig, endpoints, err := s.source.cloneContainerConfig(ctx, old, RecreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = strings.TrimPrefix(old.Name, "/")
+	}
+	if _, err := s.dst.ContainerInspect(ctx, name); err == nil {
+		return nil, fmt.Errorf("目标主机上已存在容器 %s", name)
+	}
+
+	result := newMigrateResult()
+
+	method, err := s.TransferImage(ctx, config.Image)
+	if err != nil {
+		return nil, err
+	}
+	result.Images = append(result.Images, ImageTransfer{Image: config.Image, Method: method})
+
+	if err := s.ensureNetworks(ctx, endpoints); err != nil {
+		return nil, err
+	}
+
+	// 复制卷前停止源容器，保证数据一致
+	wasRunning := old.State.Running
+	if opts.StopSource && opts.CopyVolumes && wasRunning {
+		if err := s.source.Stop(ctx, old.ID, old.Config.StopTimeout); err != nil {
+			return nil, err
+		}
+		result.SourceStopped = true
+	}
+	// 迁移失败时恢复源容器
+	restoreSource := func() {
+		if result.SourceStopped {
+			s.src.ContainerStart(context.Background(), old.ID, containerTypes.StartOptions{})
+		}
+	}
+
+	for _, m := range old.Mounts {
+		switch m.Type {
+		case mount.TypeBind:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("绑定挂载 %s 未迁移，请确认目标主机上存在该路径", m.Source))
+		case mount.TypeVolume:
+			if !opts.CopyVolumes || m.Name == "" {
+				continue
+			}
+			if m.Driver != "" && m.Driver != "local" {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("卷 %s 使用 %s 驱动，未复制数据", m.Name, m.Driver))
+				continue
+			}
+			copied, err := s.CopyVolume(ctx, m.Name, config.Image)
+			if err != nil {
+				restoreSource()
+				return nil, err
+			}
+			if !copied {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("目标主机上已存在卷 %s，未复制数据", m.Name))
+			}
+			result.Volumes = append(result.Volumes, VolumeTransfer{Name: m.Name, Copied: copied})
+		}
+	}
+
+	id, err := s.createTarget(ctx, name, config, hostConfig, endpoints, wasRunning)
+	if err != nil {
+		restoreSource()
+		return nil, err
+	}
+	result.ContainerID = id
+
+	if opts.StopSource && !result.SourceStopped && wasRunning {
+		if err := s.source.Stop(ctx, old.ID, old.Config.StopTimeout); err != nil {
+			result.Warnings = append(result.Warnings, "停止源容器失败: "+err.Error())
+		} else {
+			result.SourceStopped = true
+		}
+	}
+
+	return result, nil
+}
+
+// ensureNetworks 在目标主机上创建容器使用的自定义网络
+func (s *MigrateService) ensureNetworks(ctx context.Context, endpoints map[string]*network.EndpointSettings) error {
+	for name := range endpoints {
+		if !containerTypes.NetworkMode(name).IsUserDefined() {
+			continue
+		}
+		if _, err := s.dst.NetworkInspect(ctx, name, network.InspectOptions{}); err == nil {
+			continue
+		} else if !client.IsErrNotFound(err) {
+			return fmt.Errorf("获取目标主机网络 %s 失败: %w", name, err)
+		}
+
+		src, err := s.src.NetworkInspect(ctx, name, network.InspectOptions{})
+		if err != nil {
+			return fmt.Errorf("获取网络 %s 失败: %w", name, err)
+		}
+		ipam := src.IPAM
+		_, err = s.dst.NetworkCreate(ctx, name, network.CreateOptions{
+			Driver:     src.Driver,
+			Internal:   src.Internal,
+			Attachable: src.Attachable,
+			EnableIPv6: &src.EnableIPv6,
+			IPAM:       &ipam,
+			Options:    src.Options,
+			Labels:     src.Labels,
+		})
+		if err != nil {
+			return fmt.Errorf("创建网络 %s 失败: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// createTarget 在目标主机上创建容器并连接网络，失败时删除已创建的容器
+func (s *MigrateService) createTarget(ctx context.Context, name string, config *containerTypes.Config, hostConfig *containerTypes.HostConfig, endpoints map[string]*network.EndpointSettings, start bool) (string, error) {
+	primary := string(hostConfig.NetworkMode)
+	var networkingConfig *network.NetworkingConfig
+	if ep, ok := endpoints[primary]; ok {
+		networkingConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{primary: ep},
+		}
+	}
+
+	resp, err := s.dst.ContainerCreate(ctx, config, hostConfig, networkingConfig, nil, name)
+	if err != nil {
+		return "", fmt.Errorf("在目标主机上创建容器失败: %w", err)
+	}
+	cleanup := func() {
+		s.dst.ContainerRemove(context.Background(), resp.ID, containerTypes.RemoveOptions{Force: true})
+	}
+
+	for netName, ep := range endpoints {
+		if netName == primary {
+			continue
+		}
+		if err := s.dst.NetworkConnect(ctx, netName, resp.ID, ep); err != nil {
+			cleanup()
+			return "", fmt.Errorf("连接网络 %s 失败: %w", netName, err)
+		}
+	}
+
+	if start {
+		if err := s.dst.ContainerStart(ctx, resp.ID, containerTypes.StartOptions{}); err != nil {
+			cleanup()
+			return "", fmt.Errorf("在目标主机上启动容器失败: %w", err)
+		}
+	}
+	return resp.ID, nil
+}
+
+// ComposeMigration Compose 项目迁移的源和目标
+type ComposeMigration struct {
+	Content        string
+	SourceExecutor CommandExecutor
+	TargetExecutor CommandExecutor
+	SourceOptions  ComposeOptions
+	TargetOptions  ComposeOptions
+}
+
+// MigrateCompose 在目标主机上启动相同的 Compose 项目
+// 目录模式的项目会复制 compose 文件和环境变量文件，目录中的其他文件不会复制
+func (s *MigrateService) MigrateCompose(ctx context.Context, m ComposeMigration, opts MigrateOptions) (*MigrateResult, error) {
+	sourceCompose := NewComposeService(m.SourceExecutor)
+	targetCompose := NewComposeService(m.TargetExecutor)
+
+	config, err := sourceCompose.Config(ctx, m.Content, m.SourceOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newMigrateResult()
+
+	if m.SourceOptions.UseWorkDir {
+		if err := copyComposeFiles(ctx, m); err != nil {
+			return nil, err
+		}
+		result.Warnings = append(result.Warnings, "仅复制了 compose 文件和环境变量文件，目录中的其他文件需要手动同步")
+	}
+
+	// 多个服务可能共用同一镜像，只传输一次
+	images := make(map[string]bool)
+	names := make([]string, 0, len(config.Services))
+	for name := range config.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		svc := config.Services[name]
+		image := svc.Image
+		if image == "" {
+			// 未指定镜像名称的构建服务，compose 以 <项目>-<服务> 命名
+			image = config.Name + "-" + name
+		}
+		if images[image] {
+			continue
+		}
+		images[image] = true
+
+		method, err := s.TransferImage(ctx, image)
+		if err != nil {
+			return nil, fmt.Errorf("服务 %s: %w", name, err)
+		}
+		result.Images = append(result.Images, ImageTransfer{Image: image, Method: method})
+	}
+
+	// 复制卷前停止源项目，保证数据一致
+	if opts.StopSource && opts.CopyVolumes {
+		if _, err := sourceCompose.Stop(ctx, m.Content, m.SourceOptions, 0, nil); err != nil {
+			return nil, fmt.Errorf("停止源项目失败: %w", err)
+		}
+		result.SourceStopped = true
+	}
+	restoreSource := func() {
+		if result.SourceStopped {
+			sourceCompose.Start(context.Background(), m.Content, m.SourceOptions, nil)
+		}
+	}
+
+	if opts.CopyVolumes {
+		if err := s.copyProjectVolumes(ctx, config.Name, result); err != nil {
+			restoreSource()
+			return nil, err
+		}
+	}
+
+	stream, err := targetCompose.Up(ctx, m.Content, UpOptions{
+		ComposeOptions: m.TargetOptions,
+		Detach:         true,
+	})
+	if err != nil {
+		restoreSource()
+		return nil, fmt.Errorf("在目标主机上启动项目失败: %w", err)
+	}
+	defer stream.Close()
+
+	output, _ := io.ReadAll(stream)
+	result.Output = string(output)
+
+	if opts.StopSource && !result.SourceStopped {
+		if _, err := sourceCompose.Stop(ctx, m.Content, m.SourceOptions, 0, nil); err != nil {
+			result.Warnings = append(result.Warnings, "停止源项目失败: "+err.Error())
+		} else {
+			result.SourceStopped = true
+		}
+	}
+
+	return result, nil
+}
+
+// copyProjectVolumes 复制 Compose 项目的全部命名卷
+func (s *MigrateService) copyProjectVolumes(ctx context.Context, project string, result *MigrateResult) error {
+	resp, err := s.src.VolumeList(ctx, volume.ListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", "com.docker.compose.project="+project)),
+	})
+	if err != nil {
+		return fmt.Errorf("获取项目卷列表失败: %w", err)
+	}
+	if len(resp.Volumes) == 0 {
+		return nil
+	}
+	if len(result.Images) == 0 {
+		result.Warnings = append(result.Warnings, "项目中没有可用作辅助容器的镜像，未复制卷数据")
+		return nil
+	}
+	helperImage := result.Images[0].Image
+
+	sort.Slice(resp.Volumes, func(i, j int) bool { return resp.Volumes[i].Name < resp.Volumes[j].Name })
+	for _, vol := range resp.Volumes {
+		if vol.Driver != "local" {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("卷 %s 使用 %s 驱动，未复制数据", vol.Name, vol.Driver))
+			continue
+		}
+		copied, err := s.CopyVolume(ctx, vol.Name, helperImage)
+		if err != nil {
+			return err
+		}
+		if !copied {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("目标主机上已存在卷 %s，未复制数据", vol.Name))
+		}
+		result.Volumes = append(result.Volumes, VolumeTransfer{Name: vol.Name, Copied: copied})
+	}
+	return nil
+}
+
+// copyComposeFiles 将目录模式项目的 compose 文件和环境变量文件复制到目标主机
+// 目标主机上已存在同名文件时返回错误，避免覆盖
+func copyComposeFiles(ctx context.Context, m ComposeMigration) error {
+	files := []string{path.Join(m.SourceOptions.WorkDir, m.SourceOptions.ComposeFile)}
+	if m.SourceOptions.EnvFile != "" {
+		envFile := m.SourceOptions.EnvFile
+		if !path.IsAbs(envFile) {
+			envFile = path.Join(m.SourceOptions.WorkDir, envFile)
+		}
+		files = append(files, envFile)
+	}
+
+	for _, file := range files {
+		exists, err := m.TargetExecutor.FileExists(ctx, file)
+		if err != nil {
+			return fmt.Errorf("检查目标主机文件失败: %w", err)
+		}
+		if exists {
+			return fmt.Errorf("目标主机上已存在文件 %s", file)
+		}
+	}
+
+	for _, file := range files {
+		content, err := m.SourceExecutor.ReadFile(ctx, file)
+		if err != nil {
+			return fmt.Errorf("读取 %s 失败: %w", file, err)
+		}
+		if err := m.TargetExecutor.MkdirAll(ctx, path.Dir(file)); err != nil {
+			return fmt.Errorf("创建目录 %s 失败: %w", path.Dir(file), err)
+		}
+		if err := m.TargetExecutor.WriteFileToPath(ctx, content, file); err != nil {
+			return fmt.Errorf("写入 %s 失败: %w", file, err)
+		}
+	}
+	return nil
+}
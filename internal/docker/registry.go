@@ -0,0 +1,171 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/distribution/reference"
+)
+
+// registryHTTPClient 访问镜像仓库 API 使用的 HTTP 客户端
+var registryHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// challengeParamRegexp 解析 WWW-Authenticate 头中的参数
+var challengeParamRegexp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// linkNextRegexp 解析分页 Link 头
+var linkNextRegexp = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// ListTags 通过镜像仓库 HTTP API (v2) 列出镜像的所有标签
+// Docker Engine API 没有提供列出远程标签的接口，因此直接访问镜像仓库
+func ListTags(ctx context.Context, opts PullOptions) ([]string, error) {
+	named, err := reference.ParseNormalizedNamed(opts.Image)
+	if err != nil {
+		return nil, fmt.Errorf("解析镜像名称失败: %w", err)
+	}
+
+	domain := reference.Domain(named)
+	if domain == "docker.io" {
+		domain = "registry-1.docker.io"
+	}
+	repoPath := reference.Path(named)
+
+	next := fmt.Sprintf("https://%s/v2/%s/tags/list?n=1000", domain, repoPath)
+	authHeader := ""
+	var tags []string
+
+	for next != "" {
+		resp, err := registryGet(ctx, next, authHeader)
+		if err != nil {
+			return nil, err
+		}
+
+		// 需要认证时根据 challenge 获取 token 后重试
+		if resp.StatusCode == http.StatusUnauthorized && authHeader == "" {
+			challenge := resp.Header.Get("WWW-Authenticate")
+			resp.Body.Close()
+
+			authHeader, err = registryAuthorize(ctx, challenge, repoPath, opts)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("获取镜像标签失败: 镜像仓库返回 %s", resp.Status)
+		}
+
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("解析镜像标签失败: %w", err)
+		}
+		tags = append(tags, page.Tags...)
+
+		next = ""
+		if m := linkNextRegexp.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+			nextURL, err := resp.Request.URL.Parse(m[1])
+			if err == nil {
+				next = nextURL.String()
+			}
+		}
+	}
+
+	return tags, nil
+}
+
+// registryGet 发送带认证头的 GET 请求
+func registryGet(ctx context.Context, rawURL, authHeader string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建镜像仓库请求失败: %w", err)
+	}
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+
+	resp, err := registryHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("访问镜像仓库失败: %w", err)
+	}
+	return resp, nil
+}
+
+// registryAuthorize 根据 WWW-Authenticate 返回对应的 Authorization 头
+func registryAuthorize(ctx context.Context, challenge, repoPath string, opts PullOptions) (string, error) {
+	scheme, _, _ := strings.Cut(challenge, " ")
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if opts.Username == "" {
+			return "", fmt.Errorf("镜像仓库需要认证")
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(opts.Username, opts.Password)
+		return req.Header.Get("Authorization"), nil
+
+	case "bearer":
+		params := make(map[string]string)
+		for _, m := range challengeParamRegexp.FindAllStringSubmatch(challenge, -1) {
+			params[m[1]] = m[2]
+		}
+		if params["realm"] == "" {
+			return "", fmt.Errorf("镜像仓库认证信息缺少 realm")
+		}
+
+		tokenURL, err := url.Parse(params["realm"])
+		if err != nil {
+			return "", fmt.Errorf("解析认证地址失败: %w", err)
+		}
+		query := tokenURL.Query()
+		if params["service"] != "" {
+			query.Set("service", params["service"])
+		}
+		query.Set("scope", fmt.Sprintf("repository:%s:pull", repoPath))
+		tokenURL.RawQuery = query.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+		if err != nil {
+			return "", fmt.Errorf("创建认证请求失败: %w", err)
+		}
+		if opts.Username != "" {
+			req.SetBasicAuth(opts.Username, opts.Password)
+		}
+
+		resp, err := registryHTTPClient.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("获取镜像仓库 token 失败: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("获取镜像仓库 token 失败: %s", resp.Status)
+		}
+
+		var token struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+			return "", fmt.Errorf("解析镜像仓库 token 失败: %w", err)
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		return "Bearer " + token.Token, nil
+
+	default:
+		return "", fmt.Errorf("不支持的镜像仓库认证方式: %s", scheme)
+	}
+}
@@ -69,7 +69,7 @@ func (c *SSHConnection) Connect(ctx context.Context) (*client.Client, error) {
 
 	// 连接 SSH 服务器
 	sshAddr := fmt.Sprintf("%s:%d", c.config.Host, c.config.SSHPort)
-	sshClient, err := ssh.Dial("tcp", sshAddr, sshConfig)
+	sshClient, err := dialSSH(ctx, sshAddr, sshConfig)
 	if err != nil {
 		return nil, fmt.Errorf("SSH 连接失败: %w", err)
 	}
@@ -82,7 +82,7 @@ func (c *SSHConnection) Connect(ctx context.Context) (*client.Client, error) {
 	}
 
 	// 获取 Docker API 版本
-	apiVersion, err := c.negotiateAPIVersion(tempTransport)
+	apiVersion, err := c.negotiateAPIVersion(ctx, tempTransport)
 	if err != nil {
 		sshClient.Close()
 		return nil, fmt.Errorf("协商 API 版本失败: %w", err)
@@ -109,8 +109,41 @@ func (c *SSHConnection) Connect(ctx context.Context) (*client.Client, error) {
 	return c.client, nil
 }
 
+// dialSSH 建立 SSH 连接，TCP 连接和握手都受 ctx 控制
+// config.Timeout 仍作为 ctx 没有截止时间时的上限
+func dialSSH(ctx context.Context, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	dialer := net.Dialer{Timeout: config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// 握手期间 ctx 取消时关闭连接，使握手立即返回
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else if config.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(config.Timeout))
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if !stop() {
+		if err == nil {
+			sshConn.Close()
+		}
+		conn.Close()
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
 // negotiateAPIVersion 获取 Docker 服务器的 API 版本
-func (c *SSHConnection) negotiateAPIVersion(transport *sshTransport) (string, error) {
+func (c *SSHConnection) negotiateAPIVersion(ctx context.Context, transport *sshTransport) (string, error) {
 	// 创建临时 HTTP client
 	client := &http.Client{
 		Transport: transport,
@@ -118,7 +151,7 @@ func (c *SSHConnection) negotiateAPIVersion(transport *sshTransport) (string, er
 	}
 
 	// 发送 /version 请求
-	req, err := http.NewRequest("GET", "http://localhost/version", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://localhost/version", nil)
 	if err != nil {
 		return "", err
 	}
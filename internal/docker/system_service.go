@@ -0,0 +1,596 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"rubick/internal/model"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/build"
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+// 可清理的资源类型
+const (
+	PruneContainers = "containers"
+	PruneImages     = "images"
+	PruneVolumes    = "volumes"
+	PruneNetworks   = "networks"
+	PruneBuildCache = "build_cache"
+)
+
+// pruneTypes 资源类型的清理顺序：先删除容器，才能释放其引用的镜像、卷和网络
+var pruneTypes = []string{PruneContainers, PruneImages, PruneVolumes, PruneNetworks, PruneBuildCache}
+
+// anonymousVolumeLabel Docker 为匿名卷添加的标签
+const anonymousVolumeLabel = "com.docker.volume.anonymous"
+
+// SystemService 系统级信息与清理服务
+type SystemService struct {
+	client *client.Client
+}
+
+// NewSystemService 创建系统服务
+func NewSystemService(cli *client.Client) *SystemService {
+	return &SystemService{client: cli}
+}
+
+// DiskUsageSummary 各类资源的磁盘占用
+type DiskUsageSummary struct {
+	Images     DiskUsageCategory `json:"images"`
+	Containers DiskUsageCategory `json:"containers"`
+	Volumes    DiskUsageCategory `json:"volumes"`
+	BuildCache DiskUsageCategory `json:"build_cache"`
+}
+
+// DiskUsageCategory 一类资源的磁盘占用
+type DiskUsageCategory struct {
+	Total       int             `json:"total"`
+	Active      int             `json:"active"`
+	Size        int64           `json:"size"`
+	Reclaimable int64           `json:"reclaimable"`
+	Items       []DiskUsageItem `json:"items"`
+}
+
+// DiskUsageItem 单个资源的磁盘占用
+type DiskUsageItem struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Size    int64  `json:"size"`
+	Active  bool   `json:"active"`
+	Created int64  `json:"created,omitempty"`
+}
+
+// PruneOptions 清理选项
+type PruneOptions struct {
+	// Types 要清理的资源类型，为空时清理容器、悬空镜像和网络（与 docker system prune 一致）
+	Types []string `json:"types"`
+	// All 镜像：包含所有未被容器使用的镜像；卷：包含命名卷；构建缓存：包含全部未使用的缓存
+	All bool `json:"all"`
+	// Until 只清理在此之前创建的资源，可以是时长（如 24h）或 RFC3339 时间，卷不支持
+	Until string `json:"until"`
+	// Labels 标签过滤：key、key=value，以 ! 开头表示不包含该标签，多个条件同时满足
+	Labels []string `json:"labels"`
+	// DryRun 只列出将被清理的资源，不实际删除
+	DryRun bool `json:"dry_run"`
+}
+
+// PruneItem 被清理的资源
+type PruneItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Size int64  `json:"size,omitempty"`
+}
+
+// PruneResult 一类资源的清理结果
+type PruneResult struct {
+	Type           string      `json:"type"`
+	Items          []PruneItem `json:"items"`
+	SpaceReclaimed int64       `json:"space_reclaimed"`
+	Error          string      `json:"error,omitempty"`
+}
+
+// PruneReport 清理结果
+// 预览模式下的空间为估算值：共享的镜像层只在最后一个引用它的镜像被删除时才会释放
+type PruneReport struct {
+	DryRun         bool          `json:"dry_run"`
+	Results        []PruneResult `json:"results"`
+	SpaceReclaimed int64         `json:"space_reclaimed"`
+}
+
+// labelFilter 标签过滤条件
+type labelFilter struct {
+	key    string
+	value  string
+	exact  bool // 是否指定了值
+	negate bool
+}
+
+// pruneFilter 解析后的清理过滤条件
+type pruneFilter struct {
+	until  time.Time
+	labels []labelFilter
+}
+
+// Validate 校验清理选项
+func (o *PruneOptions) Validate() error {
+	for _, t := range o.Types {
+		if !slices.Contains(pruneTypes, t) {
+			return fmt.Errorf("types: 无效的资源类型: %s", t)
+		}
+	}
+	if o.Until != "" {
+		if _, err := parseUntil(o.Until, time.Now()); err != nil {
+			return fmt.Errorf("until: %w", err)
+		}
+		if slices.Contains(o.Types, PruneVolumes) {
+			return fmt.Errorf("until: 卷不支持按时间过滤")
+		}
+	}
+	for _, l := range o.Labels {
+		if strings.TrimLeft(l, "!") == "" || strings.HasPrefix(l, "=") || strings.HasPrefix(l, "!=") {
+			return fmt.Errorf("labels: 无效的标签过滤条件: %q", l)
+		}
+	}
+	if len(o.Labels) > 0 && slices.Contains(o.Types, PruneBuildCache) {
+		return fmt.Errorf("labels: 构建缓存不支持按标签过滤")
+	}
+	return nil
+}
+
+// DiskUsage 获取镜像、容器、卷和构建缓存的磁盘占用及可回收空间
+// 可回收空间的计算方式与 docker system df 一致
+func (s *SystemService) DiskUsage(ctx context.Context) (*DiskUsageSummary, error) {
+	du, err := s.client.DiskUsage(ctx, types.DiskUsageOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("获取磁盘占用失败: %w", err)
+	}
+
+	summary := &DiskUsageSummary{
+		Images:     DiskUsageCategory{Items: []DiskUsageItem{}},
+		Containers: DiskUsageCategory{Items: []DiskUsageItem{}},
+		Volumes:    DiskUsageCategory{Items: []DiskUsageItem{}},
+		BuildCache: DiskUsageCategory{Items: []DiskUsageItem{}},
+	}
+
+	// 镜像的总大小以镜像层计算，共享层只计一次
+	images := &summary.Images
+	images.Size = du.LayersSize
+	var imagesUsed int64
+	for _, img := range du.Images {
+		active := img.Containers > 0
+		if active {
+			images.Active++
+			if img.SharedSize >= 0 {
+				imagesUsed += img.Size - img.SharedSize
+			}
+		}
+		images.Items = append(images.Items, DiskUsageItem{
+			ID:      img.ID,
+			Name:    imageName(img.RepoTags),
+			Size:    img.Size,
+			Active:  active,
+			Created: img.Created,
+		})
+	}
+	images.Total = len(du.Images)
+	images.Reclaimable = max(images.Size-imagesUsed, 0)
+
+	containers := &summary.Containers
+	for _, c := range du.Containers {
+		active := isActiveState(c.State)
+		containers.Size += c.SizeRw
+		if active {
+			containers.Active++
+		} else {
+			containers.Reclaimable += c.SizeRw
+		}
+		containers.Items = append(containers.Items, DiskUsageItem{
+			ID:      c.ID,
+			Name:    getContainerName(c.Names),
+			Size:    c.SizeRw,
+			Active:  active,
+			Created: c.Created,
+		})
+	}
+	containers.Total = len(du.Containers)
+
+	volumes := &summary.Volumes
+	for _, v := range du.Volumes {
+		var size, refs int64 = -1, -1
+		if v.UsageData != nil {
+			size, refs = v.UsageData.Size, v.UsageData.RefCount
+		}
+		active := refs > 0
+		if size > 0 {
+			volumes.Size += size
+			if !active {
+				volumes.Reclaimable += size
+			}
+		}
+		if active {
+			volumes.Active++
+		}
+		volumes.Items = append(volumes.Items, DiskUsageItem{
+			ID:     v.Name,
+			Name:   v.Name,
+			Size:   size,
+			Active: active,
+		})
+	}
+	volumes.Total = len(du.Volumes)
+
+	cache := &summary.BuildCache
+	for _, bc := range du.BuildCache {
+		if bc.InUse {
+			cache.Active++
+		}
+		if !bc.Shared {
+			cache.Size += bc.Size
+			if !bc.InUse {
+				cache.Reclaimable += bc.Size
+			}
+		}
+		cache.Items = append(cache.Items, DiskUsageItem{
+			ID:      bc.ID,
+			Name:    bc.Description,
+			Size:    bc.Size,
+			Active:  bc.InUse,
+			Created: bc.CreatedAt.Unix(),
+		})
+	}
+	cache.Total = len(du.BuildCache)
+
+	return summary, nil
+}
+
+// Info 获取 Docker 守护进程的版本和系统信息
+func (s *SystemService) Info(ctx context.Context) (*model.HostInfo, error) {
+	info, err := s.client.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取 Docker 信息失败: %w", err)
+	}
+	version, err := s.client.ServerVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取 Docker 版本失败: %w", err)
+	}
+
+	runtimes := make([]string, 0, len(info.Runtimes))
+	for name := range info.Runtimes {
+		runtimes = append(runtimes, name)
+	}
+	sort.Strings(runtimes)
+
+	result := &model.HostInfo{
+		Name:               info.Name,
+		EngineVersion:      version.Version,
+		APIVersion:         version.APIVersion,
+		MinAPIVersion:      version.MinAPIVersion,
+		GoVersion:          version.GoVersion,
+		OperatingSystem:    info.OperatingSystem,
+		OSType:             info.OSType,
+		Architecture:       info.Architecture,
+		KernelVersion:      info.KernelVersion,
+		CPUs:               info.NCPU,
+		Memory:             info.MemTotal,
+		StorageDriver:      info.Driver,
+		LoggingDriver:      info.LoggingDriver,
+		CgroupDriver:       info.CgroupDriver,
+		CgroupVersion:      info.CgroupVersion,
+		DockerRootDir:      info.DockerRootDir,
+		Runtimes:           runtimes,
+		DefaultRuntime:     info.DefaultRuntime,
+		SwarmState:         string(info.Swarm.LocalNodeState),
+		SwarmNodeID:        info.Swarm.NodeID,
+		RegistryMirrors:    []string{},
+		InsecureRegistries: []string{},
+		Containers:         info.Containers,
+		ContainersRunning:  info.ContainersRunning,
+		ContainersPaused:   info.ContainersPaused,
+		ContainersStopped:  info.ContainersStopped,
+		Images:             info.Images,
+		Warnings:           info.Warnings,
+		CollectedAt:        time.Now(),
+	}
+	if info.RegistryConfig != nil {
+		result.RegistryMirrors = append(result.RegistryMirrors, info.RegistryConfig.Mirrors...)
+		for _, cidr := range info.RegistryConfig.InsecureRegistryCIDRs {
+			result.InsecureRegistries = append(result.InsecureRegistries, cidr.String())
+		}
+		for name, index := range info.RegistryConfig.IndexConfigs {
+			if index != nil && !index.Secure {
+				result.InsecureRegistries = append(result.InsecureRegistries, name)
+			}
+		}
+		sort.Strings(result.InsecureRegistries)
+	}
+	if result.Warnings == nil {
+		result.Warnings = []string{}
+	}
+	return result, nil
+}
+
+// Prune 清理未使用的资源，单类资源失败不影响其他类型
+func (s *SystemService) Prune(ctx context.Context, opts PruneOptions) (*PruneReport, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	selected := opts.Types
+	if len(selected) == 0 {
+		selected = []string{PruneContainers, PruneImages, PruneNetworks}
+	}
+
+	now := time.Now()
+	var pf pruneFilter
+	if opts.Until != "" {
+		pf.until, _ = parseUntil(opts.Until, now)
+	}
+	pf.labels = parseLabelFilters(opts.Labels)
+
+	var du *types.DiskUsage
+	if opts.DryRun {
+		usage, err := s.client.DiskUsage(ctx, types.DiskUsageOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("获取磁盘占用失败: %w", err)
+		}
+		du = &usage
+	}
+
+	report := &PruneReport{DryRun: opts.DryRun, Results: []PruneResult{}}
+	for _, t := range pruneTypes {
+		if !slices.Contains(selected, t) {
+			continue
+		}
+
+		result := PruneResult{Type: t, Items: []PruneItem{}}
+		var err error
+		if opts.DryRun {
+			err = s.previewPrune(ctx, du, t, opts.All, pf, &result)
+		} else {
+			err = s.prune(ctx, t, opts, &result)
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		report.SpaceReclaimed += result.SpaceReclaimed
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// prune 调用 Docker 的清理接口
+func (s *SystemService) prune(ctx context.Context, pruneType string, opts PruneOptions, result *PruneResult) error {
+	args := filters.NewArgs()
+	if opts.Until != "" && pruneType != PruneVolumes {
+		args.Add("until", opts.Until)
+	}
+	for _, l := range opts.Labels {
+		if negated, ok := strings.CutPrefix(l, "!"); ok {
+			args.Add("label!", negated)
+		} else {
+			args.Add("label", l)
+		}
+	}
+
+	switch pruneType {
+	case PruneContainers:
+		report, err := s.client.ContainersPrune(ctx, args)
+		if err != nil {
+			return fmt.Errorf("清理容器失败: %w", err)
+		}
+		result.Items = pruneItems(report.ContainersDeleted)
+		result.SpaceReclaimed = int64(report.SpaceReclaimed)
+	case PruneImages:
+		args.Add("dangling", fmt.Sprintf("%t", !opts.All))
+		report, err := s.client.ImagesPrune(ctx, args)
+		if err != nil {
+			return fmt.Errorf("清理镜像失败: %w", err)
+		}
+		for _, d := range report.ImagesDeleted {
+			if d.Deleted != "" {
+				result.Items = append(result.Items, PruneItem{ID: d.Deleted})
+			} else if d.Untagged != "" {
+				result.Items = append(result.Items, PruneItem{ID: d.Untagged, Name: d.Untagged})
+			}
+		}
+		result.SpaceReclaimed = int64(report.SpaceReclaimed)
+	case PruneVolumes:
+		if opts.All {
+			args.Add("all", "true")
+		}
+		report, err := s.client.VolumesPrune(ctx, args)
+		if err != nil {
+			return fmt.Errorf("清理卷失败: %w", err)
+		}
+		result.Items = pruneItems(report.VolumesDeleted)
+		result.SpaceReclaimed = int64(report.SpaceReclaimed)
+	case PruneNetworks:
+		report, err := s.client.NetworksPrune(ctx, args)
+		if err != nil {
+			return fmt.Errorf("清理网络失败: %w", err)
+		}
+		result.Items = pruneItems(report.NetworksDeleted)
+	case PruneBuildCache:
+		report, err := s.client.BuildCachePrune(ctx, build.CachePruneOptions{All: opts.All, Filters: args})
+		if err != nil {
+			return fmt.Errorf("清理构建缓存失败: %w", err)
+		}
+		result.Items = pruneItems(report.CachesDeleted)
+		result.SpaceReclaimed = int64(report.SpaceReclaimed)
+	}
+	return nil
+}
+
+// previewPrune 根据磁盘占用信息列出将被清理的资源
+func (s *SystemService) previewPrune(ctx context.Context, du *types.DiskUsage, pruneType string, all bool, pf pruneFilter, result *PruneResult) error {
+	add := func(item PruneItem) {
+		result.Items = append(result.Items, item)
+		result.SpaceReclaimed += max(item.Size, 0)
+	}
+
+	switch pruneType {
+	case PruneContainers:
+		for _, c := range du.Containers {
+			if isActiveState(c.State) || !pf.match(time.Unix(c.Created, 0), c.Labels) {
+				continue
+			}
+			add(PruneItem{ID: c.ID, Name: getContainerName(c.Names), Size: c.SizeRw})
+		}
+	case PruneImages:
+		// 预览时尚未删除的容器仍会引用镜像，与先清理容器再清理镜像的实际结果可能不同
+		for _, img := range du.Images {
+			dangling := imageName(img.RepoTags) == ""
+			if img.Containers > 0 || (!all && !dangling) || !pf.match(time.Unix(img.Created, 0), img.Labels) {
+				continue
+			}
+			size := img.Size
+			if img.SharedSize > 0 {
+				size -= img.SharedSize
+			}
+			add(PruneItem{ID: img.ID, Name: imageName(img.RepoTags), Size: size})
+		}
+	case PruneVolumes:
+		for _, v := range du.Volumes {
+			if v.UsageData == nil || v.UsageData.RefCount > 0 {
+				continue
+			}
+			if _, anonymous := v.Labels[anonymousVolumeLabel]; !all && !anonymous {
+				continue
+			}
+			if !pf.match(time.Time{}, v.Labels) {
+				continue
+			}
+			add(PruneItem{ID: v.Name, Name: v.Name, Size: v.UsageData.Size})
+		}
+	case PruneNetworks:
+		return s.previewNetworks(ctx, pf, add)
+	case PruneBuildCache:
+		for _, bc := range du.BuildCache {
+			if bc.InUse || (!all && bc.Shared) {
+				continue
+			}
+			lastUsed := bc.CreatedAt
+			if bc.LastUsedAt != nil {
+				lastUsed = *bc.LastUsedAt
+			}
+			if !pf.match(lastUsed, nil) {
+				continue
+			}
+			add(PruneItem{ID: bc.ID, Name: bc.Description, Size: bc.Size})
+		}
+	}
+
+	sort.Slice(result.Items, func(i, j int) bool { return result.Items[i].Size > result.Items[j].Size })
+	return nil
+}
+
+// previewNetworks 列出没有容器连接的自定义网络
+func (s *SystemService) previewNetworks(ctx context.Context, pf pruneFilter, add func(PruneItem)) error {
+	networks, err := s.client.NetworkList(ctx, network.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("获取网络列表失败: %w", err)
+	}
+	containers, err := s.client.ContainerList(ctx, containerTypes.ListOptions{All: true})
+	if err != nil {
+		return fmt.Errorf("获取容器列表失败: %w", err)
+	}
+
+	used := make(map[string]bool)
+	for _, c := range containers {
+		if c.NetworkSettings == nil {
+			continue
+		}
+		for _, ep := range c.NetworkSettings.Networks {
+			if ep != nil {
+				used[ep.NetworkID] = true
+			}
+		}
+	}
+
+	for _, n := range networks {
+		if !containerTypes.NetworkMode(n.Name).IsUserDefined() || n.Ingress || used[n.ID] {
+			continue
+		}
+		if !pf.match(n.Created, n.Labels) {
+			continue
+		}
+		add(PruneItem{ID: n.ID, Name: n.Name})
+	}
+	return nil
+}
+
+// match 判断资源是否满足时间和标签过滤条件，created 为零值时不比较时间
+func (f pruneFilter) match(created time.Time, labels map[string]string) bool {
+	if !f.until.IsZero() && !created.IsZero() && !created.Before(f.until) {
+		return false
+	}
+	for _, l := range f.labels {
+		value, ok := labels[l.key]
+		matched := ok && (!l.exact || value == l.value)
+		if matched == l.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// parseLabelFilters 解析标签过滤条件
+func parseLabelFilters(labels []string) []labelFilter {
+	result := make([]labelFilter, 0, len(labels))
+	for _, l := range labels {
+		var f labelFilter
+		l, f.negate = strings.CutPrefix(l, "!")
+		f.key, f.value, f.exact = strings.Cut(l, "=")
+		result = append(result, f)
+	}
+	return result
+}
+
+// parseUntil 解析时间过滤条件：时长表示距今多久之前，也可以是 RFC3339 时间
+func parseUntil(until string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(until); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("时长不能为负数: %s", until)
+		}
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, until)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("无效的时间: %s，应为时长（如 24h）或 RFC3339 时间", until)
+	}
+	return t, nil
+}
+
+// isActiveState 判断容器是否处于运行相关状态，这些容器不会被清理
+func isActiveState(state string) bool {
+	return state == "running" || state == "paused" || state == "restarting"
+}
+
+// imageName 返回镜像的第一个有效标签，悬空镜像返回空字符串
+func imageName(repoTags []string) string {
+	for _, tag := range repoTags {
+		if tag != "<none>:<none>" {
+			return tag
+		}
+	}
+	return ""
+}
+
+// pruneItems 将 ID 列表转换为清理结果
+func pruneItems(ids []string) []PruneItem {
+	items := make([]PruneItem, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, PruneItem{ID: id})
+	}
+	return items
+}
@@ -0,0 +1,225 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// ServiceUpdate Compose 服务镜像更新状态
+type ServiceUpdate struct {
+	Service string `json:"service"`
+	ImageUpdateStatus
+	Error string `json:"error,omitempty"`
+}
+
+// ComposeUpdateResult Compose 项目更新结果
+type ComposeUpdateResult struct {
+	Project  string          `json:"project"`
+	Services []ServiceUpdate `json:"services"`
+	Updated  []string        `json:"updated"`
+	Output   string          `json:"output,omitempty"`
+}
+
+// UpdateService 镜像更新服务
+type UpdateService struct {
+	images     *ImageService
+	containers *ContainerService
+	compose    *ComposeService
+}
+
+// NewUpdateService 创建镜像更新服务
+func NewUpdateService(cli *client.Client, executor CommandExecutor) *UpdateService {
+	return &UpdateService{
+		images:     NewImageService(cli),
+		containers: NewContainerService(cli),
+		compose:    NewComposeService(executor),
+	}
+}
+
+// CheckCompose 检查 Compose 项目中每个服务的镜像是否有更新
+func (s *UpdateService) CheckCompose(ctx context.Context, composeYAML string, opts ComposeOptions) ([]ServiceUpdate, error) {
+	_, updates, err := s.checkCompose(ctx, composeYAML, opts)
+	return updates, err
+}
+
+// checkCompose 检查镜像更新，同时返回解析后的项目配置
+func (s *UpdateService) checkCompose(ctx context.Context, composeYAML string, opts ComposeOptions) (*ProjectConfig, []ServiceUpdate, error) {
+	config, err := s.compose.Config(ctx, composeYAML, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, 0, len(config.Services))
+	for name := range config.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// 多个服务可能共用同一镜像，只检查一次
+	checked := make(map[string]ServiceUpdate)
+	updates := make([]ServiceUpdate, 0, len(names))
+	for _, name := range names {
+		svc := config.Services[name]
+		update := ServiceUpdate{Service: name}
+
+		if svc.Image == "" || (len(svc.Build) > 0 && string(svc.Build) != "null") {
+			update.Image = svc.Image
+			update.Error = "服务镜像由本地构建，不检查更新"
+			updates = append(updates, update)
+			continue
+		}
+
+		if cached, ok := checked[svc.Image]; ok {
+			cached.Service = name
+			updates = append(updates, cached)
+			continue
+		}
+
+		status, err := s.images.CheckUpdate(ctx, PullOptions{Image: svc.Image})
+		if err != nil {
+			update.Image = svc.Image
+			update.Error = err.Error()
+		} else {
+			update.ImageUpdateStatus = *status
+		}
+
+		checked[svc.Image] = update
+		updates = append(updates, update)
+	}
+
+	return config, updates, nil
+}
+
+// UpdateCompose 拉取有更新的镜像，并只重建受影响的服务
+// services 为空时检查项目中的全部服务
+func (s *UpdateService) UpdateCompose(ctx context.Context, composeYAML string, opts ComposeOptions, services []string) (*ComposeUpdateResult, error) {
+	config, updates, err := s.checkCompose(ctx, composeYAML, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]bool, len(services))
+	for _, name := range services {
+		selected[name] = true
+	}
+
+	result := &ComposeUpdateResult{Project: config.Name, Services: updates, Updated: []string{}}
+	pulled := make(map[string]error)
+	for i := range updates {
+		update := &updates[i]
+		if !update.UpdateAvailable || update.Error != "" {
+			continue
+		}
+		if len(selected) > 0 && !selected[update.Service] {
+			continue
+		}
+
+		pullErr, ok := pulled[update.Image]
+		if !ok {
+			pullErr = s.images.PullAndWait(ctx, PullOptions{Image: update.Image})
+			pulled[update.Image] = pullErr
+		}
+		if pullErr != nil {
+			update.Error = pullErr.Error()
+			continue
+		}
+
+		result.Updated = append(result.Updated, update.Service)
+	}
+
+	if len(result.Updated) == 0 {
+		return result, nil
+	}
+
+	stream, err := s.compose.Up(ctx, composeYAML, UpOptions{
+		ComposeOptions: opts,
+		Detach:         true,
+		NoDeps:         true,
+		Services:       result.Updated,
+	})
+	if err != nil {
+		return result, fmt.Errorf("重建服务失败: %w", err)
+	}
+	defer stream.Close()
+
+	output, _ := io.ReadAll(stream)
+	result.Output = string(output)
+
+	return result, nil
+}
+
+// WaitComposeHealthy 在宽限期内观察更新后服务的所有容器
+func (s *UpdateService) WaitComposeHealthy(ctx context.Context, result *ComposeUpdateResult, grace time.Duration) error {
+	var wg sync.WaitGroup
+	errCh := make(chan error, 1)
+
+	for _, service := range result.Updated {
+		containers, err := s.containers.ListByLabel(ctx, true,
+			"com.docker.compose.project="+result.Project,
+			"com.docker.compose.service="+service,
+		)
+		if err != nil {
+			return err
+		}
+		if len(containers) == 0 {
+			return fmt.Errorf("服务 %s 没有容器", service)
+		}
+
+		for _, c := range containers {
+			wg.Add(1)
+			go func(service, containerID string) {
+				defer wg.Done()
+				if err := s.containers.WaitHealthy(ctx, containerID, grace); err != nil {
+					select {
+					case errCh <- fmt.Errorf("服务 %s: %w", service, err):
+					default:
+					}
+				}
+			}(service, c.ID)
+		}
+	}
+
+	wg.Wait()
+	select {
+	case err := <-errCh:
+		return err
+	default:
+		return nil
+	}
+}
+
+// RollbackCompose 将更新过的服务恢复到更新前的镜像
+// 通过把旧镜像 ID 重新标记为原镜像名，再重建这些服务实现
+func (s *UpdateService) RollbackCompose(ctx context.Context, composeYAML string, opts ComposeOptions, result *ComposeUpdateResult) error {
+	updated := make(map[string]bool, len(result.Updated))
+	for _, name := range result.Updated {
+		updated[name] = true
+	}
+
+	for _, update := range result.Services {
+		if !updated[update.Service] || update.LocalID == "" {
+			continue
+		}
+		if err := s.images.Tag(ctx, update.LocalID, update.Image, ""); err != nil {
+			return fmt.Errorf("恢复服务 %s 的镜像失败: %w", update.Service, err)
+		}
+	}
+
+	stream, err := s.compose.Up(ctx, composeYAML, UpOptions{
+		ComposeOptions: opts,
+		Detach:         true,
+		NoDeps:         true,
+		Services:       result.Updated,
+	})
+	if err != nil {
+		return fmt.Errorf("回滚服务失败: %w", err)
+	}
+	stream.Close()
+	return nil
+}
@@ -0,0 +1,140 @@
+package docker
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+)
+
+// gzipMagic gzip 文件头
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Backup 将卷中的数据以 gzip 压缩的 tar 流写入 w
+// 通过只创建不启动的辅助容器读取卷，归档中的路径相对于卷的根目录
+func (s *VolumeService) Backup(ctx context.Context, name, helperImage string, w io.Writer) error {
+	if _, err := s.client.VolumeInspect(ctx, name); err != nil {
+		return fmt.Errorf("获取卷详情失败: %w", err)
+	}
+	if err := ensureImage(ctx, s.client, helperImage); err != nil {
+		return err
+	}
+
+	helper, err := createVolumeHelper(ctx, s.client, name, helperImage, true)
+	if err != nil {
+		return err
+	}
+	defer removeVolumeHelper(s.client, helper)
+
+	reader, _, err := s.client.CopyFromContainer(ctx, helper, helperMountPath)
+	if err != nil {
+		return fmt.Errorf("读取卷数据失败: %w", err)
+	}
+	defer reader.Close()
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	tr := tar.NewReader(reader)
+
+	// 归档以挂载目录名为根，改写为相对于卷根目录的路径
+	root := path.Base(helperMountPath)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("读取卷数据失败: %w", err)
+		}
+
+		hdr.Name = "." + strings.TrimPrefix(hdr.Name, root)
+		if hdr.Typeflag == tar.TypeLink {
+			hdr.Linkname = "." + strings.TrimPrefix(hdr.Linkname, root)
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("写入备份失败: %w", err)
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return fmt.Errorf("写入备份失败: %w", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("写入备份失败: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("写入备份失败: %w", err)
+	}
+	return nil
+}
+
+// Restore 将 tar 归档（可为 gzip 压缩）解压到卷中，卷不存在时自动创建
+// 卷中已有的同名文件会被覆盖，归档中没有的文件保留
+func (s *VolumeService) Restore(ctx context.Context, name, helperImage string, r io.Reader) error {
+	if _, err := s.client.VolumeInspect(ctx, name); err != nil {
+		if !client.IsErrNotFound(err) {
+			return fmt.Errorf("获取卷详情失败: %w", err)
+		}
+		if _, err := s.client.VolumeCreate(ctx, volume.CreateOptions{Name: name}); err != nil {
+			return fmt.Errorf("创建卷失败: %w", err)
+		}
+	}
+	if err := ensureImage(ctx, s.client, helperImage); err != nil {
+		return err
+	}
+
+	helper, err := createVolumeHelper(ctx, s.client, name, helperImage, false)
+	if err != nil {
+		return err
+	}
+	defer removeVolumeHelper(s.client, helper)
+
+	input, err := decompress(r)
+	if err != nil {
+		return err
+	}
+
+	err = s.client.CopyToContainer(ctx, helper, helperMountPath, input, containerTypes.CopyToContainerOptions{})
+	if err != nil {
+		return fmt.Errorf("恢复卷数据失败: %w", err)
+	}
+	return nil
+}
+
+// IsGzip 判断数据是否以 gzip 文件头开头
+func IsGzip(header []byte) bool {
+	return bytes.HasPrefix(header, gzipMagic)
+}
+
+// decompress 根据文件头判断是否需要解压 gzip
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(len(gzipMagic))
+	if !IsGzip(header) {
+		return br, nil
+	}
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, fmt.Errorf("解压备份失败: %w", err)
+	}
+	return gz, nil
+}
+
+// ensureImage 确保主机上存在镜像，不存在时拉取
+func ensureImage(ctx context.Context, cli *client.Client, image string) error {
+	if _, _, err := cli.ImageInspectWithRaw(ctx, image); err == nil {
+		return nil
+	} else if !client.IsErrNotFound(err) {
+		return fmt.Errorf("获取镜像详情失败: %w", err)
+	}
+	return NewImageService(cli).PullAndWait(ctx, PullOptions{Image: image})
+}
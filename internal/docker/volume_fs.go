@@ -0,0 +1,190 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	containerTypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+)
+
+// VolumeUsage 卷的磁盘占用及使用情况
+type VolumeUsage struct {
+	Name       string            `json:"name"`
+	Driver     string            `json:"driver"`
+	Size       int64             `json:"size"` // 字节数，驱动不支持统计时为 -1
+	RefCount   int64             `json:"ref_count"`
+	Dangling   bool              `json:"dangling"` // 没有任何容器挂载
+	Containers []VolumeContainer `json:"containers"`
+}
+
+// VolumeContainer 挂载卷的容器
+type VolumeContainer struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	Destination string `json:"destination"`
+	ReadOnly    bool   `json:"read_only"`
+}
+
+// VolumeBrowser 通过只读挂载卷的辅助容器浏览卷中的文件
+// 辅助容器只创建不启动，使用完毕后需调用 Close 删除
+type VolumeBrowser struct {
+	containers *ContainerService
+	helperID   string
+}
+
+// Usage 获取所有卷的磁盘占用以及挂载它们的容器
+func (s *VolumeService) Usage(ctx context.Context) ([]VolumeUsage, error) {
+	du, err := s.client.DiskUsage(ctx, types.DiskUsageOptions{
+		Types: []types.DiskUsageObject{types.VolumeObject},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取卷磁盘占用失败: %w", err)
+	}
+
+	containers, err := s.client.ContainerList(ctx, containerTypes.ListOptions{All: true})
+	if err != nil {
+		return nil, fmt.Errorf("获取容器列表失败: %w", err)
+	}
+	mounted := make(map[string][]VolumeContainer)
+	for _, c := range containers {
+		for _, m := range c.Mounts {
+			if m.Type != mount.TypeVolume || m.Name == "" {
+				continue
+			}
+			mounted[m.Name] = append(mounted[m.Name], VolumeContainer{
+				ID:          c.ID,
+				Name:        getContainerName(c.Names),
+				State:       c.State,
+				Destination: m.Destination,
+				ReadOnly:    !m.RW,
+			})
+		}
+	}
+
+	result := make([]VolumeUsage, 0, len(du.Volumes))
+	for _, v := range du.Volumes {
+		usage := VolumeUsage{
+			Name:       v.Name,
+			Driver:     v.Driver,
+			Size:       -1,
+			RefCount:   -1,
+			Containers: mounted[v.Name],
+		}
+		if v.UsageData != nil {
+			usage.Size = v.UsageData.Size
+			usage.RefCount = v.UsageData.RefCount
+		}
+		if usage.Containers == nil {
+			usage.Containers = []VolumeContainer{}
+		}
+		usage.Dangling = len(usage.Containers) == 0
+		result = append(result, usage)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// Browse 创建用于浏览卷的辅助容器
+func (s *VolumeService) Browse(ctx context.Context, name, helperImage string) (*VolumeBrowser, error) {
+	if _, err := s.client.VolumeInspect(ctx, name); err != nil {
+		return nil, fmt.Errorf("获取卷详情失败: %w", err)
+	}
+	if err := ensureImage(ctx, s.client, helperImage); err != nil {
+		return nil, err
+	}
+
+	helper, err := createVolumeHelper(ctx, s.client, name, helperImage, true)
+	if err != nil {
+		return nil, err
+	}
+	return &VolumeBrowser{containers: NewContainerService(s.client), helperID: helper}, nil
+}
+
+// Close 删除辅助容器
+func (b *VolumeBrowser) Close() {
+	removeVolumeHelper(b.containers.client, b.helperID)
+}
+
+// List 列出卷中目录的直接子项，路径相对于卷的根目录
+func (b *VolumeBrowser) List(ctx context.Context, dirPath string) ([]FileInfo, bool, error) {
+	full, err := b.resolve(ctx, dirPath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	files, truncated, err := b.containers.ListFiles(ctx, b.helperID, full, false)
+	if err != nil {
+		return nil, false, err
+	}
+	for i := range files {
+		files[i].Path = volumePath(files[i].Path)
+	}
+	return files, truncated, nil
+}
+
+// ReadFile 读取卷中单个普通文件的内容
+func (b *VolumeBrowser) ReadFile(ctx context.Context, filePath string) (io.ReadCloser, *PathStat, error) {
+	full, err := b.resolve(ctx, filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, stat, err := b.containers.ReadFile(ctx, b.helperID, full)
+	if err != nil {
+		return nil, nil, err
+	}
+	stat.Path = volumePath(stat.Path)
+	return reader, stat, nil
+}
+
+// CopyFrom 以 tar 归档形式读取卷中的文件或目录
+func (b *VolumeBrowser) CopyFrom(ctx context.Context, srcPath string) (io.ReadCloser, *PathStat, error) {
+	full, err := b.resolve(ctx, srcPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, stat, err := b.containers.CopyFrom(ctx, b.helperID, full)
+	if err != nil {
+		return nil, nil, err
+	}
+	stat.Path = volumePath(stat.Path)
+	// 卷的根目录在归档中以挂载目录名命名，下载时以卷名为准更直观
+	if stat.Path == "/" {
+		stat.Name = "volume"
+	}
+	return reader, stat, nil
+}
+
+// resolve 将卷内路径转换为辅助容器中的路径
+// 符号链接指向卷之外时返回错误，避免读取到辅助容器镜像中的文件
+func (b *VolumeBrowser) resolve(ctx context.Context, p string) (string, error) {
+	full := path.Join(helperMountPath, path.Clean("/"+p))
+
+	stat, err := b.containers.StatPath(ctx, b.helperID, full)
+	if err != nil {
+		return "", err
+	}
+	if stat.LinkTarget != "" && !withinVolume(path.Clean("/"+stat.LinkTarget)) {
+		return "", fmt.Errorf("%s 是指向卷之外的符号链接", path.Clean("/"+p))
+	}
+	return full, nil
+}
+
+// withinVolume 判断辅助容器中的路径是否位于卷内
+func withinVolume(p string) bool {
+	return p == helperMountPath || strings.HasPrefix(p, helperMountPath+"/")
+}
+
+// volumePath 将辅助容器中的路径转换为相对于卷根目录的路径
+func volumePath(p string) string {
+	return path.Clean("/" + strings.TrimPrefix(p, helperMountPath))
+}
@@ -1,8 +1,7 @@
 package handler
 
 import (
-	"bytes"
-	"io"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -28,19 +27,33 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 	return w.ResponseWriter.Write(b)
 }
 
+// Unwrap 返回底层的 ResponseWriter，使 http.ResponseController 可以穿透包装
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// auditMessageKey 处理函数设置的审计消息在 gin.Context 中的键
+const auditMessageKey = "audit_message"
+
+// setAuditMessage 为当前请求设置审计消息，替代默认的状态描述
+func setAuditMessage(c *gin.Context, message string) {
+	c.Set(auditMessageKey, message)
+}
+
+// auditRecordingKey 终端会话录像 ID 在 gin.Context 中的键
+const auditRecordingKey = "audit_recording_id"
+
+// setAuditRecording 将当前请求的审计日志关联到终端会话录像
+func setAuditRecording(c *gin.Context, recordingID string) {
+	c.Set(auditRecordingKey, recordingID)
+}
+
 // AuditMiddleware 审计日志中间件
 func AuditMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 记录开始时间
 		startTime := time.Now()
 
-		// 读取请求体（如果需要）
-		var requestBody []byte
-		if c.Request.Body != nil {
-			requestBody, _ = io.ReadAll(c.Request.Body)
-			c.Request.Body = io.NopCloser(bytes.NewBuffer(requestBody))
-		}
-
 		// 包装 ResponseWriter 以捕获状态码
 		writer := &responseWriter{
 			ResponseWriter: c.Writer,
@@ -57,13 +70,17 @@ func AuditMiddleware() gin.HandlerFunc {
 		// 只记录非健康检查的请求
 		if c.Request.URL.Path != "/api/v1/health" {
 			auditLog := &model.AuditLog{
-				Method:    c.Request.Method,
-				Path:      c.Request.URL.Path,
-				Status:    writer.status,
-				IP:        c.ClientIP(),
-				UserAgent: c.Request.UserAgent(),
-				Latency:   latency,
-				Message:   getStatusMessage(writer.status),
+				Method:      c.Request.Method,
+				Path:        c.Request.URL.Path,
+				Status:      writer.status,
+				IP:          c.ClientIP(),
+				UserAgent:   c.Request.UserAgent(),
+				Latency:     latency,
+				Message:     getStatusMessage(writer.status),
+				RecordingID: c.GetString(auditRecordingKey),
+			}
+			if message := c.GetString(auditMessageKey); message != "" {
+				auditLog.Message = message
 			}
 
 			// 异步写入日志，避免影响请求性能
@@ -71,8 +88,6 @@ func AuditMiddleware() gin.HandlerFunc {
 				repository.CreateAuditLog(auditLog)
 			}()
 		}
-
-		_ = requestBody // 避免未使用警告
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"io"
 	"path/filepath"
 	"rubick/internal/docker"
+	"rubick/internal/inventory"
 	"rubick/internal/model"
 	"rubick/internal/repository"
 	"strings"
@@ -16,8 +17,13 @@ import (
 )
 
 // ListComposeProjects 列出 Compose 项目
+// host_id 为空或 all 时列出所有主机的项目，可按主机分组、标签和环境筛选
 func ListComposeProjects(c *gin.Context) {
 	hostID := c.Query("host_id")
+	fleet := isFleetQuery(c)
+	if fleet {
+		hostID = ""
+	}
 
 	projects, err := repository.ListComposeProjects(hostID)
 	if err != nil {
@@ -25,6 +31,24 @@ func ListComposeProjects(c *gin.Context) {
 		return
 	}
 
+	if fleet {
+		hosts, ok := fleetHosts(c)
+		if !ok {
+			return
+		}
+		matched := make(map[string]bool, len(hosts))
+		for _, host := range hosts {
+			matched[host.ID] = true
+		}
+		filtered := make([]model.ComposeProject, 0, len(projects))
+		for _, p := range projects {
+			if matched[p.HostID] {
+				filtered = append(filtered, p)
+			}
+		}
+		projects = filtered
+	}
+
 	Success(c, projects)
 }
 
@@ -154,38 +178,12 @@ func DeleteComposeProject(c *gin.Context) {
 
 // getExecutor 根据主机类型获取对应的命令执行器
 func getExecutor(host *model.Host) (docker.CommandExecutor, error) {
-	switch host.Type {
-	case "local":
-		return docker.NewLocalExecutor()
-	case "ssh":
-		return docker.NewSSHExecutor(&docker.ConnectionConfig{
-			Type:          docker.ConnectionTypeSSH,
-			Host:          host.Host,
-			SSHUser:       host.SSHUser,
-			SSHAuthType:   host.SSHAuthType,
-			SSHPrivateKey: host.SSHPrivateKey,
-			SSHPassword:   host.SSHPassword,
-			SSHPort:       host.SSHPort,
-		})
-	default:
-		return nil, fmt.Errorf("不支持的主机类型: %s", host.Type)
-	}
+	return docker.NewHostExecutor(host)
 }
 
 // getComposeOptions 根据 project 构建 ComposeOptions
 func getComposeOptions(project *model.ComposeProject) docker.ComposeOptions {
-	opts := docker.ComposeOptions{
-		ProjectName: project.Name,
-	}
-
-	if project.SourceType == "directory" {
-		opts.UseWorkDir = true
-		opts.WorkDir = project.WorkDir
-		opts.ComposeFile = project.ComposeFile
-		opts.EnvFile = project.EnvFile
-	}
-
-	return opts
+	return docker.ComposeOptionsForProject(project)
 }
 
 // ComposeUp 启动 Compose 项目
@@ -238,6 +236,7 @@ func ComposeUp(c *gin.Context) {
 
 		// 更新项目状态
 		repository.UpdateComposeProjectStatus(id, "running")
+		inventory.GetInventory().Invalidate(project.Host, inventory.TypeContainer, inventory.TypeImage, inventory.TypeVolume, inventory.TypeNetwork)
 
 		SuccessWithMessage(c, "Compose 项目已启动", nil)
 	} else {
@@ -269,6 +268,7 @@ func ComposeUp(c *gin.Context) {
 
 		// 更新项目状态
 		repository.UpdateComposeProjectStatus(id, "running")
+		inventory.GetInventory().Invalidate(project.Host, inventory.TypeContainer, inventory.TypeImage, inventory.TypeVolume, inventory.TypeNetwork)
 	}
 }
 
@@ -315,6 +315,7 @@ func ComposeDown(c *gin.Context) {
 
 	// 更新项目状态
 	repository.UpdateComposeProjectStatus(id, "stopped")
+	inventory.GetInventory().Invalidate(project.Host, inventory.TypeContainer, inventory.TypeImage, inventory.TypeVolume, inventory.TypeNetwork)
 
 	SuccessWithMessage(c, "Compose 项目已停止并删除", gin.H{
 		"output": string(output),
@@ -356,6 +357,7 @@ func ComposeStart(c *gin.Context) {
 
 	// 更新项目状态
 	repository.UpdateComposeProjectStatus(id, "running")
+	inventory.GetInventory().Invalidate(project.Host, inventory.TypeContainer)
 
 	SuccessWithMessage(c, "Compose 项目已启动", gin.H{
 		"output": string(output),
@@ -398,6 +400,7 @@ func ComposeStop(c *gin.Context) {
 
 	// 更新项目状态
 	repository.UpdateComposeProjectStatus(id, "stopped")
+	inventory.GetInventory().Invalidate(project.Host, inventory.TypeContainer)
 
 	SuccessWithMessage(c, "Compose 项目已停止", gin.H{
 		"output": string(output),
@@ -440,6 +443,7 @@ func ComposeRestart(c *gin.Context) {
 
 	// 更新项目状态
 	repository.UpdateComposeProjectStatus(id, "running")
+	inventory.GetInventory().Invalidate(project.Host, inventory.TypeContainer)
 
 	SuccessWithMessage(c, "Compose 项目已重启", gin.H{
 		"output": string(output),
@@ -759,3 +763,85 @@ func UploadDirectory(c *gin.Context) {
 		"count":          len(uploadedFiles),
 	})
 }
+
+// CheckComposeUpdates 检查 Compose 项目的镜像更新
+func CheckComposeUpdates(c *gin.Context) {
+	id := c.Param("id")
+
+	project, err := repository.GetComposeProjectByID(id)
+	if err != nil {
+		NotFound(c, "Compose 项目不存在")
+		return
+	}
+
+	executor, err := getExecutor(project.Host)
+	if err != nil {
+		ServerError(c, "创建执行器失败: "+err.Error())
+		return
+	}
+	defer executor.Close()
+
+	cli, err := getClient(c.Request.Context(), project.Host)
+	if err != nil {
+		clientError(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+
+	updateService := docker.NewUpdateService(cli, executor)
+	updates, err := updateService.CheckCompose(ctx, project.Content, getComposeOptions(project))
+	if err != nil {
+		ServerError(c, "检查镜像更新失败: "+err.Error())
+		return
+	}
+
+	Success(c, updates)
+}
+
+// UpdateComposeImages 拉取有更新的镜像并重建受影响的服务
+func UpdateComposeImages(c *gin.Context) {
+	id := c.Param("id")
+
+	project, err := repository.GetComposeProjectByID(id)
+	if err != nil {
+		NotFound(c, "Compose 项目不存在")
+		return
+	}
+
+	var req struct {
+		Services []string `json:"services"`
+	}
+	c.ShouldBindJSON(&req)
+
+	executor, err := getExecutor(project.Host)
+	if err != nil {
+		ServerError(c, "创建执行器失败: "+err.Error())
+		return
+	}
+	defer executor.Close()
+
+	cli, err := getClient(c.Request.Context(), project.Host)
+	if err != nil {
+		clientError(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Minute)
+	defer cancel()
+
+	updateService := docker.NewUpdateService(cli, executor)
+	result, err := updateService.UpdateCompose(ctx, project.Content, getComposeOptions(project), req.Services)
+	if err != nil {
+		ServerError(c, "更新 Compose 项目失败: "+err.Error())
+		return
+	}
+
+	if len(result.Updated) > 0 {
+		repository.UpdateComposeProjectStatus(id, "running")
+		inventory.GetInventory().Invalidate(project.Host, inventory.TypeContainer, inventory.TypeImage)
+	}
+
+	SuccessWithMessage(c, fmt.Sprintf("已更新 %d 个服务", len(result.Updated)), result)
+}
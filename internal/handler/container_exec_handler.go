@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"rubick/internal/docker"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultExecTimeout 一次性命令的默认超时（秒）
+	defaultExecTimeout = 60
+	// maxExecTimeout 一次性命令的最大超时（秒）
+	maxExecTimeout = 3600
+	// maxExecOutput stdout、stderr 各自返回的最大字节数
+	maxExecOutput = 1 << 20
+	// maxExecStdin 标准输入的最大字节数
+	maxExecStdin = 1 << 20
+)
+
+// RunContainerExec 在容器中以非 TTY 方式执行一次性命令，等待结束后返回 stdout、stderr 和退出码
+// 超时后返回已读取的输出，timed_out 为 true，exit_code 为空；命令在容器中不会被终止
+func RunContainerExec(c *gin.Context) {
+	containerID := c.Param("id")
+
+	var req struct {
+		Cmd     []string `json:"cmd" binding:"required"`
+		Env     []string `json:"env"`
+		WorkDir string   `json:"work_dir"`
+		User    string   `json:"user"`
+		Stdin   *string  `json:"stdin"`
+		Timeout int      `json:"timeout"` // 秒，默认 60
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+	if msg := validateExecRequest(req.Cmd, req.Env, req.Stdin, &req.Timeout); msg != "" {
+		BadRequest(c, msg)
+		return
+	}
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+	info, err := svc.Get(c.Request.Context(), containerID)
+	if err != nil {
+		NotFound(c, "容器不存在: "+err.Error())
+		return
+	}
+
+	opts := docker.ExecOptions{
+		Cmd:        req.Cmd,
+		User:       req.User,
+		Env:        req.Env,
+		WorkingDir: req.WorkDir,
+		MaxOutput:  maxExecOutput,
+	}
+	if req.Stdin != nil {
+		opts.Stdin = strings.NewReader(*req.Stdin)
+	}
+
+	disableWriteTimeout(c)
+	setAuditMessage(c, "执行命令 "+info.Name+": "+strings.Join(req.Cmd, " "))
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), time.Duration(req.Timeout)*time.Second)
+	defer cancel()
+
+	// 只有读取输出时超时才会返回结果，创建或连接命令时超时视为失败
+	result, err := svc.ExecRun(ctx, containerID, opts)
+	if err != nil && (result == nil || !result.TimedOut) {
+		if errors.Is(err, context.DeadlineExceeded) {
+			FailWithStatus(c, http.StatusGatewayTimeout, CodeDockerError, "执行命令超时: "+err.Error())
+			return
+		}
+		ServerError(c, "执行命令失败: "+err.Error())
+		return
+	}
+
+	Success(c, result)
+}
+
+// validateExecRequest 校验一次性命令的参数，timeout 为 0 时设为默认值
+func validateExecRequest(cmd, env []string, stdin *string, timeout *int) string {
+	if len(cmd) == 0 || cmd[0] == "" {
+		return "cmd 不能为空"
+	}
+	for _, e := range env {
+		if strings.Index(e, "=") <= 0 {
+			return "env 的格式应为 KEY=VALUE: " + e
+		}
+	}
+	if stdin != nil && len(*stdin) > maxExecStdin {
+		return "stdin 不能超过 1 MB"
+	}
+	if *timeout == 0 {
+		*timeout = defaultExecTimeout
+	}
+	if *timeout < 1 || *timeout > maxExecTimeout {
+		return "timeout 必须在 1-3600 秒之间"
+	}
+	return ""
+}
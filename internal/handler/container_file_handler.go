@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"rubick/internal/docker"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListContainerFiles 浏览容器内的目录，容器停止时同样可用
+// 默认通过 tar 导出目录，目录下内容较多时结果可能被截断；
+// exec=true 时对运行中的容器以 root 执行 sh -c stat 只读取目录本身，该命令会出现在 docker top 和 exec 审计中
+func ListContainerFiles(c *gin.Context) {
+	containerID := c.Param("id")
+	dirPath := c.DefaultQuery("path", "/")
+	useExec := c.Query("exec") == "true"
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+
+	files, truncated, err := svc.ListFiles(c.Request.Context(), containerID, dirPath, useExec)
+	if err != nil {
+		ServerError(c, "浏览容器目录失败: "+err.Error())
+		return
+	}
+
+	Success(c, gin.H{
+		"path":      path.Clean("/" + dirPath),
+		"files":     files,
+		"truncated": truncated,
+	})
+}
+
+// DownloadContainerFiles 下载容器内的文件或目录
+// format=tar（默认）返回 tar 归档；format=raw 直接返回普通文件的内容
+func DownloadContainerFiles(c *gin.Context) {
+	containerID := c.Param("id")
+	srcPath := c.Query("path")
+	format := c.DefaultQuery("format", "tar")
+
+	if srcPath == "" {
+		BadRequest(c, "path 参数不能为空")
+		return
+	}
+	if format != "tar" && format != "raw" {
+		BadRequest(c, "format 只能是 tar 或 raw")
+		return
+	}
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+
+	disableWriteTimeout(c)
+	setAuditMessage(c, "下载容器 "+containerID+" 中的 "+srcPath)
+
+	if format == "raw" {
+		reader, stat, err := svc.ReadFile(c.Request.Context(), containerID, srcPath)
+		if err != nil {
+			ServerError(c, err.Error())
+			return
+		}
+		defer reader.Close()
+
+		c.DataFromReader(http.StatusOK, stat.Size, "application/octet-stream", reader, map[string]string{
+			"Content-Disposition": attachmentHeader(stat.Name),
+		})
+		return
+	}
+
+	reader, stat, err := svc.CopyFrom(c.Request.Context(), containerID, srcPath)
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, -1, "application/x-tar", reader, map[string]string{
+		"Content-Disposition": attachmentHeader(stat.Name + ".tar"),
+	})
+}
+
+// UploadContainerFiles 上传文件到容器内的目录，容器停止时同样可用
+// 支持 multipart 表单（path + files）或 Content-Type 为 application/x-tar 的归档（?path=）
+func UploadContainerFiles(c *gin.Context) {
+	containerID := c.Param("id")
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+
+	disableReadTimeout(c)
+	disableWriteTimeout(c)
+
+	if strings.HasPrefix(c.ContentType(), "application/x-tar") {
+		dstPath := c.Query("path")
+		if dstPath == "" {
+			BadRequest(c, "path 参数不能为空")
+			return
+		}
+
+		setAuditMessage(c, "上传归档到容器 "+containerID+" 的 "+dstPath)
+		if err := svc.CopyTo(c.Request.Context(), containerID, dstPath, c.Request.Body); err != nil {
+			ServerError(c, err.Error())
+			return
+		}
+		SuccessWithMessage(c, "上传成功", nil)
+		return
+	}
+
+	dstPath := c.PostForm("path")
+	if dstPath == "" {
+		BadRequest(c, "path 参数不能为空")
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		BadRequest(c, "解析表单失败: "+err.Error())
+		return
+	}
+	headers := form.File["files"]
+	if len(headers) == 0 {
+		BadRequest(c, "没有上传文件")
+		return
+	}
+
+	files := make([]docker.UploadFile, 0, len(headers))
+	names := make([]string, 0, len(headers))
+	for _, header := range headers {
+		f, err := header.Open()
+		if err != nil {
+			ServerError(c, "打开上传文件失败: "+err.Error())
+			return
+		}
+		defer f.Close()
+
+		files = append(files, docker.UploadFile{
+			Name:   header.Filename,
+			Size:   header.Size,
+			Reader: f,
+		})
+		names = append(names, header.Filename)
+	}
+
+	setAuditMessage(c, fmt.Sprintf("上传 %d 个文件到容器 %s 的 %s", len(files), containerID, dstPath))
+	if err := svc.UploadFiles(c.Request.Context(), containerID, dstPath, files); err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	SuccessWithMessage(c, "上传成功", gin.H{
+		"path":  dstPath,
+		"files": names,
+	})
+}
+
+// GetContainerChanges 获取容器文件系统相对镜像的变更
+func GetContainerChanges(c *gin.Context) {
+	containerID := c.Param("id")
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+
+	changes, err := svc.Diff(c.Request.Context(), containerID)
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	Success(c, changes)
+}
+
+// attachmentHeader 生成下载文件的 Content-Disposition 头
+func attachmentHeader(filename string) string {
+	return fmt.Sprintf(`attachment; filename="%s"`, strings.NewReplacer(`"`, "", "\\", "", "\n", "", "\r", "").Replace(filename))
+}
+
+// uploadReader 返回上传内容的流，不在服务端缓存
+// 请求体可以直接是文件内容，也可以是 multipart 表单中名为 field 的文件字段
+func uploadReader(c *gin.Context, field string) (io.Reader, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/") {
+		return c.Request.Body, nil
+	}
+
+	mr, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("解析表单失败: %w", err)
+	}
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			return nil, fmt.Errorf("表单中没有 %s 字段", field)
+		}
+		if part.FormName() == field {
+			return part, nil
+		}
+	}
+}
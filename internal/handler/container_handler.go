@@ -2,13 +2,21 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"rubick/internal/docker"
+	"rubick/internal/inventory"
 	"rubick/internal/model"
+	"rubick/internal/recording"
 	"rubick/internal/repository"
 
-	containerTypes "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 	"github.com/gin-gonic/gin"
 )
@@ -18,6 +26,13 @@ func ListContainers(c *gin.Context) {
 	hostID := c.Query("host_id")
 	all := c.Query("all") == "true"
 
+	if isFleetQuery(c) {
+		listAllHosts(c, func(ctx context.Context, cli *client.Client) ([]docker.ContainerInfo, error) {
+			return docker.NewContainerService(cli).List(ctx, all)
+		})
+		return
+	}
+
 	// 获取主机
 	host, err := getHost(hostID)
 	if err != nil {
@@ -25,10 +40,20 @@ func ListContainers(c *gin.Context) {
 		return
 	}
 
+	cached := serveInventory(c, host.ID, "containers-"+strconv.FormatBool(all), func(s *inventory.Snapshot) interface{} {
+		if all {
+			return s.Containers
+		}
+		return runningContainers(s.Containers)
+	})
+	if cached {
+		return
+	}
+
 	// 获取 Docker 客户端
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -52,6 +77,12 @@ func CreateContainer(c *gin.Context) {
 		return
 	}
 
+	// 先校验配置，避免无效参数到达 Docker 后才报错
+	if _, _, err := req.Build(); err != nil {
+		FailWithStatus(c, http.StatusBadRequest, CodeInvalidConfig, "无效的容器配置: "+err.Error())
+		return
+	}
+
 	host, err := getHost(hostID)
 	if err != nil {
 		ServerError(c, "获取主机失败: "+err.Error())
@@ -60,7 +91,7 @@ func CreateContainer(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -71,6 +102,7 @@ func CreateContainer(c *gin.Context) {
 		return
 	}
 
+	inventory.GetInventory().Invalidate(host, inventory.TypeContainer)
 	Success(c, gin.H{
 		"id":       resp.ID,
 		"warnings": resp.Warnings,
@@ -90,7 +122,7 @@ func GetContainer(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -117,7 +149,7 @@ func StartContainer(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -127,6 +159,7 @@ func StartContainer(c *gin.Context) {
 		return
 	}
 
+	inventory.GetInventory().Invalidate(host, inventory.TypeContainer)
 	SuccessWithMessage(c, "容器启动成功", nil)
 }
 
@@ -148,7 +181,7 @@ func StopContainer(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -162,6 +195,7 @@ func StopContainer(c *gin.Context) {
 		return
 	}
 
+	inventory.GetInventory().Invalidate(host, inventory.TypeContainer)
 	SuccessWithMessage(c, "容器停止成功", nil)
 }
 
@@ -183,7 +217,7 @@ func RestartContainer(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -197,6 +231,7 @@ func RestartContainer(c *gin.Context) {
 		return
 	}
 
+	inventory.GetInventory().Invalidate(host, inventory.TypeContainer)
 	SuccessWithMessage(c, "容器重启成功", nil)
 }
 
@@ -216,7 +251,7 @@ func RemoveContainer(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -241,22 +276,291 @@ func RemoveContainer(c *gin.Context) {
 	// 删除镜像
 	if removeImage && imageID != "" {
 		imageSvc := docker.NewImageService(cli)
-		if _, err := imageSvc.Remove(c.Request.Context(), imageID, true); err != nil {
+		_, err := imageSvc.Remove(c.Request.Context(), imageID, true)
+		inventory.GetInventory().Invalidate(host, inventory.TypeContainer, inventory.TypeImage)
+		if err != nil {
 			SuccessWithMessage(c, "容器已删除，但删除镜像失败: "+err.Error(), nil)
 			return
 		}
+	} else {
+		inventory.GetInventory().Invalidate(host, inventory.TypeContainer)
 	}
 
 	SuccessWithMessage(c, "容器删除成功", nil)
 }
 
-// GetContainerLogs 获取容器日志
-func GetContainerLogs(c *gin.Context) {
+// getContainerService 根据请求的 host_id 创建容器服务，失败时写入错误响应
+func getContainerService(c *gin.Context) (*docker.ContainerService, bool) {
+	host, err := getHost(c.Query("host_id"))
+	if err != nil {
+		ServerError(c, "获取主机失败: "+err.Error())
+		return nil, false
+	}
+
+	cli, err := getClient(c.Request.Context(), host)
+	if err != nil {
+		clientError(c, err)
+		return nil, false
+	}
+
+	return docker.NewContainerService(cli), true
+}
+
+// PauseContainer 暂停容器
+func PauseContainer(c *gin.Context) {
+	containerID := c.Param("id")
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+
+	setAuditMessage(c, "暂停容器 "+containerID)
+	if err := svc.Pause(c.Request.Context(), containerID); err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	refreshInventory(c, inventory.TypeContainer)
+	SuccessWithMessage(c, "容器已暂停", nil)
+}
+
+// UnpauseContainer 恢复已暂停的容器
+func UnpauseContainer(c *gin.Context) {
+	containerID := c.Param("id")
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+
+	setAuditMessage(c, "恢复容器 "+containerID)
+	if err := svc.Unpause(c.Request.Context(), containerID); err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	refreshInventory(c, inventory.TypeContainer)
+	SuccessWithMessage(c, "容器已恢复", nil)
+}
+
+// KillContainer 向容器发送信号
+func KillContainer(c *gin.Context) {
+	containerID := c.Param("id")
+
+	var req struct {
+		Signal string `json:"signal"` // 为空时发送 SIGKILL
+	}
+	// 请求体可以省略，但不能是无效的 JSON，避免误发送 SIGKILL
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+	if err := docker.ValidateSignal(req.Signal); err != nil {
+		FailWithStatus(c, http.StatusBadRequest, CodeInvalidConfig, err.Error())
+		return
+	}
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+
+	signal := req.Signal
+	if signal == "" {
+		signal = "SIGKILL"
+	}
+	setAuditMessage(c, "向容器 "+containerID+" 发送信号 "+signal)
+	if err := svc.Kill(c.Request.Context(), containerID, req.Signal); err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	refreshInventory(c, inventory.TypeContainer)
+	SuccessWithMessage(c, "信号已发送", nil)
+}
+
+// RenameContainer 重命名容器
+func RenameContainer(c *gin.Context) {
+	containerID := c.Param("id")
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+	if err := docker.ValidateContainerName(req.Name); err != nil {
+		FailWithStatus(c, http.StatusBadRequest, CodeInvalidConfig, err.Error())
+		return
+	}
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+
+	setAuditMessage(c, "重命名容器 "+containerID+" 为 "+req.Name)
+	if err := svc.Rename(c.Request.Context(), containerID, req.Name); err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	refreshInventory(c, inventory.TypeContainer)
+	SuccessWithMessage(c, "容器重命名成功", nil)
+}
+
+// UpdateContainerResources 在线更新容器的资源限制和重启策略
+func UpdateContainerResources(c *gin.Context) {
+	containerID := c.Param("id")
+
+	var req docker.UpdateResourcesOptions
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+	if _, err := req.Build(); err != nil {
+		FailWithStatus(c, http.StatusBadRequest, CodeInvalidConfig, "无效的资源配置: "+err.Error())
+		return
+	}
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+
+	setAuditMessage(c, "更新容器 "+containerID+" 的资源配置")
+	warnings, err := svc.UpdateResources(c.Request.Context(), containerID, req)
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	refreshInventory(c, inventory.TypeContainer)
+	SuccessWithMessage(c, "容器资源更新成功", gin.H{
+		"warnings": warnings,
+	})
+}
+
+// WaitContainer 等待容器停止并返回退出码
+func WaitContainer(c *gin.Context) {
+	containerID := c.Param("id")
+	condition := c.DefaultQuery("condition", "not-running")
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+
+	// 等待时间不确定，取消写超时，由客户端断开连接来结束等待
+	disableWriteTimeout(c)
+
+	exitCode, err := svc.Wait(c.Request.Context(), containerID, condition)
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	Success(c, gin.H{
+		"exit_code": exitCode,
+	})
+}
+
+// CommitContainer 将容器提交为新镜像
+func CommitContainer(c *gin.Context) {
+	containerID := c.Param("id")
+
+	var req docker.CommitOptions
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+
+	disableWriteTimeout(c)
+	setAuditMessage(c, "提交容器 "+containerID+" 为镜像 "+req.Reference)
+
+	imageID, err := svc.Commit(c.Request.Context(), containerID, req)
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	refreshInventory(c, inventory.TypeImage)
+	SuccessWithMessage(c, "容器已提交为镜像", gin.H{
+		"id":        imageID,
+		"reference": req.Reference,
+	})
+}
+
+// ExportContainer 将容器文件系统导出为 tar 下载
+func ExportContainer(c *gin.Context) {
 	containerID := c.Param("id")
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+
+	disableWriteTimeout(c)
+	setAuditMessage(c, "导出容器 "+containerID)
+
+	reader, err := svc.Export(c.Request.Context(), containerID)
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, -1, "application/x-tar", reader, map[string]string{
+		"Content-Disposition": attachmentHeader(containerID + ".tar"),
+	})
+}
+
+// BulkContainerAction 批量启动、停止、重启、删除、暂停或恢复容器
+// 通过 ids 或 label_selector 指定容器，返回每个容器的执行结果
+// host_id=all 或指定 group、tag、environment 时对所有匹配主机执行，此时只能使用 label_selector
+func BulkContainerAction(c *gin.Context) {
+	action := c.Param("action")
 	hostID := c.Query("host_id")
 
-	tail := c.DefaultQuery("tail", "100")
-	follow := c.Query("follow") == "true"
+	if !docker.ValidBulkAction(action) {
+		BadRequest(c, "不支持的批量操作: "+action)
+		return
+	}
+
+	var req struct {
+		docker.BulkOptions
+		IDs           []string `json:"ids"`
+		LabelSelector string   `json:"label_selector"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+	selectors := docker.ParseLabelSelector(req.LabelSelector)
+	if len(req.IDs) == 0 && len(selectors) == 0 {
+		BadRequest(c, "必须指定 ids 或 label_selector")
+		return
+	}
+
+	if isFleetQuery(c) {
+		if len(req.IDs) > 0 {
+			BadRequest(c, "多主机批量操作只能使用 label_selector 指定容器")
+			return
+		}
+		bulkAllHosts(c, action, selectors, req.BulkOptions)
+		return
+	}
 
 	host, err := getHost(hostID)
 	if err != nil {
@@ -266,32 +570,254 @@ func GetContainerLogs(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
-	svc := docker.NewContainerService(cli)
-	reader, err := svc.Logs(c.Request.Context(), containerID, containerTypes.LogsOptions{
-		ShowStdout: true,
-		ShowStderr: true,
-		Follow:     follow,
-		Tail:       tail,
+	disableWriteTimeout(c)
+
+	results, err := bulkContainers(c.Request.Context(), cli, action, req.IDs, selectors, req.BulkOptions)
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+	setAuditMessage(c, fmt.Sprintf("批量 %s %d 个容器", action, len(results)))
+	inventory.GetInventory().Invalidate(host, inventory.TypeContainer)
+
+	failed := countBulkFailed(results)
+	Success(c, gin.H{
+		"action":    action,
+		"total":     len(results),
+		"succeeded": len(results) - failed,
+		"failed":    failed,
+		"results":   results,
 	})
+}
+
+// hostBulkResult 单台主机的批量操作结果
+type hostBulkResult struct {
+	HostID   string              `json:"host_id"`
+	HostName string              `json:"host_name"`
+	Results  []docker.BulkResult `json:"results"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// bulkAllHosts 对所有匹配的启用主机并发执行批量操作
+func bulkAllHosts(c *gin.Context, action string, selectors []string, opts docker.BulkOptions) {
+	hosts, ok := fleetHosts(c)
+	if !ok {
+		return
+	}
+
+	disableWriteTimeout(c)
+
+	results := make([]hostBulkResult, len(hosts))
+	var wg sync.WaitGroup
+	for i := range hosts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			host := &hosts[i]
+			results[i] = hostBulkResult{HostID: host.ID, HostName: host.Name, Results: []docker.BulkResult{}}
+
+			cli, err := getClient(c.Request.Context(), host)
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			rows, err := bulkContainers(c.Request.Context(), cli, action, nil, selectors, opts)
+			inventory.GetInventory().Invalidate(host, inventory.TypeContainer)
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Results = rows
+		}(i)
+	}
+	wg.Wait()
+
+	total, failed, hostsFailed := 0, 0, 0
+	for _, r := range results {
+		total += len(r.Results)
+		failed += countBulkFailed(r.Results)
+		if r.Error != "" {
+			hostsFailed++
+		}
+	}
+	setAuditMessage(c, fmt.Sprintf("批量 %s %d 台主机上的 %d 个容器", action, len(hosts), total))
+
+	Success(c, gin.H{
+		"action":       action,
+		"total":        total,
+		"succeeded":    total - failed,
+		"failed":       failed,
+		"hosts":        len(hosts),
+		"hosts_failed": hostsFailed,
+		"results":      results,
+	})
+}
+
+// bulkContainers 合并 ids 与标签选择器匹配的容器并执行批量操作
+func bulkContainers(ctx context.Context, cli *client.Client, action string, idList, selectors []string, opts docker.BulkOptions) ([]docker.BulkResult, error) {
+	svc := docker.NewContainerService(cli)
+
+	// 去掉重复项
+	ids := make([]string, 0, len(idList))
+	seen := make(map[string]bool)
+	for _, id := range idList {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(selectors) > 0 {
+		containers, err := svc.ListByLabel(ctx, true, selectors...)
+		if err != nil {
+			return nil, err
+		}
+		for _, container := range containers {
+			if !seen[container.ID] {
+				seen[container.ID] = true
+				ids = append(ids, container.ID)
+			}
+		}
+	}
+
+	return svc.Bulk(ctx, action, ids, opts)
+}
+
+// countBulkFailed 统计失败的操作数
+func countBulkFailed(results []docker.BulkResult) int {
+	failed := 0
+	for _, r := range results {
+		if !r.Success {
+			failed++
+		}
+	}
+	return failed
+}
+
+// RecreateContainer 按修改后的配置重建容器
+// 新容器启动失败或在观察期内退出、健康检查失败时，自动回滚到旧容器
+func RecreateContainer(c *gin.Context) {
+	containerID := c.Param("id")
+	hostID := c.Query("host_id")
+
+	var req struct {
+		docker.RecreateOptions
+		// Wait 启动后观察新容器的秒数，0 表示不观察
+		Wait int `json:"wait"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+	if req.Wait < 0 || req.Wait > 600 {
+		BadRequest(c, "wait 必须在 0-600 秒之间")
+		return
+	}
+	if err := req.ContainerPatch.Validate(); err != nil {
+		FailWithStatus(c, http.StatusBadRequest, CodeInvalidConfig, "无效的容器配置: "+err.Error())
+		return
+	}
+
+	host, err := getHost(hostID)
 	if err != nil {
-		ServerError(c, "获取容器日志失败: "+err.Error())
+		ServerError(c, "获取主机失败: "+err.Error())
 		return
 	}
-	defer reader.Close()
 
-	// 读取日志
-	logs, err := io.ReadAll(reader)
+	cli, err := getClient(c.Request.Context(), host)
+	if err != nil {
+		clientError(c, err)
+		return
+	}
+
+	// 拉取镜像、停止容器和观察期可能超过服务器的写超时
+	disableWriteTimeout(c)
+
+	ctx := c.Request.Context()
+	if req.Image != "" {
+		imageSvc := docker.NewImageService(cli)
+		if _, err := imageSvc.Get(ctx, req.Image); err != nil {
+			if err := imageSvc.PullAndWait(ctx, docker.PullOptions{Image: req.Image}); err != nil {
+				ServerError(c, err.Error())
+				return
+			}
+		}
+	}
+
+	setAuditMessage(c, "重建容器 "+containerID)
+	svc := docker.NewContainerService(cli)
+	result, err := svc.Recreate(ctx, containerID, req.RecreateOptions)
 	if err != nil {
-		ServerError(c, "读取日志失败: "+err.Error())
+		ServerError(c, "重建容器失败: "+err.Error())
 		return
 	}
 
+	if result.WasRunning && req.Wait > 0 {
+		if err := svc.WaitHealthy(ctx, result.NewID, time.Duration(req.Wait)*time.Second); err != nil {
+			// 使用独立的 context，避免客户端断开导致回滚中断
+			rbCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			if rbErr := svc.RollbackRecreate(rbCtx, result); rbErr != nil {
+				ServerError(c, "新容器运行异常: "+err.Error()+"，回滚失败: "+rbErr.Error())
+				return
+			}
+			ServerError(c, "新容器运行异常，已回滚到旧容器: "+err.Error())
+			return
+		}
+	}
+
+	err = svc.CommitRecreate(ctx, result)
+	inventory.GetInventory().Invalidate(host, inventory.TypeContainer, inventory.TypeImage)
+	if err != nil {
+		SuccessWithMessage(c, "容器已重建，但"+err.Error(), result)
+		return
+	}
+
+	SuccessWithMessage(c, "容器重建成功", result)
+}
+
+// GetContainerLogs 获取容器日志
+func GetContainerLogs(c *gin.Context) {
+	containerID := c.Param("id")
+
+	opts, ok := logOptionsFromQuery(c, "100")
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "1000"))
+	if err != nil || limit <= 0 || limit > maxLogLines {
+		BadRequest(c, fmt.Sprintf("limit 必须在 1-%d 之间", maxLogLines))
+		return
+	}
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+
+	lines, truncated, err := svc.ReadLogs(c.Request.Context(), containerID, opts, limit)
+	if err != nil {
+		ServerError(c, "获取容器日志失败: "+err.Error())
+		return
+	}
+
+	text := make([]string, len(lines))
+	for i, line := range lines {
+		text[i] = line.String()
+	}
+	logs := strings.Join(text, "\n")
+	if logs != "" {
+		logs += "\n"
+	}
+
 	Success(c, gin.H{
-		"logs": string(logs),
+		"logs":      logs,
+		"lines":     lines,
+		"truncated": truncated,
 	})
 }
 
@@ -308,7 +834,7 @@ func GetContainerStats(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -347,7 +873,7 @@ func ExecContainer(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -357,6 +883,11 @@ func ExecContainer(c *gin.Context) {
 		ServerError(c, "创建执行命令失败: "+err.Error())
 		return
 	}
+	recording.RegisterExec(resp.ID, recording.ExecInfo{
+		ContainerID: containerID,
+		Cmd:         req.Cmd,
+		User:        req.User,
+	})
 
 	Success(c, gin.H{
 		"id": resp.ID,
@@ -373,11 +904,14 @@ func getHost(hostID string) (*model.Host, error) {
 
 // getClient 获取 Docker 客户端
 func getClient(ctx context.Context, host *model.Host) (*client.Client, error) {
-	conn, err := docker.GetManager().GetClient(ctx, host)
-	if err != nil {
-		return nil, err
-	}
+	return docker.GetManager().GetDockerClient(ctx, host)
+}
 
-	// 通过 Connection 接口的 Connect 方法获取客户端
-	return conn.Connect(ctx)
+// clientError 响应获取 Docker 客户端失败，主机已知不可用时返回 503
+func clientError(c *gin.Context, err error) {
+	if errors.Is(err, docker.ErrHostDown) {
+		FailWithStatus(c, http.StatusServiceUnavailable, CodeHostDown, err.Error())
+		return
+	}
+	ServerError(c, "获取 Docker 客户端失败: "+err.Error())
 }
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"compress/gzip"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"rubick/internal/docker"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxLogLines 单次查询最多返回的日志行数
+const maxLogLines = 10000
+
+// logOptionsFromQuery 从查询参数解析日志选项，参数无效时返回 400
+// 未指定 tail 时，按时间范围或内容过滤的查询读取全部日志，否则使用 defaultTail
+func logOptionsFromQuery(c *gin.Context, defaultTail string) (docker.LogOptions, bool) {
+	opts := docker.LogOptions{
+		Since:      c.Query("since"),
+		Until:      c.Query("until"),
+		Tail:       c.Query("tail"),
+		Timestamps: c.Query("timestamps") == "true",
+		Stdout:     c.Query("stdout") == "true",
+		Stderr:     c.Query("stderr") == "true",
+		Grep:       c.Query("grep"),
+		Regex:      c.Query("regex") == "true",
+		IgnoreCase: c.Query("ignore_case") == "true",
+	}
+
+	if opts.Tail == "" {
+		opts.Tail = defaultTail
+		if opts.Since != "" || opts.Until != "" || opts.Grep != "" {
+			opts.Tail = "all"
+		}
+	}
+	if opts.Tail != "all" {
+		if n, err := strconv.Atoi(opts.Tail); err != nil || n < 0 {
+			BadRequest(c, "tail 必须是非负整数或 all")
+			return opts, false
+		}
+	}
+
+	if err := opts.Validate(); err != nil {
+		BadRequest(c, err.Error())
+		return opts, false
+	}
+	return opts, true
+}
+
+// DownloadContainerLogs 以 gzip 文件下载容器日志
+// 默认下载全部日志，支持与查询日志相同的过滤参数
+func DownloadContainerLogs(c *gin.Context) {
+	containerID := c.Param("id")
+
+	opts, ok := logOptionsFromQuery(c, "all")
+	if !ok {
+		return
+	}
+
+	svc, ok := getContainerService(c)
+	if !ok {
+		return
+	}
+
+	// 先确认容器存在，开始写入响应后就无法再返回错误
+	info, err := svc.Get(c.Request.Context(), containerID)
+	if err != nil {
+		NotFound(c, "容器不存在: "+err.Error())
+		return
+	}
+	name := strings.TrimPrefix(info.Name, "/")
+	if name == "" {
+		name = containerID
+	}
+
+	disableWriteTimeout(c)
+	setAuditMessage(c, "下载容器日志: "+name)
+
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", attachmentHeader(name+"-logs.txt.gz"))
+	c.Status(http.StatusOK)
+
+	gz := gzip.NewWriter(c.Writer)
+	err = svc.StreamLogs(c.Request.Context(), containerID, opts, func(line docker.LogLine) error {
+		_, err := gz.Write([]byte(line.String() + "\n"))
+		return err
+	})
+	if err != nil && c.Request.Context().Err() == nil {
+		// 响应头已发送，只能记录错误并截断文件
+		log.Printf("下载容器 %s 日志失败: %v", containerID, err)
+	}
+	gz.Close()
+}
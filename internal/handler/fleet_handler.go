@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"rubick/internal/model"
+	"rubick/internal/repository"
+
+	"github.com/docker/docker/client"
+	"github.com/gin-gonic/gin"
+)
+
+// allHosts host_id 取该值时汇总所有启用主机的数据
+const allHosts = "all"
+
+// fleetHostTimeout 汇总查询时单台主机的超时时间
+const fleetHostTimeout = 10 * time.Second
+
+// hostRow 带主机标识的列表项，序列化时主机字段与原对象字段合并
+type hostRow struct {
+	HostID   string
+	HostName string
+	Item     interface{}
+}
+
+// MarshalJSON 在原对象的字段前加入 host_id 和 host_name
+func (r hostRow) MarshalJSON() ([]byte, error) {
+	item, err := json.Marshal(r.Item)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := json.Marshal(map[string]string{"host_id": r.HostID, "host_name": r.HostName})
+	if err != nil {
+		return nil, err
+	}
+
+	item = bytes.TrimSpace(item)
+	if len(item) < 2 || item[0] != '{' {
+		// 非对象类型无法合并字段，放入 item 中
+		return json.Marshal(map[string]interface{}{"host_id": r.HostID, "host_name": r.HostName, "item": r.Item})
+	}
+	body := bytes.TrimSpace(item[1 : len(item)-1])
+	if len(body) == 0 {
+		return tags, nil
+	}
+
+	var buf bytes.Buffer
+	buf.Write(tags[:len(tags)-1])
+	buf.WriteByte(',')
+	buf.Write(body)
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// hostError 汇总查询中单台主机的错误
+type hostError struct {
+	HostID   string `json:"host_id"`
+	HostName string `json:"host_name"`
+	Error    string `json:"error"`
+}
+
+// fleetResult 汇总查询结果，部分主机失败时仍返回其余主机的数据
+type fleetResult struct {
+	Items  []hostRow   `json:"items"`
+	Errors []hostError `json:"errors"`
+	Hosts  int         `json:"hosts"` // 参与查询的主机数
+}
+
+// isFleetQuery 判断请求是否针对多台主机
+// host_id=all，或未指定 host_id 但指定了 group、tag、environment 筛选条件
+func isFleetQuery(c *gin.Context) bool {
+	hostID := c.Query("host_id")
+	if hostID == allHosts {
+		return true
+	}
+	return hostID == "" && (c.Query("group") != "" || c.Query("tag") != "" || c.Query("environment") != "")
+}
+
+// hostFilterFromQuery 从查询参数解析主机筛选条件
+// group 可以是分组 ID 或名称，tag 可重复或以逗号分隔，需同时包含全部标签
+func hostFilterFromQuery(c *gin.Context) (repository.HostFilter, error) {
+	filter := repository.HostFilter{Environment: c.Query("environment")}
+
+	var tags []string
+	for _, v := range c.QueryArray("tag") {
+		tags = append(tags, strings.Split(v, ",")...)
+	}
+	filter.Tags = model.NormalizeTags(tags)
+
+	if name := c.Query("group"); name != "" {
+		group, err := repository.GetHostGroup(name)
+		if err != nil {
+			return filter, fmt.Errorf("主机分组不存在: %s", name)
+		}
+		filter.GroupID = group.ID
+	}
+	return filter, nil
+}
+
+// fleetHosts 获取多主机请求涉及的启用主机，失败时写入错误响应
+func fleetHosts(c *gin.Context) ([]model.Host, bool) {
+	filter, err := hostFilterFromQuery(c)
+	if err != nil {
+		BadRequest(c, err.Error())
+		return nil, false
+	}
+
+	hosts, err := repository.FilterHosts(filter)
+	if err != nil {
+		ServerError(c, "获取主机列表失败: "+err.Error())
+		return nil, false
+	}
+
+	active := make([]model.Host, 0, len(hosts))
+	for _, host := range hosts {
+		if host.IsActive {
+			active = append(active, host)
+		}
+	}
+	return active, true
+}
+
+// listAllHosts 并发查询所有匹配的启用主机并合并结果，每台主机单独计算超时
+func listAllHosts[T any](c *gin.Context, list func(ctx context.Context, cli *client.Client) ([]T, error)) {
+	active, ok := fleetHosts(c)
+	if !ok {
+		return
+	}
+
+	// 按主机顺序保存结果，保证输出顺序稳定
+	rows := make([][]T, len(active))
+	errs := make([]error, len(active))
+
+	var wg sync.WaitGroup
+	for i := range active {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(c.Request.Context(), fleetHostTimeout)
+			defer cancel()
+
+			cli, err := getClient(ctx, &active[i])
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			rows[i], errs[i] = list(ctx, cli)
+		}(i)
+	}
+	wg.Wait()
+
+	result := fleetResult{
+		Items:  []hostRow{},
+		Errors: []hostError{},
+		Hosts:  len(active),
+	}
+	for i, host := range active {
+		if errs[i] != nil {
+			result.Errors = append(result.Errors, hostError{
+				HostID:   host.ID,
+				HostName: host.Name,
+				Error:    errs[i].Error(),
+			})
+			continue
+		}
+		for _, item := range rows[i] {
+			result.Items = append(result.Items, hostRow{HostID: host.ID, HostName: host.Name, Item: item})
+		}
+	}
+
+	Success(c, result)
+}
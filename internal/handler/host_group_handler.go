@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"rubick/internal/model"
+	"rubick/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// hostGroupRequest 创建/更新主机分组的请求参数，指针字段为空表示不修改
+type hostGroupRequest struct {
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	HostIDs     *[]string `json:"host_ids"`
+}
+
+// apply 将请求参数应用到主机分组
+func (r *hostGroupRequest) apply(g *model.HostGroup) {
+	setIfPresent(&g.Name, r.Name)
+	setIfPresent(&g.Description, r.Description)
+	setIfPresent(&g.HostIDs, r.HostIDs)
+	g.Name = strings.TrimSpace(g.Name)
+}
+
+// validateHostGroup 校验主机分组，成员主机必须存在，重复的主机只保留一个
+func validateHostGroup(g *model.HostGroup) string {
+	if g.Name == "" {
+		return "名称不能为空"
+	}
+	if g.Name == allHosts {
+		return "名称不能为 " + allHosts
+	}
+	if existing, err := repository.GetHostGroup(g.Name); err == nil && existing.ID != g.ID {
+		return "分组名称已存在: " + g.Name
+	}
+
+	hostIDs := make([]string, 0, len(g.HostIDs))
+	seen := make(map[string]bool)
+	for _, id := range g.HostIDs {
+		if seen[id] {
+			continue
+		}
+		if _, err := repository.GetHostByID(id); err != nil {
+			return "主机不存在: " + id
+		}
+		seen[id] = true
+		hostIDs = append(hostIDs, id)
+	}
+	g.HostIDs = hostIDs
+	return ""
+}
+
+// ListHostGroups 列出主机分组
+func ListHostGroups(c *gin.Context) {
+	groups, err := repository.ListHostGroups()
+	if err != nil {
+		ServerError(c, "获取主机分组失败: "+err.Error())
+		return
+	}
+	Success(c, groups)
+}
+
+// GetHostGroup 获取主机分组详情，id 可以是分组 ID 或名称
+func GetHostGroup(c *gin.Context) {
+	group, err := repository.GetHostGroup(c.Param("id"))
+	if err != nil {
+		NotFound(c, "主机分组不存在")
+		return
+	}
+	Success(c, group)
+}
+
+// CreateHostGroup 创建主机分组
+func CreateHostGroup(c *gin.Context) {
+	var req hostGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	group := model.HostGroup{HostIDs: []string{}}
+	req.apply(&group)
+	if msg := validateHostGroup(&group); msg != "" {
+		BadRequest(c, msg)
+		return
+	}
+
+	if err := repository.CreateHostGroup(&group); err != nil {
+		ServerError(c, "创建主机分组失败: "+err.Error())
+		return
+	}
+	SuccessWithMessage(c, "主机分组创建成功", group)
+}
+
+// UpdateHostGroup 更新主机分组，传入 host_ids 时替换全部成员
+func UpdateHostGroup(c *gin.Context) {
+	group, err := repository.GetHostGroup(c.Param("id"))
+	if err != nil {
+		NotFound(c, "主机分组不存在")
+		return
+	}
+
+	var req hostGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+	req.apply(group)
+	if msg := validateHostGroup(group); msg != "" {
+		BadRequest(c, msg)
+		return
+	}
+
+	if err := repository.SaveHostGroup(group); err != nil {
+		ServerError(c, "更新主机分组失败: "+err.Error())
+		return
+	}
+	SuccessWithMessage(c, "主机分组更新成功", group)
+}
+
+// DeleteHostGroup 删除主机分组，被通知 Webhook 引用时不允许删除
+func DeleteHostGroup(c *gin.Context) {
+	group, err := repository.GetHostGroup(c.Param("id"))
+	if err != nil {
+		NotFound(c, "主机分组不存在")
+		return
+	}
+
+	count, err := repository.CountNotificationHooksByGroup(group.ID)
+	if err != nil {
+		ServerError(c, "检查分组引用失败: "+err.Error())
+		return
+	}
+	if count > 0 {
+		BadRequest(c, fmt.Sprintf("分组被 %d 个通知 Webhook 引用，请先修改这些 Webhook", count))
+		return
+	}
+
+	if err := repository.DeleteHostGroup(group.ID); err != nil {
+		ServerError(c, "删除主机分组失败: "+err.Error())
+		return
+	}
+	SuccessWithMessage(c, "主机分组删除成功", nil)
+}
@@ -1,16 +1,27 @@
 package handler
 
 import (
+	"context"
+	"strconv"
+	"time"
+
 	"rubick/internal/docker"
 	"rubick/internal/model"
+	"rubick/internal/monitor"
 	"rubick/internal/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
-// ListHosts 列出所有主机
+// ListHosts 列出所有主机，可按 group、tag、environment 筛选
 func ListHosts(c *gin.Context) {
-	hosts, err := repository.ListHosts()
+	filter, err := hostFilterFromQuery(c)
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	hosts, err := repository.FilterHosts(filter)
 	if err != nil {
 		ServerError(c, "获取主机列表失败: "+err.Error())
 		return
@@ -35,6 +46,16 @@ func CreateHost(c *gin.Context) {
 		BadRequest(c, "无效的主机类型，必须是 local、tcp 或 ssh")
 		return
 	}
+	// 主机信息和健康状态只能通过连接主机获取
+	host.Info = nil
+	host.Status = monitor.StatusUnknown
+	host.LastSeen = nil
+	host.LastError = ""
+	host.LatencyMs = 0
+	host.Tags = model.NormalizeTags(host.Tags)
+	if host.RecordingRetentionDays != nil && *host.RecordingRetentionDays < 0 {
+		host.RecordingRetentionDays = nil
+	}
 
 	// 如果设为默认，取消其他默认主机
 	if host.IsDefault {
@@ -73,6 +94,10 @@ func UpdateHost(c *gin.Context) {
 		return
 	}
 
+	if updates.Tags != nil {
+		updates.Tags = model.NormalizeTags(updates.Tags)
+	}
+
 	// 如果设为默认，取消其他默认主机
 	if updates.IsDefault {
 		repository.ClearDefaultHost()
@@ -130,8 +155,10 @@ func TestHostConnection(c *gin.Context) {
 		return
 	}
 
-	// 测试连接
+	// 测试连接，结果同时计入健康检查状态
+	start := time.Now()
 	err = docker.GetManager().TestConnection(c.Request.Context(), host)
+	monitor.GetMonitor().Record(host, time.Since(start), err)
 	if err != nil {
 		Success(c, gin.H{
 			"success": false,
@@ -141,9 +168,95 @@ func TestHostConnection(c *gin.Context) {
 		return
 	}
 
+	// 连接成功时顺便刷新主机信息快照
+	refreshHostInfo(c.Request.Context(), host)
+
 	Success(c, gin.H{
 		"success": true,
 		"message": "连接成功",
 		"host":    host.Name,
 	})
 }
+
+// ListHostStatusHistory 获取主机的健康检查记录
+// transitions=true 时只返回状态变化的记录
+func ListHostStatusHistory(c *gin.Context) {
+	id := c.Param("id")
+
+	host, err := repository.GetHostByID(id)
+	if err != nil {
+		NotFound(c, "主机不存在")
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	checks, err := repository.ListHostChecks(id, c.Query("transitions") == "true", limit)
+	if err != nil {
+		ServerError(c, "获取主机检查记录失败: "+err.Error())
+		return
+	}
+
+	Success(c, gin.H{
+		"host_id":    host.ID,
+		"status":     host.Status,
+		"last_seen":  host.LastSeen,
+		"last_error": host.LastError,
+		"latency_ms": host.LatencyMs,
+		"history":    checks,
+	})
+}
+
+// GetHostInfo 获取主机的 Docker 版本和系统信息
+// 主机无法连接时返回最近一次成功获取的快照，online 为 false
+func GetHostInfo(c *gin.Context) {
+	id := c.Param("id")
+
+	host, err := repository.GetHostByID(id)
+	if err != nil {
+		NotFound(c, "主机不存在")
+		return
+	}
+
+	info, err := refreshHostInfo(c.Request.Context(), host)
+	if err != nil {
+		Success(c, gin.H{
+			"online":  false,
+			"error":   err.Error(),
+			"info":    host.Info,
+			"host_id": host.ID,
+		})
+		return
+	}
+
+	Success(c, gin.H{
+		"online":  true,
+		"info":    info,
+		"host_id": host.ID,
+	})
+}
+
+// refreshHostInfo 从主机获取 Docker 信息并保存快照
+func refreshHostInfo(ctx context.Context, host *model.Host) (*model.HostInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	cli, err := getClient(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := docker.NewSystemService(cli).Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repository.UpdateHostInfo(host.ID, info); err != nil {
+		return nil, err
+	}
+	host.Info = info
+	return info, nil
+}
@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"context"
+	"net/http"
+	"strings"
+
 	"rubick/internal/docker"
+	"rubick/internal/inventory"
 
+	"github.com/docker/docker/client"
 	"github.com/gin-gonic/gin"
 )
 
@@ -11,15 +17,27 @@ func ListImages(c *gin.Context) {
 	hostID := c.Query("host_id")
 	all := c.Query("all") == "true"
 
+	if isFleetQuery(c) {
+		listAllHosts(c, func(ctx context.Context, cli *client.Client) ([]docker.ImageInfo, error) {
+			return docker.NewImageService(cli).List(ctx, all)
+		})
+		return
+	}
+
 	host, err := getHost(hostID)
 	if err != nil {
 		ServerError(c, "获取主机失败: "+err.Error())
 		return
 	}
 
+	// 缓存中不包含中间层镜像
+	if !all && serveInventory(c, host.ID, "images", func(s *inventory.Snapshot) interface{} { return s.Images }) {
+		return
+	}
+
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -118,3 +136,89 @@ func SearchImages(c *gin.Context) {
 
 	Success(c, []interface{}{})
 }
+
+// getImageService 根据请求的 host_id 创建镜像服务，失败时写入错误响应
+func getImageService(c *gin.Context) (*docker.ImageService, bool) {
+	host, err := getHost(c.Query("host_id"))
+	if err != nil {
+		ServerError(c, "获取主机失败: "+err.Error())
+		return nil, false
+	}
+
+	cli, err := getClient(c.Request.Context(), host)
+	if err != nil {
+		clientError(c, err)
+		return nil, false
+	}
+
+	return docker.NewImageService(cli), true
+}
+
+// SaveImages 将一个或多个镜像保存为 tar 下载
+// 镜像通过 images 参数指定，多个镜像以逗号分隔或重复传参
+func SaveImages(c *gin.Context) {
+	var images []string
+	for _, value := range c.QueryArray("images") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				images = append(images, name)
+			}
+		}
+	}
+	if len(images) == 0 {
+		BadRequest(c, "images 参数不能为空")
+		return
+	}
+
+	svc, ok := getImageService(c)
+	if !ok {
+		return
+	}
+
+	disableWriteTimeout(c)
+	setAuditMessage(c, "保存镜像 "+strings.Join(images, ", "))
+
+	reader, err := svc.Save(c.Request.Context(), images)
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+	defer reader.Close()
+
+	filename := "images.tar"
+	if len(images) == 1 {
+		filename = strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(images[0]) + ".tar"
+	}
+	c.DataFromReader(http.StatusOK, -1, "application/x-tar", reader, map[string]string{
+		"Content-Disposition": attachmentHeader(filename),
+	})
+}
+
+// LoadImages 从上传的 tar 加载镜像
+// 请求体可以直接是 tar（application/x-tar），也可以是包含 file 字段的 multipart 表单；
+// 两种方式都以流的形式转发给 Docker，不在服务端缓存
+func LoadImages(c *gin.Context) {
+	svc, ok := getImageService(c)
+	if !ok {
+		return
+	}
+
+	disableReadTimeout(c)
+	disableWriteTimeout(c)
+
+	input, err := uploadReader(c, "file")
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	result, err := svc.Load(c.Request.Context(), input)
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	setAuditMessage(c, "加载镜像 "+strings.Join(result.Images, ", "))
+	refreshInventory(c, inventory.TypeImage)
+	SuccessWithMessage(c, "镜像加载成功", result)
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"rubick/internal/docker"
+	"rubick/internal/inventory"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serveInventory 从资源清单缓存响应列表请求，缓存不是最新时返回 false，由调用方直接查询 Docker
+// 响应带有按返回内容生成的 ETag，If-None-Match 匹配时返回 304
+// fresh=true 时跳过缓存
+func serveInventory(c *gin.Context, hostID, variant string, data func(s *inventory.Snapshot) interface{}) bool {
+	if c.Query("fresh") == "true" {
+		return false
+	}
+	snapshot, live := inventory.GetInventory().Get(hostID)
+	if !live {
+		return false
+	}
+
+	body := data(&snapshot)
+	etag := contentETag(variant, body)
+	c.Header("ETag", etag)
+	c.Header("X-Inventory-Revision", strconv.FormatUint(snapshot.Revision, 10))
+	if etagMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+
+	Success(c, body)
+	return true
+}
+
+// contentETag 按响应内容生成弱 ETag
+// 清单版本不包含状态描述等随时间变化的字段，不能单独作为 ETag
+func contentETag(variant string, body interface{}) string {
+	data, _ := json.Marshal(body)
+	h := fnv.New64a()
+	h.Write(data)
+	return fmt.Sprintf(`W/"%s-%x"`, variant, h.Sum64())
+}
+
+// refreshInventory 修改资源后标记主机清单过期，并在后台刷新受影响的资源类型
+func refreshInventory(c *gin.Context, kinds ...string) {
+	host, err := getHost(c.Query("host_id"))
+	if err != nil {
+		return
+	}
+	inventory.GetInventory().Invalidate(host, kinds...)
+}
+
+// etagMatch 判断 If-None-Match 是否包含指定 ETag，按弱比较处理
+func etagMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
+
+// runningContainers 只保留未停止的容器，与 docker ps 不带 -a 的结果一致
+func runningContainers(containers []docker.ContainerInfo) []docker.ContainerInfo {
+	result := make([]docker.ContainerInfo, 0, len(containers))
+	for _, c := range containers {
+		switch c.State {
+		case "created", "exited", "dead":
+			continue
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// GetInventory 获取主机的完整资源清单
+// 清单尚未同步时立即同步一次
+func GetInventory(c *gin.Context) {
+	host, err := getHost(c.Query("host_id"))
+	if err != nil {
+		ServerError(c, "获取主机失败: "+err.Error())
+		return
+	}
+
+	inv := inventory.GetInventory()
+	snapshot, _ := inv.Get(host.ID)
+	if snapshot.RefreshedAt.IsZero() {
+		if err := inv.RefreshHost(c.Request.Context(), host); err != nil {
+			clientError(c, err)
+			return
+		}
+		snapshot, _ = inv.Get(host.ID)
+	}
+
+	etag := contentETag("inventory", snapshot)
+	c.Header("ETag", etag)
+	if etagMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	Success(c, snapshot)
+}
+
+// ListInventoryChanges 获取主机清单在 since 版本之后的变更
+// 变更记录不完整时 resync 为 true，客户端需要重新获取完整清单
+func ListInventoryChanges(c *gin.Context) {
+	host, err := getHost(c.Query("host_id"))
+	if err != nil {
+		ServerError(c, "获取主机失败: "+err.Error())
+		return
+	}
+
+	since, err := strconv.ParseUint(c.Query("since"), 10, 64)
+	if err != nil {
+		BadRequest(c, "since 必须是有效的版本号")
+		return
+	}
+
+	changes, revision, complete := inventory.GetInventory().Changes(host.ID, since)
+	if !complete {
+		Success(c, gin.H{
+			"host_id":  host.ID,
+			"revision": revision,
+			"resync":   true,
+			"changes":  []inventory.Change{},
+		})
+		return
+	}
+
+	Success(c, gin.H{
+		"host_id":  host.ID,
+		"revision": revision,
+		"resync":   false,
+		"changes":  changes,
+	})
+}
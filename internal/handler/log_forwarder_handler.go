@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"log"
+	"strings"
+
+	"rubick/internal/logstore"
+	"rubick/internal/model"
+	"rubick/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// logForwarderRequest 创建/更新日志转发器的请求参数，指针字段为空表示不修改
+type logForwarderRequest struct {
+	Name         *string            `json:"name"`
+	Type         *string            `json:"type"`
+	URL          *string            `json:"url"`
+	Selector     *string            `json:"selector"`
+	Projects     *string            `json:"projects"`
+	HostID       *string            `json:"host_id"`
+	Headers      *map[string]string `json:"headers"`
+	Labels       *map[string]string `json:"labels"`
+	BatchSize    *int               `json:"batch_size"`
+	FlushSeconds *int               `json:"flush_seconds"`
+	Enabled      *bool              `json:"enabled"`
+}
+
+// apply 将请求参数应用到转发器
+// 响应中请求头的值被清空，更新时值为空的请求头保留原值
+func (r *logForwarderRequest) apply(f *model.LogForwarder) {
+	setIfPresent(&f.Name, r.Name)
+	setIfPresent(&f.Type, r.Type)
+	setIfPresent(&f.URL, r.URL)
+	setIfPresent(&f.Selector, r.Selector)
+	setIfPresent(&f.Projects, r.Projects)
+	setIfPresent(&f.HostID, r.HostID)
+	setIfPresent(&f.Labels, r.Labels)
+	setIfPresent(&f.BatchSize, r.BatchSize)
+	setIfPresent(&f.FlushSeconds, r.FlushSeconds)
+	setIfPresent(&f.Enabled, r.Enabled)
+
+	if r.Headers != nil {
+		headers := make(map[string]string, len(*r.Headers))
+		for k, v := range *r.Headers {
+			if v == "" {
+				v = f.Headers[k]
+			}
+			headers[k] = v
+		}
+		f.Headers = headers
+	}
+	f.Name = strings.TrimSpace(f.Name)
+}
+
+// validateLogForwarder 校验转发器配置
+func validateLogForwarder(f *model.LogForwarder) string {
+	if f.Name == "" {
+		return "名称不能为空"
+	}
+	switch f.Type {
+	case model.LogForwarderSyslog, model.LogForwarderLoki, model.LogForwarderHTTP:
+	default:
+		return "type 必须是 syslog、loki 或 http"
+	}
+	if strings.TrimSpace(f.Selector) == "" && strings.TrimSpace(f.Projects) == "" {
+		return "selector 和 projects 至少指定一个"
+	}
+	if err := logstore.ValidateForwarder(f); err != nil {
+		return err.Error()
+	}
+	if f.HostID != "" {
+		if _, err := repository.GetHostByID(f.HostID); err != nil {
+			return "主机不存在"
+		}
+	}
+	if f.BatchSize < 1 || f.BatchSize > 5000 {
+		return "batch_size 必须在 1-5000 之间"
+	}
+	if f.FlushSeconds < 1 || f.FlushSeconds > 300 {
+		return "flush_seconds 必须在 1-300 之间"
+	}
+	return ""
+}
+
+// reloadLogForwarders 转发器变更后重新加载
+func reloadLogForwarders() {
+	if err := logstore.GetCollector().Reload(); err != nil {
+		log.Printf("重新加载日志转发器失败: %v", err)
+	}
+}
+
+// ListLogForwarders 列出日志转发器及运行状态
+func ListLogForwarders(c *gin.Context) {
+	forwarders, err := repository.ListLogForwarders()
+	if err != nil {
+		ServerError(c, "获取日志转发器失败: "+err.Error())
+		return
+	}
+	for i := range forwarders {
+		forwarders[i].Status = logstore.GetCollector().ForwarderStatus(forwarders[i].ID)
+		forwarders[i].ClearSensitiveFields()
+	}
+	Success(c, forwarders)
+}
+
+// GetLogForwarder 获取日志转发器详情及运行状态
+func GetLogForwarder(c *gin.Context) {
+	forwarder, err := repository.GetLogForwarderByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "日志转发器不存在")
+		return
+	}
+	forwarder.Status = logstore.GetCollector().ForwarderStatus(forwarder.ID)
+	forwarder.ClearSensitiveFields()
+	Success(c, forwarder)
+}
+
+// CreateLogForwarder 创建日志转发器
+func CreateLogForwarder(c *gin.Context) {
+	var req logForwarderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	forwarder := model.LogForwarder{BatchSize: 100, FlushSeconds: 2, Enabled: true}
+	req.apply(&forwarder)
+	if msg := validateLogForwarder(&forwarder); msg != "" {
+		BadRequest(c, msg)
+		return
+	}
+
+	if err := repository.CreateLogForwarder(&forwarder); err != nil {
+		ServerError(c, "创建日志转发器失败: "+err.Error())
+		return
+	}
+	reloadLogForwarders()
+
+	forwarder.ClearSensitiveFields()
+	SuccessWithMessage(c, "日志转发器创建成功", forwarder)
+}
+
+// UpdateLogForwarder 更新日志转发器，运行中的转发器会以新配置重新启动
+func UpdateLogForwarder(c *gin.Context) {
+	forwarder, err := repository.GetLogForwarderByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "日志转发器不存在")
+		return
+	}
+
+	var req logForwarderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+	req.apply(forwarder)
+	if msg := validateLogForwarder(forwarder); msg != "" {
+		BadRequest(c, msg)
+		return
+	}
+
+	if err := repository.SaveLogForwarder(forwarder); err != nil {
+		ServerError(c, "更新日志转发器失败: "+err.Error())
+		return
+	}
+	reloadLogForwarders()
+
+	forwarder.ClearSensitiveFields()
+	SuccessWithMessage(c, "日志转发器更新成功", forwarder)
+}
+
+// DeleteLogForwarder 删除日志转发器
+func DeleteLogForwarder(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := repository.GetLogForwarderByID(id); err != nil {
+		NotFound(c, "日志转发器不存在")
+		return
+	}
+
+	if err := repository.DeleteLogForwarder(id); err != nil {
+		ServerError(c, "删除日志转发器失败: "+err.Error())
+		return
+	}
+	reloadLogForwarders()
+
+	SuccessWithMessage(c, "日志转发器删除成功", nil)
+}
+
+// TestLogForwarder 向转发目标发送一条测试日志
+func TestLogForwarder(c *gin.Context) {
+	forwarder, err := repository.GetLogForwarderByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "日志转发器不存在")
+		return
+	}
+
+	if err := logstore.TestForwarder(c.Request.Context(), forwarder); err != nil {
+		Success(c, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	Success(c, gin.H{
+		"success": true,
+		"message": "发送成功",
+	})
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"rubick/internal/docker"
+	"rubick/internal/logstore"
+	"rubick/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxLogEntries 单次查询集中日志最多返回的行数
+const maxLogEntries = 5000
+
+// ListLogs 查询集中存储的日志
+// 支持按主机、容器（ID 前缀或名称）、Compose 项目和服务、输出流、时间范围和内容过滤
+// 结果按时间顺序排列，使用 before 向前翻页，使用 after 获取新日志
+func ListLogs(c *gin.Context) {
+	q := repository.LogQuery{
+		HostID:    c.Query("host_id"),
+		Container: strings.TrimSpace(c.Query("container")),
+		Project:   c.Query("project"),
+		Service:   c.Query("service"),
+		Stream:    c.Query("stream"),
+		Text:      c.Query("q"),
+	}
+
+	if q.Stream != "" && q.Stream != docker.StreamStdout && q.Stream != docker.StreamStderr {
+		BadRequest(c, "stream 必须是 stdout 或 stderr")
+		return
+	}
+
+	var err error
+	if q.Since, err = parseTimeQuery(c.Query("since")); err != nil {
+		BadRequest(c, "无效的 since: "+err.Error())
+		return
+	}
+	if q.Until, err = parseTimeQuery(c.Query("until")); err != nil {
+		BadRequest(c, "无效的 until: "+err.Error())
+		return
+	}
+	if q.BeforeID, err = parseCursor(c.Query("before")); err != nil {
+		BadRequest(c, "无效的 before")
+		return
+	}
+	if q.AfterID, err = parseCursor(c.Query("after")); err != nil {
+		BadRequest(c, "无效的 after")
+		return
+	}
+
+	q.Limit, err = strconv.Atoi(c.DefaultQuery("limit", "500"))
+	if err != nil || q.Limit <= 0 || q.Limit > maxLogEntries {
+		BadRequest(c, fmt.Sprintf("limit 必须在 1-%d 之间", maxLogEntries))
+		return
+	}
+
+	// 多读取一行判断是否还有更多日志
+	limit := q.Limit
+	q.Limit++
+	entries, err := repository.ListLogEntries(q)
+	if err != nil {
+		ServerError(c, "查询日志失败: "+err.Error())
+		return
+	}
+
+	more := len(entries) > limit
+	if more {
+		if q.AfterID > 0 {
+			entries = entries[:limit]
+		} else {
+			entries = entries[1:]
+		}
+	}
+
+	Success(c, gin.H{
+		"entries": entries,
+		"more":    more,
+	})
+}
+
+// GetLogStoreStatus 获取日志收集状态和存储统计
+func GetLogStoreStatus(c *gin.Context) {
+	stats, err := repository.GetLogStoreStats()
+	if err != nil {
+		ServerError(c, "获取日志存储统计失败: "+err.Error())
+		return
+	}
+
+	Success(c, gin.H{
+		"collector": logstore.GetCollector().Status(),
+		"store":     stats,
+	})
+}
+
+// parseTimeQuery 解析时间参数，支持 RFC3339、Unix 时间戳和相对时长（如 30m 表示 30 分钟前）
+func parseTimeQuery(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("应为 RFC3339 时间、Unix 时间戳或时长")
+}
+
+// parseCursor 解析分页游标，为空时返回 0
+func parseCursor(s string) (uint64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
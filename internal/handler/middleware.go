@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -57,3 +58,13 @@ func Recovery() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// disableWriteTimeout 取消当前请求的写超时，用于耗时较长或流式的响应
+func disableWriteTimeout(c *gin.Context) {
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+}
+
+// disableReadTimeout 取消当前请求的读超时，用于上传大文件
+func disableReadTimeout(c *gin.Context) {
+	http.NewResponseController(c.Writer).SetReadDeadline(time.Time{})
+}
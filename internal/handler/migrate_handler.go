@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"rubick/internal/docker"
+	"rubick/internal/model"
+	"rubick/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// migrateRequest 迁移请求
+type migrateRequest struct {
+	TargetHostID string `json:"target_host_id" binding:"required"`
+	docker.MigrateOptions
+}
+
+// MigrateContainer 将容器迁移到另一台主机
+func MigrateContainer(c *gin.Context) {
+	containerID := c.Param("id")
+
+	var req migrateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	source, err := getHost(c.Query("host_id"))
+	if err != nil {
+		ServerError(c, "获取主机失败: "+err.Error())
+		return
+	}
+	target, err := repository.GetHostByID(req.TargetHostID)
+	if err != nil {
+		BadRequest(c, "目标主机不存在")
+		return
+	}
+	if target.ID == source.ID {
+		BadRequest(c, "目标主机不能与源主机相同")
+		return
+	}
+
+	svc, ok := getMigrateService(c, source, target)
+	if !ok {
+		return
+	}
+
+	// 传输镜像和卷数据可能耗时很长
+	disableWriteTimeout(c)
+	setAuditMessage(c, "迁移容器 "+containerID+" 从 "+source.Name+" 到 "+target.Name)
+
+	result, err := svc.MigrateContainer(c.Request.Context(), containerID, req.MigrateOptions)
+	if err != nil {
+		ServerError(c, "迁移容器失败: "+err.Error())
+		return
+	}
+
+	SuccessWithMessage(c, "容器迁移成功", result)
+}
+
+// MigrateComposeProject 将 Compose 项目迁移到另一台主机
+// 成功后在目标主机上创建同名项目记录
+func MigrateComposeProject(c *gin.Context) {
+	id := c.Param("id")
+
+	project, err := repository.GetComposeProjectByID(id)
+	if err != nil {
+		NotFound(c, "Compose 项目不存在")
+		return
+	}
+	// 源主机被删除后项目记录仍然存在，此时无法迁移
+	if project.Host == nil {
+		BadRequest(c, "源主机不存在")
+		return
+	}
+
+	var req migrateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	target, err := repository.GetHostByID(req.TargetHostID)
+	if err != nil {
+		BadRequest(c, "目标主机不存在")
+		return
+	}
+	if target.ID == project.HostID {
+		BadRequest(c, "目标主机不能与源主机相同")
+		return
+	}
+
+	existing, err := repository.ListComposeProjects(target.ID)
+	if err != nil {
+		ServerError(c, "获取 Compose 项目列表失败: "+err.Error())
+		return
+	}
+	for _, p := range existing {
+		if p.Name == project.Name {
+			BadRequest(c, "目标主机上已存在同名项目: "+project.Name)
+			return
+		}
+	}
+
+	svc, ok := getMigrateService(c, project.Host, target)
+	if !ok {
+		return
+	}
+
+	sourceExecutor, err := getExecutor(project.Host)
+	if err != nil {
+		ServerError(c, "创建执行器失败: "+err.Error())
+		return
+	}
+	defer sourceExecutor.Close()
+
+	targetExecutor, err := getExecutor(target)
+	if err != nil {
+		ServerError(c, "创建目标主机执行器失败: "+err.Error())
+		return
+	}
+	defer targetExecutor.Close()
+
+	migrated := model.ComposeProject{
+		Name:        project.Name,
+		HostID:      target.ID,
+		SourceType:  project.SourceType,
+		Content:     project.Content,
+		WorkDir:     project.WorkDir,
+		ComposeFile: project.ComposeFile,
+		EnvFile:     project.EnvFile,
+		Status:      "running",
+	}
+
+	disableWriteTimeout(c)
+	setAuditMessage(c, "迁移 Compose 项目 "+project.Name+" 从 "+project.Host.Name+" 到 "+target.Name)
+
+	result, err := svc.MigrateCompose(c.Request.Context(), docker.ComposeMigration{
+		Content:        project.Content,
+		SourceExecutor: sourceExecutor,
+		TargetExecutor: targetExecutor,
+		SourceOptions:  getComposeOptions(project),
+		TargetOptions:  getComposeOptions(&migrated),
+	}, req.MigrateOptions)
+	if err != nil {
+		ServerError(c, "迁移 Compose 项目失败: "+err.Error())
+		return
+	}
+
+	if err := repository.CreateComposeProject(&migrated); err != nil {
+		ServerError(c, "项目已在目标主机上启动，但保存项目记录失败: "+err.Error())
+		return
+	}
+	if result.SourceStopped {
+		repository.UpdateComposeProjectStatus(project.ID, "stopped")
+	}
+
+	SuccessWithMessage(c, "Compose 项目迁移成功", gin.H{
+		"project": migrated,
+		"result":  result,
+	})
+}
+
+// getMigrateService 获取源主机和目标主机的客户端并创建迁移服务，失败时写入错误响应
+func getMigrateService(c *gin.Context, source, target *model.Host) (*docker.MigrateService, bool) {
+	srcClient, err := getClient(c.Request.Context(), source)
+	if err != nil {
+		clientError(c, err)
+		return nil, false
+	}
+	dstClient, err := getClient(c.Request.Context(), target)
+	if err != nil {
+		ServerError(c, "获取目标主机 Docker 客户端失败: "+err.Error())
+		return nil, false
+	}
+	return docker.NewMigrateService(srcClient, dstClient), true
+}
@@ -1,8 +1,12 @@
 package handler
 
 import (
+	"context"
+
 	"rubick/internal/docker"
+	"rubick/internal/inventory"
 
+	"github.com/docker/docker/client"
 	"github.com/gin-gonic/gin"
 )
 
@@ -10,6 +14,13 @@ import (
 func ListNetworks(c *gin.Context) {
 	hostID := c.Query("host_id")
 
+	if isFleetQuery(c) {
+		listAllHosts(c, func(ctx context.Context, cli *client.Client) ([]docker.DockerNetwork, error) {
+			return docker.NewNetworkService(cli).List(ctx)
+		})
+		return
+	}
+
 	// 获取主机
 	host, err := getHost(hostID)
 	if err != nil {
@@ -17,10 +28,14 @@ func ListNetworks(c *gin.Context) {
 		return
 	}
 
+	if serveInventory(c, host.ID, "networks", func(s *inventory.Snapshot) interface{} { return s.Networks }) {
+		return
+	}
+
 	// 获取 Docker 客户端
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -47,7 +62,7 @@ func GetNetwork(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -89,7 +104,7 @@ func CreateNetwork(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -123,6 +138,8 @@ func CreateNetwork(c *gin.Context) {
 		return
 	}
 
+	inventory.GetInventory().Invalidate(host, inventory.TypeNetwork)
+
 	Success(c, info)
 }
 
@@ -139,7 +156,7 @@ func RemoveNetwork(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -149,5 +166,6 @@ func RemoveNetwork(c *gin.Context) {
 		return
 	}
 
+	inventory.GetInventory().Invalidate(host, inventory.TypeNetwork)
 	SuccessWithMessage(c, "网络删除成功", nil)
 }
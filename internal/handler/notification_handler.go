@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"rubick/internal/model"
+	"rubick/internal/notify"
+	"rubick/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notificationHookRequest 创建/更新通知 Webhook 的请求参数，指针字段为空表示不修改
+type notificationHookRequest struct {
+	Name    *string `json:"name"`
+	URL     *string `json:"url"`
+	Events  *string `json:"events"`
+	HostID  *string `json:"host_id"`
+	GroupID *string `json:"group_id"`
+	Secret  *string `json:"secret"`
+	Enabled *bool   `json:"enabled"`
+}
+
+// apply 将请求参数应用到 Webhook
+func (r *notificationHookRequest) apply(h *model.NotificationHook) {
+	setIfPresent(&h.Name, r.Name)
+	setIfPresent(&h.URL, r.URL)
+	setIfPresent(&h.Events, r.Events)
+	setIfPresent(&h.HostID, r.HostID)
+	setIfPresent(&h.GroupID, r.GroupID)
+	setIfPresent(&h.Secret, r.Secret)
+	setIfPresent(&h.Enabled, r.Enabled)
+}
+
+// validateNotificationHook 校验 Webhook 配置
+func validateNotificationHook(h *model.NotificationHook) string {
+	if strings.TrimSpace(h.Name) == "" {
+		return "名称不能为空"
+	}
+	u, err := url.Parse(h.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "URL 必须是有效的 http 或 https 地址"
+	}
+	if h.HostID != "" && h.GroupID != "" {
+		return "host_id 和 group_id 不能同时指定"
+	}
+	if h.GroupID != "" {
+		// 允许使用分组名称，保存时统一为 ID
+		group, err := repository.GetHostGroup(h.GroupID)
+		if err != nil {
+			return "主机分组不存在"
+		}
+		h.GroupID = group.ID
+	}
+	return ""
+}
+
+// ListNotificationHooks 列出通知 Webhook
+func ListNotificationHooks(c *gin.Context) {
+	hooks, err := repository.ListNotificationHooks()
+	if err != nil {
+		ServerError(c, "获取通知 Webhook 失败: "+err.Error())
+		return
+	}
+	for i := range hooks {
+		hooks[i].ClearSensitiveFields()
+	}
+	Success(c, hooks)
+}
+
+// CreateNotificationHook 创建通知 Webhook
+func CreateNotificationHook(c *gin.Context) {
+	var req notificationHookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	hook := model.NotificationHook{Enabled: true}
+	req.apply(&hook)
+	if msg := validateNotificationHook(&hook); msg != "" {
+		BadRequest(c, msg)
+		return
+	}
+
+	if err := repository.CreateNotificationHook(&hook); err != nil {
+		ServerError(c, "创建通知 Webhook 失败: "+err.Error())
+		return
+	}
+
+	hook.ClearSensitiveFields()
+	SuccessWithMessage(c, "通知 Webhook 创建成功", hook)
+}
+
+// UpdateNotificationHook 更新通知 Webhook
+func UpdateNotificationHook(c *gin.Context) {
+	hook, err := repository.GetNotificationHookByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "通知 Webhook 不存在")
+		return
+	}
+
+	var req notificationHookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+	req.apply(hook)
+	if msg := validateNotificationHook(hook); msg != "" {
+		BadRequest(c, msg)
+		return
+	}
+
+	if err := repository.SaveNotificationHook(hook); err != nil {
+		ServerError(c, "更新通知 Webhook 失败: "+err.Error())
+		return
+	}
+
+	hook.ClearSensitiveFields()
+	SuccessWithMessage(c, "通知 Webhook 更新成功", hook)
+}
+
+// DeleteNotificationHook 删除通知 Webhook
+func DeleteNotificationHook(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := repository.GetNotificationHookByID(id); err != nil {
+		NotFound(c, "通知 Webhook 不存在")
+		return
+	}
+
+	if err := repository.DeleteNotificationHook(id); err != nil {
+		ServerError(c, "删除通知 Webhook 失败: "+err.Error())
+		return
+	}
+	SuccessWithMessage(c, "通知 Webhook 删除成功", nil)
+}
+
+// TestNotificationHook 发送测试通知
+func TestNotificationHook(c *gin.Context) {
+	hook, err := repository.GetNotificationHookByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "通知 Webhook 不存在")
+		return
+	}
+
+	err = notify.Send(c.Request.Context(), hook, notify.Event{
+		Type:    notify.EventTest,
+		Message: "Rubick 测试通知",
+		Time:    time.Now(),
+	})
+	if err != nil {
+		Success(c, gin.H{
+			"success": false,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	Success(c, gin.H{
+		"success": true,
+		"message": "发送成功",
+	})
+}
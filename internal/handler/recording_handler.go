@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"rubick/internal/recording"
+	"rubick/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// maxPlaybackSpeed 回放的最大倍速
+	maxPlaybackSpeed = 20
+	// defaultPlaybackIdle 回放时默认的最长停顿（秒），与 asciinema 的 idle_time_limit 相同
+	defaultPlaybackIdle = 2
+)
+
+// ListRecordings 分页列出终端会话录像，可按主机、容器和开始时间过滤
+func ListRecordings(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	q := repository.RecordingQuery{
+		HostID:      c.Query("host_id"),
+		ContainerID: c.Query("container"),
+	}
+	var err error
+	if q.Since, err = parseTimeQuery(c.Query("since")); err != nil {
+		BadRequest(c, "无效的 since: "+err.Error())
+		return
+	}
+	if q.Until, err = parseTimeQuery(c.Query("until")); err != nil {
+		BadRequest(c, "无效的 until: "+err.Error())
+		return
+	}
+
+	recordings, total, err := repository.ListRecordings(q, page, pageSize)
+	if err != nil {
+		ServerError(c, "获取录像列表失败: "+err.Error())
+		return
+	}
+
+	SuccessWithPage(c, recordings, total, page, pageSize)
+}
+
+// GetRecording 获取终端会话录像详情及对应的审计日志
+func GetRecording(c *gin.Context) {
+	rec, err := repository.GetRecordingByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "录像不存在")
+		return
+	}
+	if auditLog, err := repository.GetAuditLogByRecordingID(rec.ID); err == nil {
+		rec.AuditLog = auditLog
+	}
+	Success(c, rec)
+}
+
+// DownloadRecording 下载 asciinema v2 格式的录像文件，可以用 asciinema play 回放
+func DownloadRecording(c *gin.Context) {
+	rec, err := repository.GetRecordingByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "录像不存在")
+		return
+	}
+	if _, err := os.Stat(recording.Path(rec)); err != nil {
+		NotFound(c, "录像文件不存在")
+		return
+	}
+
+	name := rec.ContainerName
+	if name == "" {
+		name = rec.ContainerID
+	}
+	name += "-" + rec.StartedAt.Format("20060102-150405") + ".cast"
+
+	disableWriteTimeout(c)
+	c.Header("Content-Type", "application/x-asciicast")
+	c.Header("Content-Disposition", attachmentHeader(name))
+	c.File(recording.Path(rec))
+}
+
+// PlayRecordingWS 通过 WebSocket 按原始节奏回放录像，消息格式与容器终端相同
+// speed 为回放倍速（默认 1），idle 为最长停顿秒数（默认 2，0 表示不限制）
+// 回放结束后发送 end 消息
+func PlayRecordingWS(c *gin.Context) {
+	speed := 1.0
+	if s := c.Query("speed"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v <= 0 || v > maxPlaybackSpeed {
+			BadRequest(c, "speed 必须大于 0 且不超过 20")
+			return
+		}
+		speed = v
+	}
+	idle := float64(defaultPlaybackIdle)
+	if s := c.Query("idle"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v < 0 {
+			BadRequest(c, "idle 必须是非负数")
+			return
+		}
+		idle = v
+	}
+
+	rec, err := repository.GetRecordingByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "录像不存在")
+		return
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	f, err := os.Open(recording.Path(rec))
+	if err != nil {
+		sendWSError(conn, "打开录像文件失败")
+		return
+	}
+	defer f.Close()
+
+	dec, err := recording.NewDecoder(f)
+	if err != nil {
+		sendWSError(conn, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 客户端断开时停止回放
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	header := dec.Header()
+	if err := conn.WriteJSON(ExecWebSocketMessage{Type: "resize", Cols: header.Width, Rows: header.Height}); err != nil {
+		return
+	}
+
+	start := time.Now()
+	var offset, last float64 // offset 为压缩停顿后累计减少的秒数
+	for {
+		e, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			sendWSError(conn, err.Error())
+			return
+		}
+
+		if idle > 0 && e.Time-last > idle {
+			offset += e.Time - last - idle
+		}
+		last = e.Time
+
+		wait := time.Until(start.Add(time.Duration((e.Time - offset) / speed * float64(time.Second))))
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+
+		var msg ExecWebSocketMessage
+		switch e.Type {
+		case recording.EventOutput:
+			msg = ExecWebSocketMessage{Type: "data", Content: e.Data}
+		case recording.EventResize:
+			cols, rows, err := recording.ParseSize(e.Data)
+			if err != nil {
+				continue
+			}
+			msg = ExecWebSocketMessage{Type: "resize", Cols: cols, Rows: rows}
+		default:
+			// 输入已由终端回显在输出中，回放时不发送
+			continue
+		}
+		if err := conn.WriteJSON(msg); err != nil {
+			return
+		}
+	}
+
+	conn.WriteJSON(WebSocketMessage{Type: "end"})
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+}
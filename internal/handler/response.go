@@ -107,4 +107,5 @@ const (
 	CodeContainerNotFound = 1003
 	CodeImageNotFound     = 1004
 	CodeInvalidConfig     = 1005
+	CodeHostDown          = 1006
 )
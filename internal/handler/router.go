@@ -40,6 +40,14 @@ func (r *Router) Setup() *gin.Engine {
 		// 审计日志路由
 		api.GET("/audit/logs", ListAuditLogs)
 
+		// 全局搜索路由
+		api.GET("/search", Search)
+		api.POST("/search/refresh", RefreshSearchIndex)
+
+		// 资源清单路由
+		api.GET("/inventory", GetInventory)
+		api.GET("/inventory/changes", ListInventoryChanges)
+
 		// 主机管理路由
 		setupHostRoutes(api)
 
@@ -58,10 +66,30 @@ func (r *Router) Setup() *gin.Engine {
 		// Compose 管理路由
 		setupComposeRoutes(api)
 
+		// 自动更新路由
+		setupUpdateRoutes(api)
+
+		// 通知路由
+		setupNotificationRoutes(api)
+
+		// 定时任务路由
+		setupScheduleRoutes(api)
+
+		// 集中日志路由
+		setupLogRoutes(api)
+
+		// 终端会话录像路由
+		setupRecordingRoutes(api)
+
+		// 系统路由
+		setupSystemRoutes(api)
+
 		// WebSocket 路由
+		api.GET("/ws", StreamWS)
 		api.GET("/ws/containers/:id/logs", ContainerLogsWS)
 		api.GET("/ws/containers/:id/exec", ContainerExecWS)
 		api.GET("/ws/compose/:id/logs", ComposeLogsWS)
+		api.GET("/ws/recordings/:id/play", PlayRecordingWS)
 	}
 
 	// 静态文件服务
@@ -111,10 +139,17 @@ func setupHostRoutes(rg *gin.RouterGroup) {
 	{
 		hosts.GET("", ListHosts)
 		hosts.POST("", CreateHost)
+		hosts.GET("/groups", ListHostGroups)
+		hosts.POST("/groups", CreateHostGroup)
+		hosts.GET("/groups/:id", GetHostGroup)
+		hosts.PUT("/groups/:id", UpdateHostGroup)
+		hosts.DELETE("/groups/:id", DeleteHostGroup)
 		hosts.GET("/:id", GetHost)
 		hosts.PUT("/:id", UpdateHost)
 		hosts.DELETE("/:id", DeleteHost)
 		hosts.POST("/:id/test", TestHostConnection)
+		hosts.GET("/:id/info", GetHostInfo)
+		hosts.GET("/:id/status/history", ListHostStatusHistory)
 	}
 }
 
@@ -124,14 +159,31 @@ func setupContainerRoutes(rg *gin.RouterGroup) {
 	{
 		containers.GET("", ListContainers)
 		containers.POST("", CreateContainer)
+		containers.POST("/bulk/:action", BulkContainerAction)
 		containers.GET("/:id", GetContainer)
 		containers.POST("/:id/start", StartContainer)
 		containers.POST("/:id/stop", StopContainer)
 		containers.POST("/:id/restart", RestartContainer)
 		containers.DELETE("/:id", RemoveContainer)
+		containers.POST("/:id/recreate", RecreateContainer)
+		containers.POST("/:id/pause", PauseContainer)
+		containers.POST("/:id/unpause", UnpauseContainer)
+		containers.POST("/:id/kill", KillContainer)
+		containers.POST("/:id/rename", RenameContainer)
+		containers.POST("/:id/update", UpdateContainerResources)
+		containers.POST("/:id/wait", WaitContainer)
+		containers.GET("/:id/files", ListContainerFiles)
+		containers.GET("/:id/files/download", DownloadContainerFiles)
+		containers.POST("/:id/files/upload", UploadContainerFiles)
+		containers.GET("/:id/changes", GetContainerChanges)
+		containers.POST("/:id/commit", CommitContainer)
+		containers.GET("/:id/export", ExportContainer)
+		containers.POST("/:id/migrate", MigrateContainer)
 		containers.GET("/:id/logs", GetContainerLogs)
+		containers.GET("/:id/logs/download", DownloadContainerLogs)
 		containers.GET("/:id/stats", GetContainerStats)
 		containers.POST("/:id/exec", ExecContainer)
+		containers.POST("/:id/exec/run", RunContainerExec)
 	}
 }
 
@@ -142,6 +194,8 @@ func setupImageRoutes(rg *gin.RouterGroup) {
 		images.GET("", ListImages)
 		images.POST("/pull", PullImage)
 		images.GET("/search", SearchImages)
+		images.GET("/save", SaveImages)
+		images.POST("/load", LoadImages)
 		images.GET("/:id", GetImage)
 		images.DELETE("/:id", RemoveImage)
 		images.POST("/:id/tag", TagImage)
@@ -154,8 +208,19 @@ func setupVolumeRoutes(rg *gin.RouterGroup) {
 	{
 		volumes.GET("", ListVolumes)
 		volumes.POST("", CreateVolume)
+		volumes.GET("/usage", ListVolumeUsage)
+		volumes.GET("/backups", ListVolumeBackups)
+		volumes.POST("/backups/upload", UploadVolumeBackup)
+		volumes.GET("/backups/:id/download", DownloadVolumeBackup)
+		volumes.POST("/backups/:id/restore", RestoreVolumeBackup)
+		volumes.DELETE("/backups/:id", DeleteVolumeBackup)
 		volumes.GET("/:name", GetVolume)
 		volumes.DELETE("/:name", RemoveVolume)
+		volumes.POST("/:name/backup", CreateVolumeBackup)
+		volumes.GET("/:name/usage", GetVolumeUsage)
+		volumes.GET("/:name/files", ListVolumeFiles)
+		volumes.GET("/:name/files/content", ReadVolumeFile)
+		volumes.GET("/:name/files/download", DownloadVolumeFiles)
 	}
 }
 
@@ -186,6 +251,9 @@ func setupComposeRoutes(rg *gin.RouterGroup) {
 		compose.POST("/projects/:id/restart", ComposeRestart)
 		compose.GET("/projects/:id/logs", GetComposeLogs)
 		compose.GET("/projects/:id/ps", ComposePs)
+		compose.GET("/projects/:id/updates", CheckComposeUpdates)
+		compose.POST("/projects/:id/update", UpdateComposeImages)
+		compose.POST("/projects/:id/migrate", MigrateComposeProject)
 
 		// 目录浏览和上传
 		compose.GET("/browse", BrowseDir)
@@ -193,3 +261,78 @@ func setupComposeRoutes(rg *gin.RouterGroup) {
 		compose.POST("/upload", UploadDirectory)
 	}
 }
+
+// setupUpdateRoutes 设置自动更新路由
+func setupUpdateRoutes(rg *gin.RouterGroup) {
+	updates := rg.Group("/updates")
+	{
+		updates.GET("/policies", ListUpdatePolicies)
+		updates.POST("/policies", CreateUpdatePolicy)
+		updates.GET("/policies/:id", GetUpdatePolicy)
+		updates.PUT("/policies/:id", UpdateUpdatePolicy)
+		updates.DELETE("/policies/:id", DeleteUpdatePolicy)
+		updates.POST("/policies/:id/run", RunUpdatePolicy)
+		updates.GET("/records", ListUpdateRecords)
+	}
+}
+
+// setupNotificationRoutes 设置通知路由
+func setupNotificationRoutes(rg *gin.RouterGroup) {
+	notifications := rg.Group("/notifications")
+	{
+		notifications.GET("/hooks", ListNotificationHooks)
+		notifications.POST("/hooks", CreateNotificationHook)
+		notifications.PUT("/hooks/:id", UpdateNotificationHook)
+		notifications.DELETE("/hooks/:id", DeleteNotificationHook)
+		notifications.POST("/hooks/:id/test", TestNotificationHook)
+	}
+}
+
+// setupScheduleRoutes 设置定时任务路由
+func setupScheduleRoutes(rg *gin.RouterGroup) {
+	schedules := rg.Group("/schedules")
+	{
+		schedules.GET("", ListScheduledTasks)
+		schedules.POST("", CreateScheduledTask)
+		schedules.GET("/runs", ListTaskRuns)
+		schedules.GET("/:id", GetScheduledTask)
+		schedules.PUT("/:id", UpdateScheduledTask)
+		schedules.DELETE("/:id", DeleteScheduledTask)
+		schedules.POST("/:id/run", RunScheduledTask)
+		schedules.GET("/:id/runs", ListTaskRuns)
+	}
+}
+
+// setupLogRoutes 设置集中日志路由
+func setupLogRoutes(rg *gin.RouterGroup) {
+	logs := rg.Group("/logs")
+	{
+		logs.GET("", ListLogs)
+		logs.GET("/status", GetLogStoreStatus)
+		logs.GET("/forwarders", ListLogForwarders)
+		logs.POST("/forwarders", CreateLogForwarder)
+		logs.GET("/forwarders/:id", GetLogForwarder)
+		logs.PUT("/forwarders/:id", UpdateLogForwarder)
+		logs.DELETE("/forwarders/:id", DeleteLogForwarder)
+		logs.POST("/forwarders/:id/test", TestLogForwarder)
+	}
+}
+
+// setupRecordingRoutes 设置终端会话录像路由
+func setupRecordingRoutes(rg *gin.RouterGroup) {
+	recordings := rg.Group("/recordings")
+	{
+		recordings.GET("", ListRecordings)
+		recordings.GET("/:id", GetRecording)
+		recordings.GET("/:id/download", DownloadRecording)
+	}
+}
+
+// setupSystemRoutes 设置系统路由
+func setupSystemRoutes(rg *gin.RouterGroup) {
+	system := rg.Group("/system")
+	{
+		system.GET("/df", GetDiskUsage)
+		system.POST("/prune", PruneSystem)
+	}
+}
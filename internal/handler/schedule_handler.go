@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"rubick/internal/model"
+	"rubick/internal/repository"
+	"rubick/internal/scheduler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scheduledTaskRequest 创建/更新定时任务的请求参数，指针字段为空表示不修改
+type scheduledTaskRequest struct {
+	Name     *string   `json:"name"`
+	HostID   *string   `json:"host_id"`
+	Action   *string   `json:"action"`
+	Target   *string   `json:"target"`
+	Command  *[]string `json:"command"`
+	User     *string   `json:"user"`
+	Timeout  *int      `json:"timeout"`
+	PruneAll *bool     `json:"prune_all"`
+	Retain   *int      `json:"retain"`
+	Schedule *string   `json:"schedule"`
+	Timezone *string   `json:"timezone"`
+	Enabled  *bool     `json:"enabled"`
+}
+
+// apply 将请求参数应用到定时任务
+func (r *scheduledTaskRequest) apply(t *model.ScheduledTask) {
+	setIfPresent(&t.Name, r.Name)
+	setIfPresent(&t.HostID, r.HostID)
+	setIfPresent(&t.Action, r.Action)
+	setIfPresent(&t.Target, r.Target)
+	setIfPresent(&t.Command, r.Command)
+	setIfPresent(&t.User, r.User)
+	setIfPresent(&t.Timeout, r.Timeout)
+	setIfPresent(&t.PruneAll, r.PruneAll)
+	setIfPresent(&t.Retain, r.Retain)
+	setIfPresent(&t.Schedule, r.Schedule)
+	setIfPresent(&t.Timezone, r.Timezone)
+	setIfPresent(&t.Enabled, r.Enabled)
+}
+
+// ListScheduledTasks 列出定时任务
+func ListScheduledTasks(c *gin.Context) {
+	tasks, err := repository.ListScheduledTasks(c.Query("host_id"))
+	if err != nil {
+		ServerError(c, "获取定时任务失败: "+err.Error())
+		return
+	}
+	for i := range tasks {
+		if tasks[i].Host != nil {
+			tasks[i].Host.ClearSensitiveFields()
+		}
+	}
+	Success(c, tasks)
+}
+
+// CreateScheduledTask 创建定时任务
+func CreateScheduledTask(c *gin.Context) {
+	var req scheduledTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	task := model.ScheduledTask{Enabled: true}
+	req.apply(&task)
+
+	if !saveScheduledTask(c, &task, true) {
+		return
+	}
+	SuccessWithMessage(c, "定时任务创建成功", task)
+}
+
+// GetScheduledTask 获取定时任务详情
+func GetScheduledTask(c *gin.Context) {
+	task, err := repository.GetScheduledTaskByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "定时任务不存在")
+		return
+	}
+	if task.Host != nil {
+		task.Host.ClearSensitiveFields()
+	}
+	Success(c, task)
+}
+
+// UpdateScheduledTask 更新定时任务
+func UpdateScheduledTask(c *gin.Context) {
+	task, err := repository.GetScheduledTaskByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "定时任务不存在")
+		return
+	}
+
+	var req scheduledTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+	req.apply(task)
+	task.Host = nil
+
+	if !saveScheduledTask(c, task, false) {
+		return
+	}
+	SuccessWithMessage(c, "定时任务更新成功", task)
+}
+
+// saveScheduledTask 校验任务、计算下次执行时间并保存，失败时写入错误响应
+func saveScheduledTask(c *gin.Context, task *model.ScheduledTask, create bool) bool {
+	if err := scheduler.ValidateTask(task); err != nil {
+		BadRequest(c, err.Error())
+		return false
+	}
+	if _, err := repository.GetHostByID(task.HostID); err != nil {
+		BadRequest(c, "主机不存在")
+		return false
+	}
+	switch task.Action {
+	case scheduler.ActionComposeStart, scheduler.ActionComposeStop, scheduler.ActionComposeRestart:
+		project, err := repository.GetComposeProjectByID(task.Target)
+		if err != nil || project.HostID != task.HostID {
+			BadRequest(c, "Compose 项目不存在或不属于该主机")
+			return false
+		}
+	}
+
+	next, err := scheduler.NextRunAt(task, time.Now())
+	if err != nil {
+		BadRequest(c, err.Error())
+		return false
+	}
+	task.NextRunAt = next
+
+	if create {
+		err = repository.CreateScheduledTask(task)
+	} else {
+		err = repository.SaveScheduledTask(task)
+	}
+	if err != nil {
+		ServerError(c, "保存定时任务失败: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// DeleteScheduledTask 删除定时任务
+func DeleteScheduledTask(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := repository.GetScheduledTaskByID(id); err != nil {
+		NotFound(c, "定时任务不存在")
+		return
+	}
+	if scheduler.GetScheduler().IsRunning(id) {
+		BadRequest(c, "任务正在执行中，请稍后再删除")
+		return
+	}
+
+	if err := repository.DeleteScheduledTask(id); err != nil {
+		ServerError(c, "删除定时任务失败: "+err.Error())
+		return
+	}
+	SuccessWithMessage(c, "定时任务删除成功", nil)
+}
+
+// RunScheduledTask 立即执行定时任务
+func RunScheduledTask(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := repository.GetScheduledTaskByID(id); err != nil {
+		NotFound(c, "定时任务不存在")
+		return
+	}
+
+	run, err := scheduler.GetScheduler().Trigger(id)
+	if err != nil {
+		Fail(c, CodeBadRequest, err.Error())
+		return
+	}
+	SuccessWithMessage(c, "定时任务已开始执行", run)
+}
+
+// ListTaskRuns 列出定时任务执行记录
+func ListTaskRuns(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	taskID := c.Param("id")
+	if taskID == "" {
+		taskID = c.Query("task_id")
+	}
+
+	runs, total, err := repository.ListTaskRuns(page, pageSize, taskID, c.Query("status"))
+	if err != nil {
+		ServerError(c, "获取执行记录失败: "+err.Error())
+		return
+	}
+
+	SuccessWithPage(c, runs, total, page, pageSize)
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rubick/internal/inventory"
+	"rubick/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// searchHostStatus 搜索结果中单台主机的清单状态
+type searchHostStatus struct {
+	HostID      string     `json:"host_id"`
+	HostName    string     `json:"host_name"`
+	RefreshedAt time.Time  `json:"refreshed_at"`
+	Error       string     `json:"error,omitempty"`
+	FailedAt    *time.Time `json:"failed_at,omitempty"`
+}
+
+// Search 在所有主机的容器、镜像、卷、网络和 Compose 项目中搜索
+// 数据来自定期刷新的资源清单缓存，不直接访问 Docker
+// 支持 types（逗号分隔）、host_id、group、tag、environment 和 limit 参数
+func Search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		BadRequest(c, "搜索关键字不能为空")
+		return
+	}
+
+	opts := inventory.SearchOptions{Limit: 50}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > 500 {
+			BadRequest(c, "limit 必须在 1-500 之间")
+			return
+		}
+		opts.Limit = limit
+	}
+	for _, t := range strings.Split(c.Query("types"), ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if !inventory.ValidType(t) {
+			BadRequest(c, "不支持的资源类型: "+t)
+			return
+		}
+		opts.Types = append(opts.Types, t)
+	}
+
+	if hostID := c.Query("host_id"); hostID != "" && hostID != allHosts {
+		opts.HostIDs = map[string]bool{hostID: true}
+	} else if isFleetQuery(c) {
+		hosts, ok := fleetHosts(c)
+		if !ok {
+			return
+		}
+		opts.HostIDs = make(map[string]bool, len(hosts))
+		for _, host := range hosts {
+			opts.HostIDs[host.ID] = true
+		}
+		// 没有匹配的主机时不返回任何结果
+		if len(opts.HostIDs) == 0 {
+			opts.HostIDs[""] = true
+		}
+	}
+
+	projects, err := repository.ListComposeProjects("")
+	if err != nil {
+		ServerError(c, "获取 Compose 项目列表失败: "+err.Error())
+		return
+	}
+
+	snapshots := inventory.GetInventory().Snapshots()
+	results, total := inventory.Search(query, snapshots, projects, opts)
+
+	hosts := make([]searchHostStatus, 0, len(snapshots))
+	for _, s := range snapshots {
+		if len(opts.HostIDs) > 0 && !opts.HostIDs[s.HostID] {
+			continue
+		}
+		hosts = append(hosts, searchHostStatus{
+			HostID:      s.HostID,
+			HostName:    s.HostName,
+			RefreshedAt: s.RefreshedAt,
+			Error:       s.Error,
+			FailedAt:    s.FailedAt,
+		})
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].HostName < hosts[j].HostName })
+
+	Success(c, gin.H{
+		"query":   query,
+		"total":   total,
+		"results": results,
+		"hosts":   hosts,
+	})
+}
+
+// RefreshSearchIndex 立即刷新所有主机的资源清单
+func RefreshSearchIndex(c *gin.Context) {
+	disableWriteTimeout(c)
+	inventory.GetInventory().Refresh(c.Request.Context())
+	SuccessWithMessage(c, "资源清单已刷新", nil)
+}
@@ -0,0 +1,308 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"rubick/internal/stream"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// streamPingInterval 多路复用连接的心跳间隔
+	streamPingInterval = 30 * time.Second
+	// streamWriteTimeout 单次写入的超时，客户端长时间不读取时断开连接
+	streamWriteTimeout = 10 * time.Second
+	// streamReadBatch 每轮从单个主题读取的最大消息数，避免一个主题占满连接
+	streamReadBatch = 100
+	// streamDefaultTail 未指定续传位置时回放的最近消息数
+	streamDefaultTail = 100
+	// maxStreamSubscriptions 单个连接的最大订阅数
+	maxStreamSubscriptions = 64
+)
+
+// streamRequest 客户端发送的指令
+//
+//	{"op": "subscribe", "topic": "logs:<host_id>:<container_id>", "epoch": "m3k1x2", "since": 120}
+//	{"op": "unsubscribe", "topic": "..."}
+//	{"op": "ping"}
+//
+// since 为客户端已收到的最后一条消息的序号，用于断线重连后续传，需要同时指定消息所属的 epoch；
+// 主题已重建（epoch 不一致）时先发送 reset，再回放最近 tail 条消息。
+// 未指定 since 时回放最近 tail 条消息（默认 100）
+type streamRequest struct {
+	Op    string  `json:"op"`
+	Topic string  `json:"topic"`
+	Epoch string  `json:"epoch"`
+	Since *uint64 `json:"since"`
+	Tail  *int    `json:"tail"`
+}
+
+// streamFrame 服务端发送的消息
+// type 为 message、subscribed、unsubscribed、lagged、reset、end、error、ping、pong
+// seq 只在同一 epoch 内有效
+type streamFrame struct {
+	Type    string          `json:"type"`
+	Topic   string          `json:"topic,omitempty"`
+	Epoch   string          `json:"epoch,omitempty"`
+	Seq     uint64          `json:"seq,omitempty"`
+	Time    *time.Time      `json:"time,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Skipped uint64          `json:"skipped,omitempty"` // lagged：被跳过的消息数
+	Error   string          `json:"error,omitempty"`
+}
+
+// streamSub 连接上的一个订阅，cursor 为已发送的最后一条消息的序号
+type streamSub struct {
+	sub    *stream.Subscription
+	cursor uint64
+	ended  bool
+}
+
+// streamConn 多路复用连接
+// 读协程处理指令，写协程是唯一的写入者，按订阅的游标从主题缓冲读取并发送。
+// 客户端读取慢时生产者不会被阻塞，落后超过缓冲的消息以 lagged 通知客户端
+type streamConn struct {
+	conn    *websocket.Conn
+	mu      sync.Mutex
+	subs    map[string]*streamSub
+	control chan streamFrame
+	wake    chan struct{}
+}
+
+// StreamWS 多路复用 WebSocket，一个连接上订阅多个主题
+// 主题：logs:<host_id>:<container_id>、stats:<host_id>:<container_id>、events:<host_id>、job:<run_id>
+func StreamWS(c *gin.Context) {
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sc := &streamConn{
+		conn:    conn,
+		subs:    make(map[string]*streamSub),
+		control: make(chan streamFrame, 64),
+		wake:    make(chan struct{}, 1),
+	}
+	defer sc.closeAll()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer conn.Close() // 写入失败时让读循环退出
+		sc.writeLoop(ctx)
+	}()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+
+		var req streamRequest
+		if err := json.Unmarshal(msg, &req); err != nil {
+			sc.send(ctx, streamFrame{Type: "error", Error: "无效的消息"})
+			continue
+		}
+		switch req.Op {
+		case "subscribe":
+			sc.subscribe(ctx, req)
+		case "unsubscribe":
+			sc.unsubscribe(ctx, req.Topic)
+		case "ping":
+			sc.send(ctx, streamFrame{Type: "pong"})
+		default:
+			sc.send(ctx, streamFrame{Type: "error", Error: "不支持的指令: " + req.Op})
+		}
+	}
+
+	cancel()
+	<-done
+}
+
+// subscribe 订阅主题，已订阅时按新的续传位置重新开始
+func (sc *streamConn) subscribe(ctx context.Context, req streamRequest) {
+	sc.mu.Lock()
+	old, exists := sc.subs[req.Topic]
+	count := len(sc.subs)
+	sc.mu.Unlock()
+	if !exists && count >= maxStreamSubscriptions {
+		sc.send(ctx, streamFrame{Type: "error", Topic: req.Topic, Error: "订阅数超过上限"})
+		return
+	}
+	if req.Since != nil && req.Epoch == "" {
+		sc.send(ctx, streamFrame{Type: "error", Topic: req.Topic, Error: "续传时需要指定 epoch"})
+		return
+	}
+
+	sub, err := stream.GetHub().Subscribe(req.Topic, sc.wake)
+	if err != nil {
+		sc.send(ctx, streamFrame{Type: "error", Topic: req.Topic, Error: err.Error()})
+		return
+	}
+
+	s := &streamSub{sub: sub}
+	reset := false
+	if req.Since != nil && req.Epoch == sub.Epoch() {
+		s.cursor = *req.Since
+	} else {
+		// 主题在断线期间被清理并重建，之前的序号已失效
+		reset = req.Since != nil
+		tail := streamDefaultTail
+		if req.Tail != nil && *req.Tail >= 0 {
+			tail = *req.Tail
+		}
+		s.cursor = sub.Tail(tail)
+	}
+
+	sc.mu.Lock()
+	sc.subs[req.Topic] = s
+	sc.mu.Unlock()
+	if exists {
+		old.sub.Close()
+	}
+
+	if reset {
+		sc.send(ctx, streamFrame{Type: "reset", Topic: req.Topic, Epoch: sub.Epoch()})
+	}
+	sc.send(ctx, streamFrame{Type: "subscribed", Topic: req.Topic, Epoch: sub.Epoch(), Seq: s.cursor})
+	sc.signal()
+}
+
+// unsubscribe 取消订阅
+func (sc *streamConn) unsubscribe(ctx context.Context, topic string) {
+	sc.mu.Lock()
+	s, ok := sc.subs[topic]
+	delete(sc.subs, topic)
+	sc.mu.Unlock()
+	if ok {
+		s.sub.Close()
+	}
+	sc.send(ctx, streamFrame{Type: "unsubscribed", Topic: topic})
+}
+
+// closeAll 连接关闭时取消所有订阅，主题在空闲超时前保留缓冲以便重连续传
+func (sc *streamConn) closeAll() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for topic, s := range sc.subs {
+		s.sub.Close()
+		delete(sc.subs, topic)
+	}
+}
+
+// send 发送控制消息
+func (sc *streamConn) send(ctx context.Context, f streamFrame) {
+	select {
+	case sc.control <- f:
+	case <-ctx.Done():
+	}
+}
+
+// signal 唤醒写协程
+func (sc *streamConn) signal() {
+	select {
+	case sc.wake <- struct{}{}:
+	default:
+	}
+}
+
+// writeLoop 发送控制消息、订阅的消息和心跳，写入失败时退出
+func (sc *streamConn) writeLoop(ctx context.Context) {
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case f := <-sc.control:
+			err = sc.write(f)
+		case <-ticker.C:
+			err = sc.write(streamFrame{Type: "ping"})
+		case <-sc.wake:
+			err = sc.flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// flush 为每个订阅发送新消息，还有未发送的消息时再次唤醒自己
+// 先发送积压的控制消息，保证客户端先收到 subscribed 再收到该主题的消息
+func (sc *streamConn) flush() error {
+	for pending := true; pending; {
+		select {
+		case f := <-sc.control:
+			if err := sc.write(f); err != nil {
+				return err
+			}
+		default:
+			pending = false
+		}
+	}
+
+	sc.mu.Lock()
+	subs := make([]*streamSub, 0, len(sc.subs))
+	for _, s := range sc.subs {
+		subs = append(subs, s)
+	}
+	sc.mu.Unlock()
+
+	more := false
+	for _, s := range subs {
+		topic, epoch := s.sub.Topic(), s.sub.Epoch()
+		batch := s.sub.Read(s.cursor, streamReadBatch)
+
+		if batch.Reset {
+			s.cursor = 0
+			if err := sc.write(streamFrame{Type: "reset", Topic: topic, Epoch: epoch}); err != nil {
+				return err
+			}
+		}
+		if batch.Skipped > 0 {
+			if err := sc.write(streamFrame{Type: "lagged", Topic: topic, Epoch: epoch, Seq: s.cursor, Skipped: batch.Skipped}); err != nil {
+				return err
+			}
+		}
+		for _, m := range batch.Messages {
+			t := m.Time
+			if err := sc.write(streamFrame{Type: "message", Topic: topic, Epoch: epoch, Seq: m.Seq, Time: &t, Data: m.Data}); err != nil {
+				return err
+			}
+			s.cursor = m.Seq
+		}
+		if len(batch.Messages) == streamReadBatch {
+			more = true
+		}
+
+		// 生产者结束后只通知一次，重新启动后继续发送
+		if batch.Ended && !s.ended {
+			if err := sc.write(streamFrame{Type: "end", Topic: topic, Epoch: epoch, Seq: s.cursor, Error: batch.Error}); err != nil {
+				return err
+			}
+		}
+		s.ended = batch.Ended
+	}
+
+	if more {
+		sc.signal()
+	}
+	return nil
+}
+
+// write 写入一条消息
+func (sc *streamConn) write(f streamFrame) error {
+	sc.conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	return sc.conn.WriteJSON(f)
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"rubick/internal/docker"
+	"rubick/internal/inventory"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getSystemService 根据请求的 host_id 创建系统服务，失败时写入错误响应
+func getSystemService(c *gin.Context) (*docker.SystemService, bool) {
+	host, err := getHost(c.Query("host_id"))
+	if err != nil {
+		ServerError(c, "获取主机失败: "+err.Error())
+		return nil, false
+	}
+
+	cli, err := getClient(c.Request.Context(), host)
+	if err != nil {
+		clientError(c, err)
+		return nil, false
+	}
+
+	return docker.NewSystemService(cli), true
+}
+
+// GetDiskUsage 获取镜像、容器、卷和构建缓存的磁盘占用
+func GetDiskUsage(c *gin.Context) {
+	svc, ok := getSystemService(c)
+	if !ok {
+		return
+	}
+
+	usage, err := svc.DiskUsage(c.Request.Context())
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	Success(c, usage)
+}
+
+// PruneSystem 清理未使用的容器、镜像、卷、网络和构建缓存
+// dry_run 为 true 时只返回将被清理的资源
+func PruneSystem(c *gin.Context) {
+	var req docker.PruneOptions
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		FailWithStatus(c, http.StatusBadRequest, CodeInvalidConfig, err.Error())
+		return
+	}
+
+	svc, ok := getSystemService(c)
+	if !ok {
+		return
+	}
+
+	// 清理大量镜像或构建缓存可能耗时较长
+	disableWriteTimeout(c)
+
+	report, err := svc.Prune(c.Request.Context(), req)
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	if !req.DryRun {
+		types := make([]string, 0, len(report.Results))
+		for _, r := range report.Results {
+			types = append(types, fmt.Sprintf("%s(%d)", r.Type, len(r.Items)))
+		}
+		setAuditMessage(c, fmt.Sprintf("清理 %s，释放 %d 字节", strings.Join(types, ", "), report.SpaceReclaimed))
+		refreshInventory(c, inventory.TypeContainer, inventory.TypeImage, inventory.TypeVolume, inventory.TypeNetwork)
+	}
+
+	Success(c, report)
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"rubick/internal/model"
+	"rubick/internal/repository"
+	"rubick/internal/updater"
+
+	"github.com/gin-gonic/gin"
+)
+
+// updatePolicyRequest 创建/更新策略的请求参数，指针字段为空表示不修改
+type updatePolicyRequest struct {
+	Name              *string `json:"name"`
+	HostID            *string `json:"host_id"`
+	TargetType        *string `json:"target_type"`
+	LabelSelector     *string `json:"label_selector"`
+	ProjectID         *string `json:"project_id"`
+	Schedule          *string `json:"schedule"`
+	Timezone          *string `json:"timezone"`
+	MaintenanceWindow *string `json:"maintenance_window"`
+	SemverConstraint  *string `json:"semver_constraint"`
+	TagPattern        *string `json:"tag_pattern"`
+	HealthGracePeriod *int    `json:"health_grace_period"`
+	Rollback          *bool   `json:"rollback"`
+	Enabled           *bool   `json:"enabled"`
+}
+
+// apply 将请求参数应用到策略
+func (r *updatePolicyRequest) apply(p *model.UpdatePolicy) {
+	setIfPresent(&p.Name, r.Name)
+	setIfPresent(&p.HostID, r.HostID)
+	setIfPresent(&p.TargetType, r.TargetType)
+	setIfPresent(&p.LabelSelector, r.LabelSelector)
+	setIfPresent(&p.ProjectID, r.ProjectID)
+	setIfPresent(&p.Schedule, r.Schedule)
+	setIfPresent(&p.Timezone, r.Timezone)
+	setIfPresent(&p.MaintenanceWindow, r.MaintenanceWindow)
+	setIfPresent(&p.SemverConstraint, r.SemverConstraint)
+	setIfPresent(&p.TagPattern, r.TagPattern)
+	setIfPresent(&p.HealthGracePeriod, r.HealthGracePeriod)
+	setIfPresent(&p.Rollback, r.Rollback)
+	setIfPresent(&p.Enabled, r.Enabled)
+}
+
+// setIfPresent 值不为空时赋值
+func setIfPresent[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// ListUpdatePolicies 列出自动更新策略
+func ListUpdatePolicies(c *gin.Context) {
+	policies, err := repository.ListUpdatePolicies(c.Query("host_id"))
+	if err != nil {
+		ServerError(c, "获取更新策略失败: "+err.Error())
+		return
+	}
+	for i := range policies {
+		if policies[i].Host != nil {
+			policies[i].Host.ClearSensitiveFields()
+		}
+	}
+	Success(c, policies)
+}
+
+// CreateUpdatePolicy 创建自动更新策略
+func CreateUpdatePolicy(c *gin.Context) {
+	var req updatePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+
+	policy := model.UpdatePolicy{
+		HealthGracePeriod: 60,
+		Rollback:          true,
+		Enabled:           true,
+	}
+	req.apply(&policy)
+
+	if !saveUpdatePolicy(c, &policy, true) {
+		return
+	}
+	SuccessWithMessage(c, "更新策略创建成功", policy)
+}
+
+// GetUpdatePolicy 获取自动更新策略详情
+func GetUpdatePolicy(c *gin.Context) {
+	policy, err := repository.GetUpdatePolicyByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "更新策略不存在")
+		return
+	}
+	if policy.Host != nil {
+		policy.Host.ClearSensitiveFields()
+	}
+	Success(c, policy)
+}
+
+// UpdateUpdatePolicy 更新自动更新策略
+func UpdateUpdatePolicy(c *gin.Context) {
+	policy, err := repository.GetUpdatePolicyByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "更新策略不存在")
+		return
+	}
+
+	var req updatePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "无效的请求参数: "+err.Error())
+		return
+	}
+	req.apply(policy)
+	policy.Host = nil
+
+	if !saveUpdatePolicy(c, policy, false) {
+		return
+	}
+	SuccessWithMessage(c, "更新策略更新成功", policy)
+}
+
+// saveUpdatePolicy 校验策略、计算下次执行时间并保存，失败时写入错误响应
+func saveUpdatePolicy(c *gin.Context, policy *model.UpdatePolicy, create bool) bool {
+	if err := updater.ValidatePolicy(policy); err != nil {
+		BadRequest(c, err.Error())
+		return false
+	}
+	if _, err := repository.GetHostByID(policy.HostID); err != nil {
+		BadRequest(c, "主机不存在")
+		return false
+	}
+	if policy.TargetType == updater.TargetCompose {
+		project, err := repository.GetComposeProjectByID(policy.ProjectID)
+		if err != nil || project.HostID != policy.HostID {
+			BadRequest(c, "Compose 项目不存在或不属于该主机")
+			return false
+		}
+	}
+
+	next, err := updater.NextRunAt(policy, time.Now())
+	if err != nil {
+		BadRequest(c, err.Error())
+		return false
+	}
+	policy.NextRunAt = next
+
+	if create {
+		err = repository.CreateUpdatePolicy(policy)
+	} else {
+		err = repository.SaveUpdatePolicy(policy)
+	}
+	if err != nil {
+		ServerError(c, "保存更新策略失败: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// DeleteUpdatePolicy 删除自动更新策略
+func DeleteUpdatePolicy(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := repository.GetUpdatePolicyByID(id); err != nil {
+		NotFound(c, "更新策略不存在")
+		return
+	}
+
+	if err := repository.DeleteUpdatePolicy(id); err != nil {
+		ServerError(c, "删除更新策略失败: "+err.Error())
+		return
+	}
+	SuccessWithMessage(c, "更新策略删除成功", nil)
+}
+
+// RunUpdatePolicy 立即执行自动更新策略（忽略维护窗口）
+func RunUpdatePolicy(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := repository.GetUpdatePolicyByID(id); err != nil {
+		NotFound(c, "更新策略不存在")
+		return
+	}
+
+	if err := updater.GetUpdater().Trigger(id); err != nil {
+		Fail(c, CodeBadRequest, err.Error())
+		return
+	}
+	SuccessWithMessage(c, "更新策略已开始执行", nil)
+}
+
+// ListUpdateRecords 列出自动更新记录
+func ListUpdateRecords(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	records, total, err := repository.ListUpdateRecords(page, pageSize, c.Query("policy_id"), c.Query("host_id"))
+	if err != nil {
+		ServerError(c, "获取更新记录失败: "+err.Error())
+		return
+	}
+
+	SuccessWithPage(c, records, total, page, pageSize)
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"path"
+
+	"rubick/internal/backup"
+	"rubick/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListVolumeBackups 列出卷备份，可按主机和卷名称过滤
+func ListVolumeBackups(c *gin.Context) {
+	backups, err := repository.ListVolumeBackups(c.Query("host_id"), c.Query("volume"))
+	if err != nil {
+		ServerError(c, "获取备份列表失败: "+err.Error())
+		return
+	}
+	for i := range backups {
+		if backups[i].Host != nil {
+			backups[i].Host.ClearSensitiveFields()
+		}
+	}
+
+	Success(c, backups)
+}
+
+// CreateVolumeBackup 备份卷到本地备份目录
+func CreateVolumeBackup(c *gin.Context) {
+	name := c.Param("name")
+
+	host, err := getHost(c.Query("host_id"))
+	if err != nil {
+		ServerError(c, "获取主机失败: "+err.Error())
+		return
+	}
+
+	disableWriteTimeout(c)
+	setAuditMessage(c, "备份卷 "+name)
+
+	b, err := backup.Create(c.Request.Context(), host, name, backup.SourceManual, "")
+	if err != nil {
+		ServerError(c, "备份卷失败: "+err.Error())
+		return
+	}
+
+	SuccessWithMessage(c, "备份成功", b)
+}
+
+// DownloadVolumeBackup 下载备份文件
+func DownloadVolumeBackup(c *gin.Context) {
+	b, err := repository.GetVolumeBackupByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "备份不存在")
+		return
+	}
+
+	disableWriteTimeout(c)
+	c.FileAttachment(backup.Path(b), path.Base(b.FileName))
+}
+
+// UploadVolumeBackup 上传 tar 归档（可为 gzip 压缩）作为卷备份
+// 请求体可以直接是归档内容，也可以是包含 file 字段的 multipart 表单
+func UploadVolumeBackup(c *gin.Context) {
+	volume := c.Query("volume")
+	if volume == "" {
+		BadRequest(c, "volume 参数不能为空")
+		return
+	}
+	if err := backup.ValidateVolumeName(volume); err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	host, err := getHost(c.Query("host_id"))
+	if err != nil {
+		ServerError(c, "获取主机失败: "+err.Error())
+		return
+	}
+
+	disableReadTimeout(c)
+
+	input, err := uploadReader(c, "file")
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	setAuditMessage(c, "上传卷 "+volume+" 的备份")
+	b, err := backup.Import(host.ID, volume, input)
+	if err != nil {
+		BadRequest(c, "保存备份失败: "+err.Error())
+		return
+	}
+
+	SuccessWithMessage(c, "上传成功", b)
+}
+
+// RestoreVolumeBackup 将备份恢复到新卷或已有的卷
+// 默认恢复到备份所在主机的原卷，可通过 host_id 和 volume 指定其他主机和卷
+func RestoreVolumeBackup(c *gin.Context) {
+	b, err := repository.GetVolumeBackupByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "备份不存在")
+		return
+	}
+
+	var req struct {
+		HostID string `json:"host_id"`
+		Volume string `json:"volume"`
+	}
+	c.ShouldBindJSON(&req)
+	if req.HostID == "" {
+		req.HostID = b.HostID
+	}
+	if req.Volume == "" {
+		req.Volume = b.Volume
+	}
+
+	host, err := repository.GetHostByID(req.HostID)
+	if err != nil {
+		BadRequest(c, "主机不存在")
+		return
+	}
+
+	disableWriteTimeout(c)
+	setAuditMessage(c, "恢复备份 "+b.FileName+" 到卷 "+req.Volume)
+
+	if err := backup.Restore(c.Request.Context(), b, host, req.Volume); err != nil {
+		ServerError(c, "恢复备份失败: "+err.Error())
+		return
+	}
+
+	SuccessWithMessage(c, "恢复成功", gin.H{
+		"host_id": host.ID,
+		"volume":  req.Volume,
+	})
+}
+
+// DeleteVolumeBackup 删除备份文件和记录
+func DeleteVolumeBackup(c *gin.Context) {
+	b, err := repository.GetVolumeBackupByID(c.Param("id"))
+	if err != nil {
+		NotFound(c, "备份不存在")
+		return
+	}
+
+	setAuditMessage(c, "删除备份 "+b.FileName)
+	if err := backup.Delete(b); err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	SuccessWithMessage(c, "备份删除成功", nil)
+}
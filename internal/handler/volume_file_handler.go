@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"unicode/utf8"
+
+	"rubick/internal/config"
+	"rubick/internal/docker"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxVolumeFileRead 直接查看卷中文件内容的大小上限，更大的文件需要下载
+const maxVolumeFileRead = 1 << 20
+
+// getVolumeService 根据请求的 host_id 创建卷服务，失败时写入错误响应
+func getVolumeService(c *gin.Context) (*docker.VolumeService, bool) {
+	host, err := getHost(c.Query("host_id"))
+	if err != nil {
+		ServerError(c, "获取主机失败: "+err.Error())
+		return nil, false
+	}
+
+	cli, err := getClient(c.Request.Context(), host)
+	if err != nil {
+		clientError(c, err)
+		return nil, false
+	}
+
+	return docker.NewVolumeService(cli), true
+}
+
+// browseVolume 创建浏览卷的辅助容器，失败时写入错误响应
+func browseVolume(c *gin.Context) (*docker.VolumeBrowser, bool) {
+	svc, ok := getVolumeService(c)
+	if !ok {
+		return nil, false
+	}
+
+	browser, err := svc.Browse(c.Request.Context(), c.Param("name"), config.Get().Backup.HelperImage)
+	if err != nil {
+		ServerError(c, err.Error())
+		return nil, false
+	}
+	return browser, true
+}
+
+// ListVolumeFiles 浏览卷中的目录
+func ListVolumeFiles(c *gin.Context) {
+	dirPath := c.DefaultQuery("path", "/")
+
+	browser, ok := browseVolume(c)
+	if !ok {
+		return
+	}
+	defer browser.Close()
+
+	files, truncated, err := browser.List(c.Request.Context(), dirPath)
+	if err != nil {
+		ServerError(c, "浏览卷目录失败: "+err.Error())
+		return
+	}
+
+	Success(c, gin.H{
+		"path":      path.Clean("/" + dirPath),
+		"files":     files,
+		"truncated": truncated,
+	})
+}
+
+// ReadVolumeFile 查看卷中小文件的内容
+// 文本文件以 utf-8 返回，二进制文件以 base64 返回
+func ReadVolumeFile(c *gin.Context) {
+	filePath := c.Query("path")
+	if filePath == "" {
+		BadRequest(c, "path 参数不能为空")
+		return
+	}
+
+	browser, ok := browseVolume(c)
+	if !ok {
+		return
+	}
+	defer browser.Close()
+
+	reader, stat, err := browser.ReadFile(c.Request.Context(), filePath)
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+	defer reader.Close()
+
+	if stat.Size > maxVolumeFileRead {
+		BadRequest(c, fmt.Sprintf("文件大小超过 %d 字节，请下载后查看", maxVolumeFileRead))
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxVolumeFileRead))
+	if err != nil {
+		ServerError(c, "读取文件失败: "+err.Error())
+		return
+	}
+
+	content, encoding := string(data), "utf-8"
+	if !utf8.Valid(data) {
+		content, encoding = base64.StdEncoding.EncodeToString(data), "base64"
+	}
+
+	Success(c, gin.H{
+		"path":     stat.Path,
+		"size":     stat.Size,
+		"mode":     stat.Mode,
+		"mod_time": stat.ModTime,
+		"encoding": encoding,
+		"content":  content,
+	})
+}
+
+// DownloadVolumeFiles 下载卷中的文件或目录
+// format=tar（默认）返回 tar 归档；format=raw 直接返回普通文件的内容
+func DownloadVolumeFiles(c *gin.Context) {
+	name := c.Param("name")
+	srcPath := c.DefaultQuery("path", "/")
+	format := c.DefaultQuery("format", "tar")
+
+	if format != "tar" && format != "raw" {
+		BadRequest(c, "format 只能是 tar 或 raw")
+		return
+	}
+
+	browser, ok := browseVolume(c)
+	if !ok {
+		return
+	}
+	defer browser.Close()
+
+	disableWriteTimeout(c)
+	setAuditMessage(c, "下载卷 "+name+" 中的 "+srcPath)
+
+	if format == "raw" {
+		reader, stat, err := browser.ReadFile(c.Request.Context(), srcPath)
+		if err != nil {
+			ServerError(c, err.Error())
+			return
+		}
+		defer reader.Close()
+
+		c.DataFromReader(http.StatusOK, stat.Size, "application/octet-stream", reader, map[string]string{
+			"Content-Disposition": attachmentHeader(stat.Name),
+		})
+		return
+	}
+
+	reader, stat, err := browser.CopyFrom(c.Request.Context(), srcPath)
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+	defer reader.Close()
+
+	filename := stat.Name + ".tar"
+	if stat.Path == "/" {
+		filename = name + ".tar"
+	}
+	c.DataFromReader(http.StatusOK, -1, "application/x-tar", reader, map[string]string{
+		"Content-Disposition": attachmentHeader(filename),
+	})
+}
+
+// ListVolumeUsage 获取所有卷的磁盘占用和挂载情况
+func ListVolumeUsage(c *gin.Context) {
+	svc, ok := getVolumeService(c)
+	if !ok {
+		return
+	}
+
+	usage, err := svc.Usage(c.Request.Context())
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	Success(c, usage)
+}
+
+// GetVolumeUsage 获取单个卷的磁盘占用和挂载情况
+func GetVolumeUsage(c *gin.Context) {
+	name := c.Param("name")
+
+	svc, ok := getVolumeService(c)
+	if !ok {
+		return
+	}
+
+	usage, err := svc.Usage(c.Request.Context())
+	if err != nil {
+		ServerError(c, err.Error())
+		return
+	}
+
+	for _, u := range usage {
+		if u.Name == name {
+			Success(c, u)
+			return
+		}
+	}
+	NotFound(c, "卷不存在: "+name)
+}
@@ -1,8 +1,12 @@
 package handler
 
 import (
+	"context"
+
 	"rubick/internal/docker"
+	"rubick/internal/inventory"
 
+	"github.com/docker/docker/client"
 	"github.com/gin-gonic/gin"
 )
 
@@ -10,6 +14,13 @@ import (
 func ListVolumes(c *gin.Context) {
 	hostID := c.Query("host_id")
 
+	if isFleetQuery(c) {
+		listAllHosts(c, func(ctx context.Context, cli *client.Client) ([]docker.VolumeInfo, error) {
+			return docker.NewVolumeService(cli).List(ctx)
+		})
+		return
+	}
+
 	// 获取主机
 	host, err := getHost(hostID)
 	if err != nil {
@@ -17,10 +28,14 @@ func ListVolumes(c *gin.Context) {
 		return
 	}
 
+	if serveInventory(c, host.ID, "volumes", func(s *inventory.Snapshot) interface{} { return s.Volumes }) {
+		return
+	}
+
 	// 获取 Docker 客户端
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -47,7 +62,7 @@ func GetVolume(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -78,7 +93,7 @@ func CreateVolume(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -89,6 +104,8 @@ func CreateVolume(c *gin.Context) {
 		return
 	}
 
+	inventory.GetInventory().Invalidate(host, inventory.TypeVolume)
+
 	Success(c, info)
 }
 
@@ -106,7 +123,7 @@ func RemoveVolume(c *gin.Context) {
 
 	cli, err := getClient(c.Request.Context(), host)
 	if err != nil {
-		ServerError(c, "获取 Docker 客户端失败: "+err.Error())
+		clientError(c, err)
 		return
 	}
 
@@ -116,5 +133,6 @@ func RemoveVolume(c *gin.Context) {
 		return
 	}
 
+	inventory.GetInventory().Invalidate(host, inventory.TypeVolume)
 	SuccessWithMessage(c, "卷删除成功", nil)
 }
@@ -4,12 +4,15 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"rubick/internal/docker"
+	"rubick/internal/model"
+	"rubick/internal/recording"
 	"rubick/internal/repository"
 	"time"
 
-	"github.com/docker/docker/api/types/container"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 )
@@ -38,6 +41,12 @@ func ContainerLogsWS(c *gin.Context) {
 		return
 	}
 
+	// 获取 tail 参数，默认 100 行
+	opts, ok := logOptionsFromQuery(c, "100")
+	if !ok {
+		return
+	}
+
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		return
@@ -59,39 +68,16 @@ func ContainerLogsWS(c *gin.Context) {
 	defer executor.Close()
 
 	// 使用 Docker SDK 获取日志
-	manager := docker.GetManager()
-	dockerConn, err := manager.GetClient(c.Request.Context(), host)
+	cli, err := docker.GetManager().GetDockerClient(c.Request.Context(), host)
 	if err != nil {
 		sendWSError(conn, "连接 Docker 失败")
 		return
 	}
 
-	client, err := dockerConn.Connect(c.Request.Context())
-	if err != nil {
-		sendWSError(conn, "连接 Docker 失败")
-		return
-	}
-
-	// 获取 tail 参数，默认 100 行
-	tail := c.DefaultQuery("tail", "100")
-
 	// 获取容器日志流
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	logsReader, err := client.ContainerLogs(ctx, containerID, container.LogsOptions{
-		ShowStdout: true,
-		ShowStderr: true,
-		Follow:     true,
-		Tail:       tail,
-		Timestamps: false,
-	})
-	if err != nil {
-		sendWSError(conn, "获取日志失败")
-		return
-	}
-	defer logsReader.Close()
-
 	// 启动心跳协程
 	go func() {
 		ticker := time.NewTicker(30 * time.Second)
@@ -109,15 +95,18 @@ func ContainerLogsWS(c *gin.Context) {
 		}
 	}()
 
-	// 读取日志并发送到 WebSocket
-	scanner := bufio.NewScanner(logsReader)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := conn.WriteJSON(WebSocketMessage{
+	// 读取日志并发送到 WebSocket，stdout/stderr 已按流拆分
+	opts.Follow = true
+	err = docker.NewContainerService(cli).StreamLogs(ctx, containerID, opts, func(line docker.LogLine) error {
+		return conn.WriteJSON(WebSocketMessage{
 			Type:    "log",
-			Content: line,
-		}); err != nil {
-			break
+			Content: line.String(),
+		})
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) {
+			sendWSError(conn, "获取日志失败: "+err.Error())
 		}
 	}
 
@@ -249,8 +238,38 @@ func ContainerExecWS(c *gin.Context) {
 
 	// 连接到 exec 实例
 	svc := docker.NewContainerService(client)
+	inspect, err := svc.ExecInspect(c.Request.Context(), execID)
+	if err != nil {
+		sendWSError(conn, "执行命令不存在")
+		return
+	}
+
+	// 每个终端会话都需要录像，无法录制时拒绝连接
+	rec := &model.TerminalRecording{
+		HostID:      host.ID,
+		ContainerID: inspect.ContainerID,
+		ExecID:      execID,
+		ClientIP:    c.ClientIP(),
+		UserAgent:   c.Request.UserAgent(),
+	}
+	if info, err := svc.Get(c.Request.Context(), inspect.ContainerID); err == nil {
+		rec.ContainerName = info.Name
+	}
+	session, err := recording.Start(rec)
+	if err != nil {
+		sendWSError(conn, "开始录制会话失败: "+err.Error())
+		return
+	}
+	setAuditRecording(c, rec.ID)
+	if rec.Command != "" {
+		setAuditMessage(c, "终端会话 "+rec.ContainerName+": "+rec.Command)
+	} else {
+		setAuditMessage(c, "终端会话 "+rec.ContainerName)
+	}
+
 	hijackedResp, err := svc.ExecAttach(c.Request.Context(), execID)
 	if err != nil {
+		session.Finish(nil)
 		sendWSError(conn, "连接终端失败: "+err.Error())
 		return
 	}
@@ -259,8 +278,11 @@ func ContainerExecWS(c *gin.Context) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// 从 Docker 读取输出并发送到 WebSocket
+	// 从 Docker 读取输出并发送到 WebSocket，命令退出后关闭连接
+	outputDone := make(chan struct{})
 	go func() {
+		defer close(outputDone)
+		defer conn.Close()
 		buf := make([]byte, 1024)
 		for {
 			select {
@@ -268,15 +290,16 @@ func ContainerExecWS(c *gin.Context) {
 				return
 			default:
 				n, err := hijackedResp.Reader.Read(buf)
-				if err != nil {
-					return
-				}
 				if n > 0 {
+					session.Output(buf[:n])
 					conn.WriteJSON(ExecWebSocketMessage{
 						Type:    "data",
 						Content: string(buf[:n]),
 					})
 				}
+				if err != nil {
+					return
+				}
 			}
 		}
 	}()
@@ -295,13 +318,30 @@ func ContainerExecWS(c *gin.Context) {
 
 		switch execMsg.Type {
 		case "input":
+			session.Input([]byte(execMsg.Content))
 			hijackedResp.Conn.Write([]byte(execMsg.Content))
 		case "resize":
 			if execMsg.Cols > 0 && execMsg.Rows > 0 {
+				session.Resize(execMsg.Cols, execMsg.Rows)
 				svc.ExecResize(ctx, execID, uint(execMsg.Cols), uint(execMsg.Rows))
 			}
 		}
 	}
+
+	// 等待输出读取结束后再保存录像，保证录像包含全部输出
+	cancel()
+	hijackedResp.Close()
+	<-outputDone
+
+	var exitCode *int
+	inspectCtx, inspectCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer inspectCancel()
+	if inspect, err := svc.ExecInspect(inspectCtx, execID); err == nil && !inspect.Running {
+		exitCode = &inspect.ExitCode
+	}
+	if err := session.Finish(exitCode); err != nil {
+		log.Printf("保存终端会话录像 %s 失败: %v", rec.ID, err)
+	}
 }
 
 func sendWSError(conn *websocket.Conn, message string) {
@@ -0,0 +1,164 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"rubick/internal/docker"
+	"rubick/internal/model"
+)
+
+// 变更类型
+const (
+	ActionUpsert = "upsert"
+	ActionRemove = "remove"
+)
+
+// Change 清单中单个资源的变更
+type Change struct {
+	Revision uint64      `json:"revision"`
+	Type     string      `json:"type"` // container, image, volume, network
+	Action   string      `json:"action"`
+	ID       string      `json:"id"`
+	Item     interface{} `json:"item,omitempty"` // 变更后的资源，删除时为空
+	Time     time.Time   `json:"time"`
+}
+
+// item 清单中的单个资源
+type item struct {
+	id    string
+	hash  uint64
+	value interface{}
+}
+
+// collect 从主机读取一类资源
+func collect(ctx context.Context, host *model.Host, kind string) ([]item, error) {
+	cli, err := docker.GetManager().GetDockerClient(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []item
+	switch kind {
+	case TypeContainer:
+		containers, err := docker.NewContainerService(cli).List(ctx, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range containers {
+			// 状态描述（如 Up 5 minutes）随时间变化，不计入变更
+			hashed := c
+			hashed.Status = ""
+			items = append(items, item{id: c.ID, hash: hashOf(hashed), value: c})
+		}
+	case TypeImage:
+		images, err := docker.NewImageService(cli).List(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, img := range images {
+			items = append(items, item{id: img.ID, hash: hashOf(img), value: img})
+		}
+	case TypeVolume:
+		volumes, err := docker.NewVolumeService(cli).List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range volumes {
+			items = append(items, item{id: v.Name, hash: hashOf(v), value: v})
+		}
+	case TypeNetwork:
+		networks, err := docker.NewNetworkService(cli).List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range networks {
+			items = append(items, item{id: n.ID, hash: hashOf(n), value: n})
+		}
+	}
+	return items, nil
+}
+
+// apply 用新读取的资源替换清单中的一类资源并记录变更，调用方需持有写锁
+// 首次同步之前不记录变更，只更新版本号
+func (i *Inventory) apply(state *hostState, kind string, items []item) {
+	initial := state.snapshot.RefreshedAt.IsZero()
+	previous := state.hashes[kind]
+	now := time.Now()
+
+	hashes := make(map[string]uint64, len(items))
+	var changes []Change
+	for _, it := range items {
+		hashes[it.id] = it.hash
+		if h, ok := previous[it.id]; !ok || h != it.hash {
+			changes = append(changes, Change{Type: kind, Action: ActionUpsert, ID: it.id, Item: it.value, Time: now})
+		}
+	}
+	var removed []string
+	for id := range previous {
+		if _, ok := hashes[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+	for _, id := range removed {
+		changes = append(changes, Change{Type: kind, Action: ActionRemove, ID: id, Time: now})
+	}
+	state.hashes[kind] = hashes
+
+	switch kind {
+	case TypeContainer:
+		list := make([]docker.ContainerInfo, 0, len(items))
+		for _, it := range items {
+			list = append(list, it.value.(docker.ContainerInfo))
+		}
+		state.snapshot.Containers = list
+	case TypeImage:
+		list := make([]docker.ImageInfo, 0, len(items))
+		for _, it := range items {
+			list = append(list, it.value.(docker.ImageInfo))
+		}
+		state.snapshot.Images = list
+	case TypeVolume:
+		list := make([]docker.VolumeInfo, 0, len(items))
+		for _, it := range items {
+			list = append(list, it.value.(docker.VolumeInfo))
+		}
+		state.snapshot.Volumes = list
+	case TypeNetwork:
+		list := make([]docker.DockerNetwork, 0, len(items))
+		for _, it := range items {
+			list = append(list, it.value.(docker.DockerNetwork))
+		}
+		state.snapshot.Networks = list
+	}
+
+	if initial {
+		if state.snapshot.Revision == 0 || len(changes) > 0 {
+			state.snapshot.Revision = i.revision.Add(1)
+		}
+		state.base = state.snapshot.Revision
+		return
+	}
+
+	for idx := range changes {
+		changes[idx].Revision = i.revision.Add(1)
+		state.snapshot.Revision = changes[idx].Revision
+	}
+	state.changes = append(state.changes, changes...)
+	if over := len(state.changes) - maxChanges; over > 0 {
+		state.base = state.changes[over-1].Revision
+		state.changes = append([]Change(nil), state.changes[over:]...)
+	}
+}
+
+// hashOf 计算资源内容的摘要
+func hashOf(v interface{}) uint64 {
+	data, _ := json.Marshal(v)
+	h := fnv.New64a()
+	h.Write(data)
+	return h.Sum64()
+}
@@ -0,0 +1,333 @@
+package inventory
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rubick/internal/config"
+	"rubick/internal/docker"
+	"rubick/internal/model"
+	"rubick/internal/repository"
+)
+
+// maxChanges 每台主机保留的变更记录数，更早的变更需要全量同步
+const maxChanges = 1000
+
+// Snapshot 单台主机的资源清单
+type Snapshot struct {
+	HostID      string                 `json:"host_id"`
+	HostName    string                 `json:"host_name"`
+	Revision    uint64                 `json:"revision"` // 清单版本，任一资源变化时递增
+	Containers  []docker.ContainerInfo `json:"containers"`
+	Images      []docker.ImageInfo     `json:"images"`
+	Volumes     []docker.VolumeInfo    `json:"volumes"`
+	Networks    []docker.DockerNetwork `json:"networks"`
+	Watching    bool                   `json:"watching"`            // 是否正在接收 Docker 事件
+	RefreshedAt time.Time              `json:"refreshed_at"`        // 最近一次成功全量同步的时间
+	Error       string                 `json:"error,omitempty"`     // 最近一次同步失败的原因，清单保留上次成功的数据
+	FailedAt    *time.Time             `json:"failed_at,omitempty"` // 最近一次同步失败的时间
+}
+
+// Inventory 所有主机的资源清单缓存
+// 通过 Docker 事件流增量更新，并定期全量同步
+type Inventory struct {
+	mu        sync.RWMutex
+	hosts     map[string]*hostState
+	revision  atomic.Uint64 // 全局递增的版本号
+	refreshMu sync.Mutex    // 同一时间只进行一轮全量同步
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+}
+
+// hostState 单台主机的缓存状态
+type hostState struct {
+	snapshot Snapshot
+	hashes   map[string]map[string]uint64 // 资源类型 -> ID -> 内容摘要
+	changes  []Change
+	base     uint64 // 从该版本之后的变更是完整的
+	stale    bool   // 修改资源后尚未重新读取或读取失败，不能作为最新数据
+
+	invalidations uint64 // Invalidate 的调用次数，用于判断后台读取是否为最近一次修改之后
+
+	syncMu      sync.Mutex // 串行化同一主机的读取，避免旧数据覆盖新数据
+	watchHost   time.Time  // 事件监听使用的主机配置的更新时间
+	watchCancel context.CancelFunc
+}
+
+var (
+	inventory     *Inventory
+	inventoryOnce sync.Once
+)
+
+// GetInventory 获取资源清单缓存单例
+func GetInventory() *Inventory {
+	inventoryOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		inventory = &Inventory{
+			hosts:  make(map[string]*hostState),
+			ctx:    ctx,
+			cancel: cancel,
+		}
+	})
+	return inventory
+}
+
+// Start 启动定期全量同步
+func (i *Inventory) Start() {
+	interval := config.Get().Inventory.Interval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			i.Refresh(i.ctx)
+			select {
+			case <-i.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止同步和事件监听
+func (i *Inventory) Stop() {
+	i.cancel()
+	i.wg.Wait()
+}
+
+// Refresh 全量同步所有启用主机的清单
+// 同时为新主机启动事件监听，并移除已删除或停用的主机
+func (i *Inventory) Refresh(ctx context.Context) {
+	i.refreshMu.Lock()
+	defer i.refreshMu.Unlock()
+
+	hosts, err := repository.ListHosts()
+	if err != nil {
+		log.Printf("同步资源清单获取主机列表失败: %v", err)
+		return
+	}
+
+	active := make(map[string]bool)
+	var wg sync.WaitGroup
+	for idx := range hosts {
+		host := &hosts[idx]
+		if !host.IsActive {
+			continue
+		}
+		active[host.ID] = true
+		i.ensureWatch(host)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			i.RefreshHost(ctx, host)
+		}()
+	}
+	wg.Wait()
+
+	i.mu.Lock()
+	for id, state := range i.hosts {
+		if !active[id] {
+			if state.watchCancel != nil {
+				state.watchCancel()
+			}
+			delete(i.hosts, id)
+		}
+	}
+	i.mu.Unlock()
+}
+
+// RefreshHost 全量同步单台主机的清单，失败时保留上次成功的数据并记录错误
+func (i *Inventory) RefreshHost(ctx context.Context, host *model.Host) error {
+	state := i.state(host)
+	state.syncMu.Lock()
+	defer state.syncMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, refreshTimeout())
+	defer cancel()
+
+	kinds := []string{TypeContainer, TypeImage, TypeVolume, TypeNetwork}
+	items := make(map[string][]item, len(kinds))
+	var err error
+	for _, kind := range kinds {
+		if items[kind], err = collect(ctx, host, kind); err != nil {
+			break
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	state.snapshot.HostName = host.Name
+	if err != nil {
+		now := time.Now()
+		state.snapshot.Error = err.Error()
+		state.snapshot.FailedAt = &now
+		return err
+	}
+
+	for _, kind := range kinds {
+		i.apply(state, kind, items[kind])
+	}
+	state.snapshot.RefreshedAt = time.Now()
+	state.stale = false
+	state.snapshot.Error = ""
+	state.snapshot.FailedAt = nil
+	return nil
+}
+
+// refreshKind 重新读取单台主机的一类资源，用于处理 Docker 事件
+func (i *Inventory) refreshKind(ctx context.Context, host *model.Host, kind string) error {
+	i.mu.RLock()
+	state, ok := i.hosts[host.ID]
+	i.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	state.syncMu.Lock()
+	defer state.syncMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, refreshTimeout())
+	defer cancel()
+
+	items, err := collect(ctx, host, kind)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.apply(state, kind, items)
+	return nil
+}
+
+// Invalidate 将主机的清单标记为过期，并在后台重新读取指定的资源类型
+// 用于通过本服务修改资源之后：读取完成之前 Get 返回的 live 为 false，列表请求直接查询 Docker，
+// 避免 Docker 事件到达之前返回旧数据，同时不阻塞修改请求；读取失败时保持过期，直到下一次全量同步成功
+func (i *Inventory) Invalidate(host *model.Host, kinds ...string) {
+	i.mu.Lock()
+	state, ok := i.hosts[host.ID]
+	if !ok {
+		i.mu.Unlock()
+		return
+	}
+	state.stale = true
+	state.invalidations++
+	gen := state.invalidations
+	i.mu.Unlock()
+
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		for _, kind := range kinds {
+			if err := i.refreshKind(i.ctx, host, kind); err != nil {
+				log.Printf("刷新主机 %s 的资源清单失败: %v", host.Name, err)
+				return
+			}
+		}
+
+		// 期间有新的修改时由后一次读取清除过期标记
+		i.mu.Lock()
+		if state.invalidations == gen {
+			state.stale = false
+		}
+		i.mu.Unlock()
+	}()
+}
+
+// Snapshots 获取所有主机的清单
+// 清单中的切片在同步时整体替换，调用方只能读取
+func (i *Inventory) Snapshots() []Snapshot {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	result := make([]Snapshot, 0, len(i.hosts))
+	for _, state := range i.hosts {
+		result = append(result, state.snapshot)
+	}
+	return result
+}
+
+// Get 获取主机的清单
+// 只有正在接收事件且至少完成过一次全量同步的清单才是最新的，live 为 false 时调用方应直接查询 Docker
+func (i *Inventory) Get(hostID string) (snapshot Snapshot, live bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	state, ok := i.hosts[hostID]
+	if !ok {
+		return Snapshot{}, false
+	}
+	s := state.snapshot
+	return s, s.Watching && !s.RefreshedAt.IsZero() && !state.stale
+}
+
+// Changes 获取主机在 since 版本之后的变更
+// since 早于保留的变更记录时 complete 为 false，调用方需要重新获取完整清单
+func (i *Inventory) Changes(hostID string, since uint64) (changes []Change, revision uint64, complete bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	state, ok := i.hosts[hostID]
+	if !ok || state.snapshot.RefreshedAt.IsZero() {
+		return nil, 0, false
+	}
+	revision = state.snapshot.Revision
+	if since < state.base || since > revision {
+		return nil, revision, false
+	}
+
+	changes = []Change{}
+	for _, change := range state.changes {
+		if change.Revision > since {
+			changes = append(changes, change)
+		}
+	}
+	return changes, revision, true
+}
+
+// state 获取或创建主机的缓存状态
+func (i *Inventory) state(host *model.Host) *hostState {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	state, ok := i.hosts[host.ID]
+	if !ok {
+		state = &hostState{
+			snapshot: Snapshot{HostID: host.ID, HostName: host.Name},
+			hashes:   make(map[string]map[string]uint64),
+		}
+		i.hosts[host.ID] = state
+	}
+	return state
+}
+
+// setWatching 更新主机的事件监听状态
+func (i *Inventory) setWatching(hostID string, watching bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if state, ok := i.hosts[hostID]; ok {
+		state.snapshot.Watching = watching
+	}
+}
+
+// refreshTimeout 单台主机同步的超时时间
+func refreshTimeout() time.Duration {
+	timeout := config.Get().Inventory.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return timeout
+}
@@ -0,0 +1,270 @@
+package inventory
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"rubick/internal/model"
+)
+
+// 搜索结果类型
+const (
+	TypeContainer = "container"
+	TypeImage     = "image"
+	TypeVolume    = "volume"
+	TypeNetwork   = "network"
+	TypeCompose   = "compose"
+)
+
+// 匹配得分，同一资源取最高得分的字段
+const (
+	scoreExact    = 100
+	scoreIDPrefix = 90
+	scorePrefix   = 80
+	scoreContains = 60
+	// 非名称字段（镜像、标签）在名称得分基础上降低
+	secondaryPenalty = 30
+)
+
+// minIDPrefix ID 前缀匹配的最小长度，避免短查询匹配大量 ID
+const minIDPrefix = 3
+
+// SearchOptions 搜索选项
+type SearchOptions struct {
+	Types   []string        // 为空表示全部类型
+	HostIDs map[string]bool // 为空表示全部主机
+	Limit   int
+}
+
+// Result 搜索结果
+type Result struct {
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	HostID   string `json:"host_id"`
+	HostName string `json:"host_name"`
+	Score    int    `json:"score"`
+	Match    string `json:"match"`            // 匹配的字段，如 name、image、label:key
+	Detail   string `json:"detail,omitempty"` // 附加信息，如容器状态和镜像
+	Link     string `json:"link"`             // 资源详情的 API 路径
+}
+
+// ValidType 判断是否为支持的结果类型
+func ValidType(t string) bool {
+	switch t {
+	case TypeContainer, TypeImage, TypeVolume, TypeNetwork, TypeCompose:
+		return true
+	}
+	return false
+}
+
+// Search 在资源清单和 Compose 项目中搜索，按得分从高到低排序
+// 返回截断到 Limit 条的结果和截断前的匹配总数
+func Search(query string, snapshots []Snapshot, projects []model.ComposeProject, opts SearchOptions) ([]Result, int) {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return []Result{}, 0
+	}
+
+	want := func(t string) bool {
+		if len(opts.Types) == 0 {
+			return true
+		}
+		for _, v := range opts.Types {
+			if v == t {
+				return true
+			}
+		}
+		return false
+	}
+	wantHost := func(id string) bool {
+		return len(opts.HostIDs) == 0 || opts.HostIDs[id]
+	}
+
+	results := []Result{}
+	add := func(r Result, m match) {
+		if m.score == 0 {
+			return
+		}
+		r.Score = m.score
+		r.Match = m.field
+		results = append(results, r)
+	}
+
+	for _, s := range snapshots {
+		if !wantHost(s.HostID) {
+			continue
+		}
+		hostQuery := "?host_id=" + url.QueryEscape(s.HostID)
+
+		if want(TypeContainer) {
+			for _, c := range s.Containers {
+				m := best(
+					matchName(q, c.Name, "name"),
+					matchID(q, c.ID),
+					secondary(matchName(q, c.Image, "image")),
+					secondary(matchLabels(q, c.Labels)),
+				)
+				add(Result{
+					Type: TypeContainer, ID: c.ID, Name: c.Name,
+					HostID: s.HostID, HostName: s.HostName,
+					Detail: strings.TrimSpace(c.State + " " + c.Image),
+					Link:   "/api/v1/containers/" + c.ID + hostQuery,
+				}, m)
+			}
+		}
+
+		if want(TypeImage) {
+			for _, img := range s.Images {
+				name := strings.TrimPrefix(img.ID, "sha256:")
+				if len(img.RepoTags) > 0 {
+					name = img.RepoTags[0]
+				}
+				candidates := []match{
+					matchID(q, strings.TrimPrefix(img.ID, "sha256:")),
+					secondary(matchLabels(q, img.Labels)),
+				}
+				for _, tag := range img.RepoTags {
+					candidates = append(candidates, matchName(q, tag, "name"))
+				}
+				add(Result{
+					Type: TypeImage, ID: img.ID, Name: name,
+					HostID: s.HostID, HostName: s.HostName,
+					Link: "/api/v1/images/" + url.PathEscape(img.ID) + hostQuery,
+				}, best(candidates...))
+			}
+		}
+
+		if want(TypeVolume) {
+			for _, v := range s.Volumes {
+				m := best(
+					matchName(q, v.Name, "name"),
+					secondary(matchLabels(q, v.Labels)),
+				)
+				add(Result{
+					Type: TypeVolume, ID: v.Name, Name: v.Name,
+					HostID: s.HostID, HostName: s.HostName,
+					Detail: v.Driver,
+					Link:   "/api/v1/volumes/" + url.PathEscape(v.Name) + hostQuery,
+				}, m)
+			}
+		}
+
+		if want(TypeNetwork) {
+			for _, n := range s.Networks {
+				m := best(
+					matchName(q, n.Name, "name"),
+					matchID(q, n.ID),
+					secondary(matchLabels(q, n.Labels)),
+				)
+				add(Result{
+					Type: TypeNetwork, ID: n.ID, Name: n.Name,
+					HostID: s.HostID, HostName: s.HostName,
+					Detail: n.Driver,
+					Link:   "/api/v1/networks/" + n.ID + hostQuery,
+				}, m)
+			}
+		}
+	}
+
+	if want(TypeCompose) {
+		for _, p := range projects {
+			if !wantHost(p.HostID) {
+				continue
+			}
+			hostName := ""
+			if p.Host != nil {
+				hostName = p.Host.Name
+			}
+			add(Result{
+				Type: TypeCompose, ID: p.ID, Name: p.Name,
+				HostID: p.HostID, HostName: hostName,
+				Detail: p.Status,
+				Link:   "/api/v1/compose/projects/" + p.ID,
+			}, matchName(q, p.Name, "name"))
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].HostName < results[j].HostName
+	})
+
+	total := len(results)
+	if opts.Limit > 0 && total > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results, total
+}
+
+// match 字段匹配结果，score 为 0 表示不匹配
+type match struct {
+	score int
+	field string
+}
+
+// best 取得分最高的匹配
+func best(matches ...match) match {
+	var result match
+	for _, m := range matches {
+		if m.score > result.score {
+			result = m
+		}
+	}
+	return result
+}
+
+// secondary 降低非名称字段的得分
+func secondary(m match) match {
+	if m.score > 0 {
+		m.score -= secondaryPenalty
+	}
+	return m
+}
+
+// matchName 按完全相同、前缀、包含的顺序匹配名称，忽略大小写
+func matchName(q, name, field string) match {
+	name = strings.ToLower(strings.TrimPrefix(name, "/"))
+	switch {
+	case name == "":
+		return match{}
+	case name == q:
+		return match{scoreExact, field}
+	case strings.HasPrefix(name, q):
+		return match{scorePrefix, field}
+	case strings.Contains(name, q):
+		return match{scoreContains, field}
+	}
+	return match{}
+}
+
+// matchID 匹配 ID 前缀
+func matchID(q, id string) match {
+	if len(q) >= minIDPrefix && strings.HasPrefix(strings.ToLower(id), q) {
+		return match{scoreIDPrefix, "id"}
+	}
+	return match{}
+}
+
+// matchLabels 匹配标签的键、值或 key=value
+func matchLabels(q string, labels map[string]string) match {
+	var result match
+	for k, v := range labels {
+		m := best(
+			matchName(q, k, "label:"+k),
+			matchName(q, v, "label:"+k),
+			matchName(q, k+"="+v, "label:"+k),
+		)
+		// 得分相同时取键名较小者，保证结果稳定
+		if m.score > result.score || (m.score == result.score && m.score > 0 && m.field < result.field) {
+			result = m
+		}
+	}
+	return result
+}
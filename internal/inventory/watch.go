@@ -0,0 +1,154 @@
+package inventory
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"rubick/internal/docker"
+	"rubick/internal/model"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+const (
+	// eventDebounce 收到事件后等待的时间，合并短时间内的多个事件
+	eventDebounce = 500 * time.Millisecond
+	// maxWatchBackoff 事件流断开后重连的最长等待时间
+	maxWatchBackoff = time.Minute
+)
+
+// ensureWatch 确保主机的事件监听已启动，主机配置变化时重新连接
+func (i *Inventory) ensureWatch(host *model.Host) {
+	state := i.state(host)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if state.watchCancel != nil && state.watchHost.Equal(host.UpdatedAt) {
+		return
+	}
+	if state.watchCancel != nil {
+		state.watchCancel()
+	}
+
+	ctx, cancel := context.WithCancel(i.ctx)
+	state.watchCancel = cancel
+	state.watchHost = host.UpdatedAt
+
+	watched := *host
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		i.watch(ctx, &watched)
+	}()
+}
+
+// watch 持续监听主机的 Docker 事件，断开后按指数退避重连
+func (i *Inventory) watch(ctx context.Context, host *model.Host) {
+	backoff := time.Second
+	for {
+		start := time.Now()
+		connected, err := i.watchEvents(ctx, host)
+		i.setWatching(host.ID, false)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			log.Printf("主机 %s 的事件流已断开: %v", host.Name, err)
+		}
+		if time.Since(start) > maxWatchBackoff {
+			backoff = time.Second
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxWatchBackoff {
+			backoff = maxWatchBackoff
+		}
+	}
+}
+
+// watchEvents 订阅事件并在资源变化时重新读取对应类型，connected 表示订阅是否成功
+func (i *Inventory) watchEvents(ctx context.Context, host *model.Host) (connected bool, err error) {
+	cli, err := docker.GetManager().GetDockerClient(ctx, host)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	args := filters.NewArgs(
+		filters.Arg("type", string(events.ContainerEventType)),
+		filters.Arg("type", string(events.ImageEventType)),
+		filters.Arg("type", string(events.VolumeEventType)),
+		filters.Arg("type", string(events.NetworkEventType)),
+	)
+	messages, errs := cli.Events(ctx, events.ListOptions{Filters: args})
+
+	// 订阅后全量同步一次，补上订阅之前错过的变化
+	if err := i.RefreshHost(ctx, host); err != nil {
+		return false, err
+	}
+	i.setWatching(host.ID, true)
+
+	pending := make(map[string]bool)
+	var fire <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case err := <-errs:
+			return true, err
+		case msg := <-messages:
+			kind := eventKind(msg)
+			if kind == "" {
+				continue
+			}
+			pending[kind] = true
+			if fire == nil {
+				fire = time.After(eventDebounce)
+			}
+		case <-fire:
+			fire = nil
+			for kind := range pending {
+				if err := i.refreshKind(ctx, host, kind); err != nil {
+					return true, err
+				}
+				delete(pending, kind)
+			}
+		}
+	}
+}
+
+// eventKind 返回事件影响的资源类型，不影响清单的事件返回空字符串
+func eventKind(msg events.Message) string {
+	action := string(msg.Action)
+	switch msg.Type {
+	case events.ContainerEventType:
+		if strings.HasPrefix(action, "exec_") || action == "top" || action == "attach" {
+			return ""
+		}
+		return TypeContainer
+	case events.ImageEventType:
+		return TypeImage
+	case events.VolumeEventType:
+		if action == "mount" || action == "unmount" {
+			return ""
+		}
+		return TypeVolume
+	case events.NetworkEventType:
+		// 连接和断开改变的是容器的网络信息
+		if action == "connect" || action == "disconnect" {
+			return TypeContainer
+		}
+		return TypeNetwork
+	}
+	return ""
+}
@@ -0,0 +1,551 @@
+package logstore
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"rubick/internal/config"
+	"rubick/internal/docker"
+	"rubick/internal/model"
+	"rubick/internal/repository"
+)
+
+const (
+	// initialTail 首次收集容器日志时读取的历史行数
+	initialTail = "1000"
+	// batchSize 批量写入的最大行数
+	batchSize = 500
+	// flushInterval 未满一批时的写入间隔
+	flushInterval = time.Second
+	// pruneInterval 按保留时长和大小上限清理日志的间隔
+	pruneInterval = 5 * time.Minute
+	// listTimeout 列出单台主机容器的超时时间
+	listTimeout = 15 * time.Second
+)
+
+// Target 正在收集日志的容器
+type Target struct {
+	HostID        string    `json:"host_id"`
+	HostName      string    `json:"host_name"`
+	ContainerID   string    `json:"container_id"`
+	ContainerName string    `json:"container_name"`
+	Project       string    `json:"project,omitempty"`
+	Service       string    `json:"service,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+
+	labels map[string]string
+	cancel context.CancelFunc
+}
+
+// Status 日志收集状态
+type Status struct {
+	Enabled    bool     `json:"enabled"` // 是否写入本地存储
+	Selectors  []string `json:"selectors"`
+	Forwarders int      `json:"forwarders"` // 运行中的转发器数量
+	Targets    []Target `json:"targets"`
+	Error      string   `json:"error,omitempty"` // 配置错误
+}
+
+// Collector 跟踪各主机上选中容器的日志，写入本地存储并分发到转发器
+// 满足存储选择器或任一转发器条件的运行中容器会被跟踪
+type Collector struct {
+	mu         sync.Mutex
+	targets    map[string]*Target   // 容器 ID -> 正在收集的容器
+	cursors    map[string]time.Time // 容器 ID -> 已收集的最新日志时间
+	selectors  []Selector
+	store      bool // 是否写入本地存储
+	err        error
+	forwarders map[string]*forwarder // 转发器 ID -> 运行中的转发器
+	entries    chan model.LogEntry
+	written    chan struct{}
+	trigger    chan struct{} // 转发器变更后立即同步
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+var (
+	collector     *Collector
+	collectorOnce sync.Once
+)
+
+// GetCollector 获取日志收集器单例
+func GetCollector() *Collector {
+	collectorOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		collector = &Collector{
+			targets:    make(map[string]*Target),
+			cursors:    make(map[string]time.Time),
+			forwarders: make(map[string]*forwarder),
+			entries:    make(chan model.LogEntry, 4*batchSize),
+			written:    make(chan struct{}),
+			trigger:    make(chan struct{}, 1),
+			ctx:        ctx,
+			cancel:     cancel,
+		}
+	})
+	return collector
+}
+
+// Start 启动日志收集和转发器
+func (c *Collector) Start() {
+	cfg := config.Get().LogStore
+
+	c.selectors, c.err = ParseSelectors(cfg.Selectors)
+	if c.err != nil {
+		log.Printf("日志收集配置无效: %v", c.err)
+	}
+	c.store = cfg.Enabled && c.err == nil
+
+	if err := c.Reload(); err != nil {
+		log.Printf("加载日志转发器失败: %v", err)
+	}
+
+	go c.writeLoop()
+
+	interval := cfg.SyncInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var lastPrune time.Time
+		for {
+			c.sync()
+			if time.Since(lastPrune) >= pruneInterval {
+				c.prune()
+				lastPrune = time.Now()
+			}
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			case <-c.trigger:
+			}
+		}
+	}()
+}
+
+// Stop 停止收集，等待缓冲中的日志写入存储并发送到转发器
+func (c *Collector) Stop() {
+	c.cancel()
+	c.wg.Wait()
+	close(c.entries)
+	<-c.written
+
+	c.mu.Lock()
+	forwarders := c.forwarders
+	c.forwarders = make(map[string]*forwarder)
+	c.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, f := range forwarders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.stop()
+		}()
+	}
+	wg.Wait()
+}
+
+// Reload 重新加载启用的转发器，配置未变化的转发器继续运行
+func (c *Collector) Reload() error {
+	configs, err := repository.ListEnabledLogForwarders()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	current := make(map[string]*forwarder, len(configs))
+	for _, cfg := range configs {
+		if f, ok := c.forwarders[cfg.ID]; ok && f.cfg.UpdatedAt.Equal(cfg.UpdatedAt) {
+			current[cfg.ID] = f
+			continue
+		}
+		f, err := newForwarder(cfg)
+		if err != nil {
+			log.Printf("启动日志转发器 %s 失败: %v", cfg.Name, err)
+			continue
+		}
+		current[cfg.ID] = f
+	}
+	var stale []*forwarder
+	for id, f := range c.forwarders {
+		if current[id] != f {
+			stale = append(stale, f)
+		}
+	}
+	c.forwarders = current
+	c.mu.Unlock()
+
+	for _, f := range stale {
+		go f.stop()
+	}
+
+	select {
+	case c.trigger <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// ForwarderStatus 获取转发器的运行状态，转发器未运行时返回 nil
+func (c *Collector) ForwarderStatus(id string) *model.LogForwarderStatus {
+	c.mu.Lock()
+	f, ok := c.forwarders[id]
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	status := f.Status()
+	return &status
+}
+
+// Status 获取收集状态
+func (c *Collector) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := Status{
+		Enabled:    c.store,
+		Selectors:  []string{},
+		Forwarders: len(c.forwarders),
+		Targets:    []Target{},
+	}
+	for _, s := range c.selectors {
+		status.Selectors = append(status.Selectors, s.String())
+	}
+	for _, t := range c.targets {
+		status.Targets = append(status.Targets, *t)
+	}
+	sort.Slice(status.Targets, func(i, j int) bool {
+		if status.Targets[i].HostName != status.Targets[j].HostName {
+			return status.Targets[i].HostName < status.Targets[j].HostName
+		}
+		return status.Targets[i].ContainerName < status.Targets[j].ContainerName
+	})
+	if c.err != nil {
+		status.Error = c.err.Error()
+	}
+	return status
+}
+
+// sync 在所有启用的主机上查找需要收集的运行中容器，为尚未跟踪的容器启动跟踪
+// 容器停止后日志流结束，重新启动后在下一轮同步时继续收集；不再需要的容器停止跟踪
+func (c *Collector) sync() {
+	c.mu.Lock()
+	idle := !c.store && len(c.forwarders) == 0
+	c.mu.Unlock()
+
+	active := make(map[string]bool)
+	if !idle {
+		hosts, err := repository.ListHosts()
+		if err != nil {
+			log.Printf("日志收集获取主机列表失败: %v", err)
+			return
+		}
+
+		var wg sync.WaitGroup
+		for i := range hosts {
+			host := hosts[i]
+			if !host.IsActive {
+				continue
+			}
+			active[host.ID] = true
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := c.syncHost(&host); err != nil {
+					log.Printf("日志收集同步主机 %s 失败: %v", host.Name, err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	// 停止已删除或停用主机上的跟踪
+	c.mu.Lock()
+	for _, t := range c.targets {
+		if !active[t.HostID] {
+			t.cancel()
+		}
+	}
+	c.mu.Unlock()
+}
+
+// syncHost 在单台主机上查找需要收集的容器
+func (c *Collector) syncHost(host *model.Host) error {
+	ctx, cancel := context.WithTimeout(c.ctx, listTimeout)
+	defer cancel()
+
+	cli, err := docker.GetManager().GetDockerClient(ctx, host)
+	if err != nil {
+		return err
+	}
+	containers, err := docker.NewContainerService(cli).List(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wanted := make(map[string]bool)
+	for _, ctr := range containers {
+		target := &Target{
+			HostID:        host.ID,
+			HostName:      host.Name,
+			ContainerID:   ctr.ID,
+			ContainerName: strings.TrimPrefix(ctr.Name, "/"),
+			Project:       ctr.Labels[LabelProject],
+			Service:       ctr.Labels[LabelService],
+			StartedAt:     time.Now(),
+			labels:        ctr.Labels,
+		}
+		if !c.storeMatch(target) && len(c.matchingForwarders(target)) == 0 {
+			continue
+		}
+		wanted[ctr.ID] = true
+		if _, ok := c.targets[ctr.ID]; ok {
+			continue
+		}
+
+		var followCtx context.Context
+		followCtx, target.cancel = context.WithCancel(c.ctx)
+		c.targets[ctr.ID] = target
+
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			defer func() {
+				target.cancel()
+				c.mu.Lock()
+				delete(c.targets, target.ContainerID)
+				c.mu.Unlock()
+			}()
+			if err := c.follow(followCtx, *host, target); err != nil && followCtx.Err() == nil {
+				log.Printf("收集容器 %s 日志失败: %v", target.ContainerName, err)
+			}
+		}()
+	}
+
+	for id, t := range c.targets {
+		if t.HostID == host.ID && !wanted[id] {
+			t.cancel()
+		}
+	}
+	return nil
+}
+
+// storeMatch 判断容器日志是否写入本地存储，调用方需持有锁
+func (c *Collector) storeMatch(t *Target) bool {
+	if !c.store {
+		return false
+	}
+	for _, s := range c.selectors {
+		if s.Match(t.labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchingForwarders 获取需要转发容器日志的转发器，调用方需持有锁
+func (c *Collector) matchingForwarders(t *Target) []*forwarder {
+	var result []*forwarder
+	for _, f := range c.forwarders {
+		if f.matches(t) {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// follow 跟踪容器日志直到日志流结束
+// 从已收集的最新日志时间继续读取并跳过不晚于该时间的行，避免重复；
+// 首次跟踪时写入存储的容器读取最近的历史日志，只转发的容器从当前时间开始
+func (c *Collector) follow(ctx context.Context, host model.Host, target *Target) error {
+	cursor, err := c.cursor(target.ContainerID)
+	if err != nil {
+		return err
+	}
+
+	opts := docker.LogOptions{Follow: true, Timestamps: true, Tail: initialTail}
+	if cursor.IsZero() {
+		c.mu.Lock()
+		store := c.storeMatch(target)
+		c.mu.Unlock()
+		if !store {
+			cursor = time.Now()
+		}
+	}
+	if !cursor.IsZero() {
+		opts.Tail = "all"
+		opts.Since = fmt.Sprintf("%d.%09d", cursor.Unix(), cursor.Nanosecond())
+	}
+
+	cli, err := docker.GetManager().GetDockerClient(ctx, &host)
+	if err != nil {
+		return err
+	}
+
+	return docker.NewContainerService(cli).StreamLogs(ctx, target.ContainerID, opts, func(line docker.LogLine) error {
+		t, err := time.Parse(time.RFC3339Nano, line.Timestamp)
+		if err != nil {
+			t = time.Now()
+		}
+		if !t.After(cursor) {
+			return nil
+		}
+		t = t.UTC()
+
+		c.mu.Lock()
+		c.cursors[target.ContainerID] = t
+		store := c.storeMatch(target)
+		forwarders := c.matchingForwarders(target)
+		c.mu.Unlock()
+
+		if len(forwarders) > 0 {
+			record := Record{
+				HostID:        target.HostID,
+				HostName:      target.HostName,
+				ContainerID:   target.ContainerID,
+				ContainerName: target.ContainerName,
+				Project:       target.Project,
+				Service:       target.Service,
+				Stream:        line.Stream,
+				Time:          t,
+				Message:       line.Text,
+			}
+			for _, f := range forwarders {
+				f.enqueue(record)
+			}
+		}
+		if !store {
+			return nil
+		}
+
+		entry := model.LogEntry{
+			HostID:        target.HostID,
+			ContainerID:   target.ContainerID,
+			ContainerName: target.ContainerName,
+			Project:       target.Project,
+			Service:       target.Service,
+			Stream:        line.Stream,
+			Time:          t,
+			Message:       line.Text,
+		}
+		select {
+		case c.entries <- entry:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// cursor 获取容器已收集的最新日志时间，内存中没有记录时从存储读取
+func (c *Collector) cursor(containerID string) (time.Time, error) {
+	c.mu.Lock()
+	t, ok := c.cursors[containerID]
+	c.mu.Unlock()
+	if ok {
+		return t, nil
+	}
+	return repository.LastLogTime(containerID)
+}
+
+// writeLoop 批量写入日志，通道关闭后写入剩余的日志并退出
+func (c *Collector) writeLoop() {
+	defer close(c.written)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]model.LogEntry, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := repository.CreateLogEntries(batch); err != nil {
+			log.Printf("写入日志失败，丢弃 %d 行: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry, ok := <-c.entries:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// prune 删除超过保留时长的日志，存储超过大小上限时继续删除最早的日志
+// 删除后回收空闲页，使数据库文件随之缩小
+func (c *Collector) prune() {
+	cfg := config.Get().LogStore
+	defer func() {
+		if err := repository.ReclaimLogSpace(); err != nil {
+			log.Printf("回收日志存储空间失败: %v", err)
+		}
+	}()
+
+	if cfg.Retention > 0 {
+		cutoff := time.Now().Add(-cfg.Retention)
+		c.mu.Lock()
+		for id, t := range c.cursors {
+			if t.Before(cutoff) && c.targets[id] == nil {
+				delete(c.cursors, id)
+			}
+		}
+		c.mu.Unlock()
+
+		if _, err := repository.DeleteLogEntriesBefore(cutoff); err != nil {
+			log.Printf("清理过期日志失败: %v", err)
+			return
+		}
+	}
+	if cfg.MaxSizeMB <= 0 {
+		return
+	}
+
+	limit := cfg.MaxSizeMB << 20
+	for {
+		stats, err := repository.GetLogStoreStats()
+		if err != nil {
+			log.Printf("获取日志存储统计失败: %v", err)
+			return
+		}
+		if stats.Size <= limit || stats.Count == 0 {
+			return
+		}
+		// 按平均行大小估算需要删除的行数，多删除一批减少循环次数
+		n := int((stats.Size-limit)*stats.Count/stats.Size) + batchSize
+		if _, err := repository.DeleteOldestLogEntries(n); err != nil {
+			log.Printf("清理超出大小上限的日志失败: %v", err)
+			return
+		}
+	}
+}
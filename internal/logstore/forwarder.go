@@ -0,0 +1,242 @@
+package logstore
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"rubick/internal/docker"
+	"rubick/internal/model"
+)
+
+// 转发器状态
+const (
+	ForwarderIdle     = "idle"     // 尚未发送过日志
+	ForwarderOK       = "ok"       // 最近一批发送成功
+	ForwarderRetrying = "retrying" // 最近一批发送失败，正在重试
+	ForwarderFailing  = "failing"  // 重试耗尽，最近一批已丢弃
+)
+
+const (
+	// forwardQueueSize 转发队列长度，队列满时丢弃新的日志
+	forwardQueueSize = 10000
+	// maxSendRetries 单批日志的最大重试次数
+	maxSendRetries = 5
+	// maxRetryBackoff 重试间隔的上限
+	maxRetryBackoff = 30 * time.Second
+	// drainTimeout 停止转发器时发送剩余日志的最长时间
+	drainTimeout = 5 * time.Second
+)
+
+// forwarder 运行中的日志转发器
+type forwarder struct {
+	cfg      model.LogForwarder
+	selector Selector
+	projects map[string]bool
+	sink     sink
+
+	mu     sync.Mutex
+	status model.LogForwarderStatus
+	closed bool
+
+	queue  chan Record
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newForwarder 创建并启动转发器
+func newForwarder(cfg model.LogForwarder) (*forwarder, error) {
+	s, err := newSink(&cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &forwarder{
+		cfg:      cfg,
+		projects: make(map[string]bool),
+		sink:     s,
+		status:   model.LogForwarderStatus{State: ForwarderIdle},
+		queue:    make(chan Record, forwardQueueSize),
+		done:     make(chan struct{}),
+	}
+	if strings.TrimSpace(cfg.Selector) != "" {
+		if f.selector, err = ParseSelector(cfg.Selector); err != nil {
+			return nil, err
+		}
+	}
+	for _, p := range strings.Split(cfg.Projects, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			f.projects[p] = true
+		}
+	}
+	if f.cfg.BatchSize <= 0 {
+		f.cfg.BatchSize = 100
+	}
+	if f.cfg.FlushSeconds <= 0 {
+		f.cfg.FlushSeconds = 2
+	}
+
+	f.ctx, f.cancel = context.WithCancel(context.Background())
+	go f.run()
+	return f, nil
+}
+
+// matches 判断是否转发容器的日志
+func (f *forwarder) matches(t *Target) bool {
+	if f.cfg.HostID != "" && f.cfg.HostID != t.HostID {
+		return false
+	}
+	if t.Project != "" && f.projects[t.Project] {
+		return true
+	}
+	return f.selector != nil && f.selector.Match(t.labels)
+}
+
+// enqueue 将日志加入发送队列，队列已满时丢弃
+func (f *forwarder) enqueue(r Record) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return
+	}
+	select {
+	case f.queue <- r:
+	default:
+		f.status.Dropped++
+	}
+}
+
+// stop 停止接收日志，在 drainTimeout 内发送队列中剩余的日志
+func (f *forwarder) stop() {
+	f.mu.Lock()
+	if !f.closed {
+		f.closed = true
+		close(f.queue)
+	}
+	f.mu.Unlock()
+
+	select {
+	case <-f.done:
+	case <-time.After(drainTimeout):
+		f.cancel()
+		<-f.done
+	}
+}
+
+// Status 获取运行状态
+func (f *forwarder) Status() model.LogForwarderStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	status := f.status
+	status.Pending = len(f.queue)
+	return status
+}
+
+// run 按批发送日志，队列关闭且发送完剩余日志后退出
+func (f *forwarder) run() {
+	defer close(f.done)
+	defer f.sink.Close()
+	defer f.cancel()
+
+	interval := time.Duration(f.cfg.FlushSeconds) * time.Second
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	batch := make([]Record, 0, f.cfg.BatchSize)
+	for {
+		closed := false
+		select {
+		case r, ok := <-f.queue:
+			if !ok {
+				closed = true
+				break
+			}
+			batch = append(batch, r)
+			if len(batch) < f.cfg.BatchSize {
+				continue
+			}
+		case <-timer.C:
+		}
+
+		if len(batch) > 0 {
+			f.send(batch)
+			batch = batch[:0]
+		}
+		if closed || f.ctx.Err() != nil {
+			return
+		}
+		timer.Reset(interval)
+	}
+}
+
+// send 发送一批日志，失败时按指数退避重试，重试耗尽后丢弃
+func (f *forwarder) send(batch []Record) {
+	backoff := time.Second
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(f.ctx, sendTimeout)
+		err := f.sink.Send(ctx, batch)
+		cancel()
+
+		now := time.Now()
+		f.mu.Lock()
+		if err == nil {
+			f.status.State = ForwarderOK
+			f.status.Sent += int64(len(batch))
+			f.status.LastSentAt = &now
+			f.mu.Unlock()
+			return
+		}
+		f.status.LastError = err.Error()
+		f.status.LastErrorAt = &now
+		if attempt >= maxSendRetries || f.ctx.Err() != nil {
+			f.status.State = ForwarderFailing
+			f.status.Dropped += int64(len(batch))
+			f.mu.Unlock()
+			return
+		}
+		f.status.State = ForwarderRetrying
+		f.mu.Unlock()
+
+		select {
+		case <-f.ctx.Done():
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// ValidateForwarder 校验转发器的目标地址和选择器
+func ValidateForwarder(cfg *model.LogForwarder) error {
+	if _, err := newSink(cfg); err != nil {
+		return err
+	}
+	if strings.TrimSpace(cfg.Selector) != "" {
+		if _, err := ParseSelector(cfg.Selector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestForwarder 向转发目标发送一条测试日志
+func TestForwarder(ctx context.Context, cfg *model.LogForwarder) error {
+	s, err := newSink(cfg)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	return s.Send(ctx, []Record{{
+		HostName:      "rubick",
+		ContainerName: "rubick-test",
+		Stream:        docker.StreamStdout,
+		Time:          time.Now(),
+		Message:       "Rubick 日志转发测试",
+	}})
+}
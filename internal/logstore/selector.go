@@ -0,0 +1,66 @@
+package logstore
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Compose 为容器添加的标签
+const (
+	LabelProject = "com.docker.compose.project"
+	LabelService = "com.docker.compose.service"
+)
+
+// Selector 容器标签选择器，所有条件都满足时匹配
+// 条件为 key 或 key=value，多个条件用逗号分隔
+type Selector []string
+
+// ParseSelector 解析标签选择器
+func ParseSelector(s string) (Selector, error) {
+	var selector Selector
+	for _, term := range strings.Split(s, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(term, "=")
+		if strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("无效的标签选择器: %q", s)
+		}
+		selector = append(selector, term)
+	}
+	if len(selector) == 0 {
+		return nil, fmt.Errorf("标签选择器不能为空")
+	}
+	return selector, nil
+}
+
+// ParseSelectors 解析多个标签选择器
+func ParseSelectors(values []string) ([]Selector, error) {
+	selectors := make([]Selector, 0, len(values))
+	for _, v := range values {
+		selector, err := ParseSelector(v)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
+}
+
+// Match 判断标签是否满足选择器
+func (s Selector) Match(labels map[string]string) bool {
+	for _, term := range s {
+		key, value, hasValue := strings.Cut(term, "=")
+		v, ok := labels[key]
+		if !ok || (hasValue && v != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// String 以逗号分隔的形式输出选择器
+func (s Selector) String() string {
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,257 @@
+package logstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"rubick/internal/docker"
+	"rubick/internal/model"
+)
+
+// sendTimeout 单批日志的发送超时
+const sendTimeout = 15 * time.Second
+
+// maxUDPMessage UDP syslog 单条消息的最大长度，超出部分截断
+const maxUDPMessage = 8192
+
+// httpClient 转发日志使用的 HTTP 客户端
+var httpClient = &http.Client{Timeout: sendTimeout}
+
+// Record 转发的日志行
+type Record struct {
+	HostID        string    `json:"host_id"`
+	HostName      string    `json:"host_name"`
+	ContainerID   string    `json:"container_id"`
+	ContainerName string    `json:"container_name"`
+	Project       string    `json:"project,omitempty"`
+	Service       string    `json:"service,omitempty"`
+	Stream        string    `json:"stream"`
+	Time          time.Time `json:"time"`
+	Message       string    `json:"message"`
+}
+
+// sink 日志转发目标
+// Send 失败时整批重试，目标可能收到重复的日志
+type sink interface {
+	Send(ctx context.Context, records []Record) error
+	Close() error
+}
+
+// newSink 根据转发器配置创建转发目标
+func newSink(f *model.LogForwarder) (sink, error) {
+	u, err := url.Parse(f.URL)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 URL: %w", err)
+	}
+
+	switch f.Type {
+	case model.LogForwarderSyslog:
+		if (u.Scheme != "udp" && u.Scheme != "tcp") || u.Host == "" || u.Port() == "" {
+			return nil, fmt.Errorf("syslog URL 必须是 udp://host:port 或 tcp://host:port")
+		}
+		return &syslogSink{network: u.Scheme, addr: u.Host}, nil
+	case model.LogForwarderLoki, model.LogForwarderHTTP:
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("URL 必须是有效的 http 或 https 地址")
+		}
+		if f.Type == model.LogForwarderLoki {
+			return &lokiSink{url: f.URL, headers: f.Headers, labels: f.Labels}, nil
+		}
+		return &httpSink{url: f.URL, headers: f.Headers}, nil
+	}
+	return nil, fmt.Errorf("不支持的转发类型: %s", f.Type)
+}
+
+// syslogSink 以 RFC5424 格式发送到 syslog 服务器
+// TCP 使用 RFC6587 的长度前缀分帧，UDP 每条消息一个数据报
+type syslogSink struct {
+	network string
+	addr    string
+	conn    net.Conn
+}
+
+func (s *syslogSink) Send(ctx context.Context, records []Record) error {
+	if s.conn == nil {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, s.network, s.addr)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		s.conn.SetWriteDeadline(deadline)
+	}
+
+	var buf bytes.Buffer
+	for _, r := range records {
+		msg := formatSyslog(r)
+		if s.network == "udp" {
+			if len(msg) > maxUDPMessage {
+				msg = msg[:maxUDPMessage]
+			}
+			if _, err := s.conn.Write([]byte(msg)); err != nil {
+				s.Close()
+				return err
+			}
+			continue
+		}
+		fmt.Fprintf(&buf, "%d %s", len(msg), msg)
+	}
+	if buf.Len() > 0 {
+		if _, err := s.conn.Write(buf.Bytes()); err != nil {
+			// 连接可能已被服务器关闭，下次发送时重新连接
+			s.Close()
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *syslogSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// formatSyslog 按 RFC5424 格式化日志行
+// 设施为 user，stdout 的级别为 info，stderr 的级别为 err；APP-NAME 为容器名称，MSGID 为输出流
+func formatSyslog(r Record) string {
+	severity := 6
+	if r.Stream == docker.StreamStderr {
+		severity = 3
+	}
+	return fmt.Sprintf("<%d>1 %s %s %s - %s - %s",
+		1*8+severity,
+		r.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogField(r.HostName, 255),
+		syslogField(r.ContainerName, 48),
+		syslogField(r.Stream, 32),
+		r.Message,
+	)
+}
+
+// syslogField 将头部字段限制为可打印 ASCII 并截断，空值使用 NILVALUE
+func syslogField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// lokiSink 发送到 Loki push API
+type lokiSink struct {
+	url     string
+	headers map[string]string
+	labels  map[string]string
+}
+
+// lokiStream Loki 推送请求中的一个日志流
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (s *lokiSink) Send(ctx context.Context, records []Record) error {
+	// 按标签组合分组，每组内保持时间顺序
+	streams := []*lokiStream{}
+	index := make(map[string]*lokiStream)
+	for _, r := range records {
+		labels := map[string]string{
+			"host":      r.HostName,
+			"container": r.ContainerName,
+			"stream":    r.Stream,
+		}
+		if r.Project != "" {
+			labels["project"] = r.Project
+		}
+		if r.Service != "" {
+			labels["service"] = r.Service
+		}
+		for k, v := range s.labels {
+			labels[k] = v
+		}
+
+		key := r.HostID + "\x00" + r.ContainerID + "\x00" + r.Stream
+		stream, ok := index[key]
+		if !ok {
+			stream = &lokiStream{Stream: labels}
+			index[key] = stream
+			streams = append(streams, stream)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(r.Time.UnixNano(), 10), r.Message})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"streams": streams})
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.url, "application/json", s.headers, body)
+}
+
+func (s *lokiSink) Close() error { return nil }
+
+// httpSink 以 NDJSON 格式发送到 HTTP 地址，每行一个 Record
+type httpSink struct {
+	url     string
+	headers map[string]string
+}
+
+func (s *httpSink) Send(ctx context.Context, records []Record) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return post(ctx, s.url, "application/x-ndjson", s.headers, buf.Bytes())
+}
+
+func (s *httpSink) Close() error { return nil }
+
+// post 发送 POST 请求，非 2xx 响应视为失败
+func post(ctx context.Context, target, contentType string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "Rubick")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("服务器返回 %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// VolumeBackup 卷备份记录，备份文件保存在本地备份目录中
+type VolumeBackup struct {
+	ID       string `gorm:"primaryKey" json:"id"`
+	HostID   string `gorm:"not null;index" json:"host_id"`
+	Volume   string `gorm:"not null;index" json:"volume"`
+	FileName string `gorm:"not null" json:"file_name"` // 相对于备份目录的路径
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"` // 备份文件的 sha256
+	Source   string `json:"source"`   // manual, schedule, upload
+	TaskID   string `gorm:"index" json:"task_id,omitempty"`
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	Host *Host `gorm:"foreignKey:HostID" json:"host,omitempty"`
+}
+
+// BeforeCreate 创建前钩子
+func (b *VolumeBackup) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == "" {
+		b.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -34,11 +34,60 @@ type Host struct {
 	// Docker 端口
 	DockerPort int `gorm:"column:docker_port;default:2375" json:"docker_port,omitempty"`
 
+	// 分类标签
+	Tags        []string `gorm:"column:tags;serializer:json" json:"tags"`
+	Environment *string  `gorm:"index" json:"environment,omitempty"` // 环境，如 prod、staging，更新时传入空字符串表示清空
+
+	// 终端会话录像保留天数，为空时使用全局配置，0 表示永久保留
+	RecordingRetentionDays *int `gorm:"column:recording_retention_days" json:"recording_retention_days"`
+
+	// 最近一次成功获取的 Docker 信息，主机离线时仍可展示
+	Info *HostInfo `gorm:"column:info;serializer:json" json:"info,omitempty"`
+
+	// 健康检查状态，由后台监控更新
+	Status    string     `gorm:"default:'unknown'" json:"status"` // up, down, unknown
+	LastSeen  *time.Time `json:"last_seen,omitempty"`             // 最近一次检查成功的时间
+	LastError string     `json:"last_error,omitempty"`
+	LatencyMs int64      `json:"latency_ms"`
+
 	// 未加密的临时字段（用于内部使用）
 	sshPrivateKeyPlain string
 	sshPasswordPlain   string
 }
 
+// HostInfo Docker 守护进程信息快照
+type HostInfo struct {
+	Name               string    `json:"name"`
+	EngineVersion      string    `json:"engine_version"`
+	APIVersion         string    `json:"api_version"`
+	MinAPIVersion      string    `json:"min_api_version,omitempty"`
+	GoVersion          string    `json:"go_version,omitempty"`
+	OperatingSystem    string    `json:"operating_system"`
+	OSType             string    `json:"os_type"`
+	Architecture       string    `json:"architecture"`
+	KernelVersion      string    `json:"kernel_version"`
+	CPUs               int       `json:"cpus"`
+	Memory             int64     `json:"memory"`
+	StorageDriver      string    `json:"storage_driver"`
+	LoggingDriver      string    `json:"logging_driver"`
+	CgroupDriver       string    `json:"cgroup_driver"`
+	CgroupVersion      string    `json:"cgroup_version"`
+	DockerRootDir      string    `json:"docker_root_dir"`
+	Runtimes           []string  `json:"runtimes"`
+	DefaultRuntime     string    `json:"default_runtime"`
+	SwarmState         string    `json:"swarm_state"`
+	SwarmNodeID        string    `json:"swarm_node_id,omitempty"`
+	RegistryMirrors    []string  `json:"registry_mirrors"`
+	InsecureRegistries []string  `json:"insecure_registries"`
+	Containers         int       `json:"containers"`
+	ContainersRunning  int       `json:"containers_running"`
+	ContainersPaused   int       `json:"containers_paused"`
+	ContainersStopped  int       `json:"containers_stopped"`
+	Images             int       `json:"images"`
+	Warnings           []string  `json:"warnings"`
+	CollectedAt        time.Time `json:"collected_at"`
+}
+
 // ClearSensitiveFields 清除敏感字段（用于 API 响应）
 func (h *Host) ClearSensitiveFields() {
 	h.SSHPassword = ""
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// HostGroup 主机分组
+type HostGroup struct {
+	ID          string    `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"uniqueIndex;not null" json:"name"`
+	Description string    `json:"description"`
+	HostIDs     []string  `gorm:"-" json:"host_ids"` // 成员主机，保存在 host_group_members 表中
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BeforeCreate 创建前钩子
+func (g *HostGroup) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == "" {
+		g.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// HostGroupMember 主机分组成员关系
+type HostGroupMember struct {
+	GroupID string `gorm:"primaryKey" json:"group_id"`
+	HostID  string `gorm:"primaryKey;index" json:"host_id"`
+}
+
+// NormalizeTags 去除标签首尾空白、空标签和重复标签
+func NormalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+// HasTags 判断主机是否包含全部指定标签
+func (h *Host) HasTags(tags ...string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, t := range h.Tags {
+			if t == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// HostCheck 主机健康检查记录
+type HostCheck struct {
+	ID         string    `gorm:"primaryKey" json:"id"`
+	HostID     string    `gorm:"not null;index" json:"host_id"`
+	Status     string    `json:"status"` // up, down
+	LatencyMs  int64     `json:"latency_ms"`
+	Error      string    `json:"error,omitempty"`
+	Transition bool      `gorm:"index" json:"transition"` // 状态是否与上一次检查不同
+	CheckedAt  time.Time `gorm:"index" json:"checked_at"`
+}
+
+// BeforeCreate 创建前钩子
+func (c *HostCheck) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// LogEntry 集中存储的容器日志行
+// 日志量大，使用自增 ID 代替 UUID，ID 同时作为分页游标
+type LogEntry struct {
+	ID            uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	HostID        string    `gorm:"not null;index" json:"host_id"`
+	ContainerID   string    `gorm:"not null;index" json:"container_id"`
+	ContainerName string    `gorm:"index" json:"container_name"` // 容器重建后 ID 变化，按名称查询可以跨越重建
+	Project       string    `gorm:"index" json:"project,omitempty"`
+	Service       string    `json:"service,omitempty"`
+	Stream        string    `json:"stream"` // stdout, stderr
+	Time          time.Time `gorm:"index" json:"time"`
+	Message       string    `json:"message"`
+}
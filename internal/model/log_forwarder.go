@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 日志转发目标类型
+const (
+	LogForwarderSyslog = "syslog" // RFC5424 syslog，URL 为 udp://host:port 或 tcp://host:port
+	LogForwarderLoki   = "loki"   // Loki push API，URL 为完整的推送地址
+	LogForwarderHTTP   = "http"   // 以 NDJSON 格式 POST 到 URL
+)
+
+// LogForwarder 日志转发器，将选中容器的日志转发到外部系统
+type LogForwarder struct {
+	ID           string              `gorm:"primaryKey" json:"id"`
+	Name         string              `gorm:"not null" json:"name"`
+	Type         string              `gorm:"not null" json:"type"`
+	URL          string              `gorm:"not null" json:"url"`
+	Selector     string              `json:"selector,omitempty"`             // 容器标签选择器，如 app=web,tier=backend
+	Projects     string              `json:"projects,omitempty"`             // 逗号分隔的 Compose 项目名称，与 Selector 满足其一即转发
+	HostID       string              `json:"host_id,omitempty"`              // 为空表示全部主机
+	Headers      map[string]string   `gorm:"serializer:json" json:"headers"` // loki、http 请求附加的请求头，如认证信息
+	Labels       map[string]string   `gorm:"serializer:json" json:"labels"`  // loki 附加的静态标签
+	BatchSize    int                 `gorm:"default:100" json:"batch_size"`  // 每批发送的最大行数
+	FlushSeconds int                 `gorm:"default:2" json:"flush_seconds"` // 未满一批时的发送间隔
+	Enabled      bool                `json:"enabled"`
+	Status       *LogForwarderStatus `gorm:"-" json:"status,omitempty"` // 运行状态，仅启用的转发器有
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LogForwarderStatus 日志转发器的运行状态
+type LogForwarderStatus struct {
+	State       string     `json:"state"`   // idle, ok, retrying, failing
+	Sent        int64      `json:"sent"`    // 已发送的行数
+	Dropped     int64      `json:"dropped"` // 队列已满或重试耗尽而丢弃的行数
+	Pending     int        `json:"pending"` // 队列中等待发送的行数
+	LastSentAt  *time.Time `json:"last_sent_at,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// BeforeCreate 创建前钩子
+func (f *LogForwarder) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == "" {
+		f.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// ClearSensitiveFields 清除敏感字段（用于 API 响应），请求头的值可能包含认证信息
+func (f *LogForwarder) ClearSensitiveFields() {
+	for k := range f.Headers {
+		f.Headers[k] = ""
+	}
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NotificationHook 通知 Webhook
+type NotificationHook struct {
+	ID      string `gorm:"primaryKey" json:"id"`
+	Name    string `gorm:"not null" json:"name"`
+	URL     string `gorm:"not null" json:"url"`
+	Events  string `json:"events"`             // 逗号分隔的事件类型，为空表示全部事件
+	HostID  string `json:"host_id,omitempty"`  // 为空表示全部主机
+	GroupID string `json:"group_id,omitempty"` // 只接收该分组内主机的事件，与 HostID 同时为空表示全部主机
+	Secret  string `json:"secret,omitempty"`   // 用于请求签名
+	Enabled bool   `json:"enabled"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate 创建前钩子
+func (h *NotificationHook) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == "" {
+		h.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// ClearSensitiveFields 清除敏感字段（用于 API 响应）
+func (h *NotificationHook) ClearSensitiveFields() {
+	h.Secret = ""
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TerminalRecording 终端会话录像，录像文件以 asciinema v2 格式保存在本地录像目录中
+type TerminalRecording struct {
+	ID            string `gorm:"primaryKey" json:"id"`
+	HostID        string `gorm:"not null;index" json:"host_id"`
+	ContainerID   string `gorm:"index" json:"container_id"`
+	ContainerName string `json:"container_name"`
+	ExecID        string `gorm:"index" json:"exec_id"`
+	Command       string `json:"command"`        // 执行的命令，参数以空格连接
+	User          string `json:"user,omitempty"` // 容器内执行命令的用户
+	ClientIP      string `json:"client_ip"`
+	UserAgent     string `json:"user_agent"`
+
+	FileName string  `gorm:"not null" json:"file_name"` // 相对于录像目录的路径
+	Size     int64   `json:"size"`
+	Duration float64 `json:"duration"`            // 会话时长（秒）
+	ExitCode *int    `json:"exit_code,omitempty"` // 会话结束时命令的退出码
+
+	StartedAt time.Time  `gorm:"index" json:"started_at"`
+	EndedAt   *time.Time `gorm:"index" json:"ended_at,omitempty"` // 为空表示会话进行中或服务异常退出
+
+	Host     *Host     `gorm:"foreignKey:HostID" json:"host,omitempty"`
+	AuditLog *AuditLog `gorm:"-" json:"audit_log,omitempty"`
+}
+
+// BeforeCreate 创建前钩子
+func (r *TerminalRecording) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ScheduledTask 定时任务
+type ScheduledTask struct {
+	ID     string `gorm:"primaryKey" json:"id"`
+	Name   string `gorm:"not null" json:"name"`
+	HostID string `gorm:"not null;index" json:"host_id"`
+
+	// 动作: container_start, container_stop, container_restart, container_exec,
+	// compose_start, compose_stop, compose_restart, image_prune, volume_backup
+	Action string `gorm:"not null" json:"action"`
+	Target string `json:"target,omitempty"` // 容器 ID/名称、Compose 项目 ID 或卷名称
+
+	// 动作参数
+	Command  []string `gorm:"serializer:json" json:"command,omitempty"` // container_exec 执行的命令
+	User     string   `json:"user,omitempty"`                           // container_exec 执行用户
+	Timeout  int      `json:"timeout,omitempty"`                        // 执行超时（秒），0 使用默认值
+	PruneAll bool     `json:"prune_all,omitempty"`                      // image_prune 是否清理所有未使用镜像
+	Retain   int      `json:"retain,omitempty"`                         // volume_backup 保留的备份数量，0 表示全部保留
+
+	// 调度配置
+	Schedule string `gorm:"not null" json:"schedule"` // cron 表达式
+	Timezone string `json:"timezone"`                 // 为空时使用 UTC
+	Enabled  bool   `gorm:"index" json:"enabled"`
+
+	NextRunAt  *time.Time `gorm:"index" json:"next_run_at,omitempty"`
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	LastStatus string     `json:"last_status,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	Host *Host `gorm:"foreignKey:HostID" json:"host,omitempty"`
+}
+
+// BeforeCreate 创建前钩子
+func (t *ScheduledTask) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// TaskRun 定时任务执行记录
+type TaskRun struct {
+	ID         string     `gorm:"primaryKey" json:"id"`
+	TaskID     string     `gorm:"index;not null" json:"task_id"`
+	HostID     string     `gorm:"index" json:"host_id"`
+	Action     string     `json:"action"`
+	Target     string     `json:"target,omitempty"`
+	Trigger    string     `json:"trigger"`             // schedule, manual
+	Status     string     `gorm:"index" json:"status"` // running, succeeded, failed, skipped, interrupted
+	ExitCode   *int       `json:"exit_code,omitempty"` // container_exec 的退出码
+	Output     string     `gorm:"type:text" json:"output,omitempty"`
+	Error      string     `gorm:"type:text" json:"error,omitempty"`
+	StartedAt  time.Time  `gorm:"index" json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// BeforeCreate 创建前钩子
+func (r *TaskRun) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
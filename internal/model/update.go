@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UpdatePolicy 自动更新策略
+type UpdatePolicy struct {
+	ID     string `gorm:"primaryKey" json:"id"`
+	Name   string `gorm:"not null" json:"name"`
+	HostID string `gorm:"not null;index" json:"host_id"`
+
+	// 目标类型: container (按标签选择容器) 或 compose (Compose 项目)
+	TargetType    string `gorm:"not null" json:"target_type"`
+	LabelSelector string `json:"label_selector,omitempty"` // 如 rubick.update=true
+	ProjectID     string `gorm:"index" json:"project_id,omitempty"`
+
+	// 调度配置
+	Schedule          string `gorm:"not null" json:"schedule"`              // cron 表达式
+	Timezone          string `json:"timezone"`                              // 为空时使用 UTC
+	MaintenanceWindow string `json:"maintenance_window,omitempty"`          // 如 02:00-05:00，为空表示不限制
+	SemverConstraint  string `json:"semver_constraint,omitempty"`           // 如 ^1.2、~1.25、>=1.0 <2.0
+	TagPattern        string `json:"tag_pattern,omitempty"`                 // 候选标签需匹配的正则
+	HealthGracePeriod int    `gorm:"default:60" json:"health_grace_period"` // 健康检查宽限期（秒）
+	Rollback          bool   `json:"rollback"`                              // 失败时回滚到旧镜像
+	Enabled           bool   `gorm:"index" json:"enabled"`
+
+	NextRunAt *time.Time `gorm:"index" json:"next_run_at,omitempty"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	Host *Host `gorm:"foreignKey:HostID" json:"host,omitempty"`
+}
+
+// BeforeCreate 创建前钩子
+func (p *UpdatePolicy) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// UpdateRecord 更新记录
+type UpdateRecord struct {
+	ID         string    `gorm:"primaryKey" json:"id"`
+	PolicyID   string    `gorm:"index" json:"policy_id,omitempty"`
+	HostID     string    `gorm:"index" json:"host_id"`
+	TargetType string    `json:"target_type"` // container, compose
+	Target     string    `json:"target"`      // 容器名称或 Compose 项目名称
+	Service    string    `json:"service,omitempty"`
+	OldImage   string    `json:"old_image"`
+	NewImage   string    `json:"new_image"`
+	OldImageID string    `json:"old_image_id"`
+	NewImageID string    `json:"new_image_id,omitempty"`
+	Status     string    `gorm:"index" json:"status"` // updated, rolled_back, failed
+	Message    string    `gorm:"type:text" json:"message,omitempty"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
+
+// BeforeCreate 创建前钩子
+func (r *UpdateRecord) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"rubick/internal/config"
+	"rubick/internal/docker"
+	"rubick/internal/model"
+	"rubick/internal/notify"
+	"rubick/internal/repository"
+)
+
+// 主机健康状态
+const (
+	StatusUp      = "up"
+	StatusDown    = "down"
+	StatusUnknown = "unknown"
+)
+
+// pruneInterval 清理过期检查记录的间隔
+const pruneInterval = time.Hour
+
+// Monitor 主机健康检查器
+type Monitor struct {
+	mu       sync.Mutex
+	inflight map[string]bool   // 正在检查的主机
+	last     map[string]string // 主机最近一次的检查状态
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+var (
+	monitor     *Monitor
+	monitorOnce sync.Once
+)
+
+// GetMonitor 获取健康检查器单例
+func GetMonitor() *Monitor {
+	monitorOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		monitor = &Monitor{
+			inflight: make(map[string]bool),
+			last:     make(map[string]string),
+			ctx:      ctx,
+			cancel:   cancel,
+		}
+	})
+	return monitor
+}
+
+// Start 启动检查循环
+func (m *Monitor) Start() {
+	interval := config.Get().Monitor.Interval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var lastPrune time.Time
+		for {
+			m.checkAll(interval)
+			if time.Since(lastPrune) >= pruneInterval {
+				m.prune()
+				lastPrune = time.Now()
+			}
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止检查并等待正在进行的检查结束
+func (m *Monitor) Stop() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// checkAll 检查所有启用的主机
+// 每台主机随机延迟一段时间后再检查，避免同时向所有主机发起连接
+func (m *Monitor) checkAll(interval time.Duration) {
+	hosts, err := repository.ListHosts()
+	if err != nil {
+		log.Printf("健康检查获取主机列表失败: %v", err)
+		return
+	}
+
+	for i := range hosts {
+		host := hosts[i]
+		if !host.IsActive {
+			continue
+		}
+
+		m.mu.Lock()
+		if m.inflight[host.ID] {
+			m.mu.Unlock()
+			continue
+		}
+		m.inflight[host.ID] = true
+		m.mu.Unlock()
+
+		jitter := time.Duration(rand.Int63n(int64(interval/2) + 1))
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			defer func() {
+				m.mu.Lock()
+				delete(m.inflight, host.ID)
+				m.mu.Unlock()
+			}()
+
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-time.After(jitter):
+			}
+			m.Check(m.ctx, &host)
+		}()
+	}
+}
+
+// Check 立即检查主机并记录结果
+func (m *Monitor) Check(ctx context.Context, host *model.Host) *model.HostCheck {
+	timeout := config.Get().Monitor.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := docker.GetManager().Ping(ctx, host)
+	return m.Record(host, time.Since(start), err)
+}
+
+// Record 记录一次检查结果，err 为 nil 表示主机可用
+// 状态发生变化时写入审计日志并发送通知
+func (m *Monitor) Record(host *model.Host, latency time.Duration, err error) *model.HostCheck {
+	now := time.Now()
+	check := &model.HostCheck{
+		HostID:    host.ID,
+		Status:    StatusUp,
+		LatencyMs: latency.Milliseconds(),
+		CheckedAt: now,
+	}
+	if err != nil {
+		check.Status = StatusDown
+		check.Error = err.Error()
+	}
+
+	docker.GetManager().SetHostStatus(host.ID, err)
+
+	m.mu.Lock()
+	previous, ok := m.last[host.ID]
+	if !ok {
+		previous = host.Status
+	}
+	m.last[host.ID] = check.Status
+	m.mu.Unlock()
+	check.Transition = previous != check.Status
+
+	var lastSeen *time.Time
+	if check.Status == StatusUp {
+		lastSeen = &now
+		host.LastSeen = lastSeen
+	}
+	host.Status = check.Status
+	host.LastError = check.Error
+	host.LatencyMs = check.LatencyMs
+
+	if err := repository.UpdateHostStatus(host.ID, check.Status, lastSeen, check.Error, check.LatencyMs); err != nil {
+		log.Printf("保存主机 %s 状态失败: %v", host.Name, err)
+	}
+	if err := repository.CreateHostCheck(check); err != nil {
+		log.Printf("保存主机 %s 检查记录失败: %v", host.Name, err)
+	}
+
+	// 首次检查成功不算恢复，只有从不可用变为可用时才通知
+	if check.Status == StatusDown && previous != StatusDown {
+		m.notify(host, check, notify.EventHostDown, fmt.Sprintf("主机 %s 不可用: %s", host.Name, check.Error))
+	} else if check.Status == StatusUp && previous == StatusDown {
+		m.notify(host, check, notify.EventHostUp, fmt.Sprintf("主机 %s 已恢复，延迟 %dms", host.Name, check.LatencyMs))
+	}
+	return check
+}
+
+// notify 记录状态变化并发送通知
+func (m *Monitor) notify(host *model.Host, check *model.HostCheck, eventType, message string) {
+	log.Print(message)
+
+	status := http.StatusOK
+	if check.Status == StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+	repository.CreateAuditLog(&model.AuditLog{
+		Method:  "SYSTEM",
+		Path:    "/api/v1/hosts/" + host.ID,
+		Status:  status,
+		Latency: check.LatencyMs,
+		Message: message,
+	})
+
+	notify.Dispatch(notify.Event{
+		Type:     eventType,
+		HostID:   host.ID,
+		HostName: host.Name,
+		Message:  message,
+		Data:     check,
+	})
+}
+
+// prune 删除超过保留时长的检查记录
+func (m *Monitor) prune() {
+	retention := config.Get().Monitor.Retention
+	if retention <= 0 {
+		return
+	}
+	deleted, err := repository.DeleteHostChecksBefore(time.Now().Add(-retention))
+	if err != nil {
+		log.Printf("清理主机检查记录失败: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("已清理 %d 条过期的主机检查记录", deleted)
+	}
+}
@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"rubick/internal/model"
+	"rubick/internal/repository"
+)
+
+// 事件类型
+const (
+	EventUpdateSucceeded  = "update.succeeded"
+	EventUpdateRolledBack = "update.rolled_back"
+	EventUpdateFailed     = "update.failed"
+	EventHostDown         = "host.down"
+	EventHostUp           = "host.up"
+	EventTest             = "test"
+)
+
+// SignatureHeader 配置了密钥时，请求体的 HMAC-SHA256 签名所在的请求头
+const SignatureHeader = "X-Rubick-Signature"
+
+// httpClient 发送通知使用的 HTTP 客户端
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Event 通知事件
+type Event struct {
+	Type     string      `json:"type"`
+	HostID   string      `json:"host_id,omitempty"`
+	HostName string      `json:"host_name,omitempty"`
+	Message  string      `json:"message"`
+	Data     interface{} `json:"data,omitempty"`
+	Time     time.Time   `json:"time"`
+}
+
+// Dispatch 异步将事件发送到所有匹配的 Webhook
+func Dispatch(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	go func() {
+		hooks, err := repository.ListEnabledNotificationHooks(event.HostID)
+		if err != nil {
+			log.Printf("获取通知 Webhook 失败: %v", err)
+			return
+		}
+
+		for i := range hooks {
+			if !Matches(&hooks[i], event.Type) {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			if err := Send(ctx, &hooks[i], event); err != nil {
+				log.Printf("发送通知到 %s 失败: %v", hooks[i].Name, err)
+			}
+			cancel()
+		}
+	}()
+}
+
+// Matches 判断 Webhook 是否订阅了指定事件
+// Events 为空或包含 * 时订阅全部事件；以 .* 结尾时按前缀匹配
+func Matches(hook *model.NotificationHook, eventType string) bool {
+	if strings.TrimSpace(hook.Events) == "" {
+		return true
+	}
+	for _, pattern := range strings.Split(hook.Events, ",") {
+		pattern = strings.TrimSpace(pattern)
+		switch {
+		case pattern == "*" || pattern == eventType:
+			return true
+		case strings.HasSuffix(pattern, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(pattern, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+// Send 同步发送事件到指定 Webhook
+func Send(ctx context.Context, hook *model.NotificationHook, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("序列化通知失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建通知请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Rubick")
+	if hook.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(hook.Secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送通知失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Webhook 返回 %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// asciinema v2 事件类型
+const (
+	EventOutput = "o" // 终端输出
+	EventInput  = "i" // 用户输入
+	EventResize = "r" // 终端大小变化，数据为 <列>x<行>
+)
+
+// 未收到终端大小时使用的默认值
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// Header asciinema v2 文件头
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Command   string            `json:"command,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Event asciinema v2 事件，编码为 [时间, 类型, 数据]
+type Event struct {
+	Time float64 // 相对会话开始的秒数
+	Type string
+	Data string
+}
+
+// MarshalJSON 编码为 JSON 数组
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{json.Number(strconv.FormatFloat(e.Time, 'f', 6, 64)), e.Type, e.Data})
+}
+
+// UnmarshalJSON 从 JSON 数组解码
+func (e *Event) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 3 {
+		return fmt.Errorf("事件应包含 3 个元素")
+	}
+	if err := json.Unmarshal(raw[0], &e.Time); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[1], &e.Type); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw[2], &e.Data)
+}
+
+// ParseSize 解析终端大小事件的数据 <列>x<行>
+func ParseSize(s string) (cols, rows int, err error) {
+	if _, err := fmt.Sscanf(s, "%dx%d", &cols, &rows); err != nil || cols <= 0 || rows <= 0 {
+		return 0, 0, fmt.Errorf("无效的终端大小: %q", s)
+	}
+	return cols, rows, nil
+}
+
+// Recorder 以 asciinema v2 格式写入终端会话，可以被多个协程同时调用
+// 文件头在第一个输入输出事件时写入，此前收到的终端大小写入文件头而不是作为事件
+type Recorder struct {
+	mu      sync.Mutex
+	w       io.Writer
+	header  Header
+	started bool
+	start   time.Time
+	last    float64
+	size    int64
+	err     error
+	// 上次写入时末尾不完整的 UTF-8 字节，与下次的数据合并，避免多字节字符被拆开后无法编码
+	partial map[string][]byte
+}
+
+// NewRecorder 创建录制器，会话开始时间为当前时间
+func NewRecorder(w io.Writer, command, title string, env map[string]string) *Recorder {
+	now := time.Now()
+	return &Recorder{
+		w: w,
+		header: Header{
+			Version:   2,
+			Width:     defaultWidth,
+			Height:    defaultHeight,
+			Timestamp: now.Unix(),
+			Command:   command,
+			Title:     title,
+			Env:       env,
+		},
+		start:   now,
+		partial: make(map[string][]byte),
+	}
+}
+
+// Output 记录终端输出
+func (r *Recorder) Output(p []byte) {
+	r.writeData(EventOutput, p)
+}
+
+// Input 记录用户输入
+func (r *Recorder) Input(p []byte) {
+	r.writeData(EventInput, p)
+}
+
+// Resize 记录终端大小变化
+func (r *Recorder) Resize(cols, rows int) {
+	if cols <= 0 || rows <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.started {
+		r.header.Width = cols
+		r.header.Height = rows
+		return
+	}
+	r.writeEvent(EventResize, fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// Close 写出剩余的数据，返回第一次写入失败的错误
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, typ := range []string{EventOutput, EventInput} {
+		if p := r.partial[typ]; len(p) > 0 {
+			r.writeEvent(typ, string(p))
+			delete(r.partial, typ)
+		}
+	}
+	r.begin()
+	return r.err
+}
+
+// Duration 返回最后一个事件相对会话开始的秒数
+func (r *Recorder) Duration() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last
+}
+
+// Size 返回已写入的字节数
+func (r *Recorder) Size() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.size
+}
+
+// writeData 记录数据事件，末尾不完整的 UTF-8 字符留到下次写入
+func (r *Recorder) writeData(typ string, p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data := append(r.partial[typ], p...)
+	n := completeUTF8(data)
+	r.partial[typ] = append([]byte(nil), data[n:]...)
+	if n > 0 {
+		r.writeEvent(typ, string(data[:n]))
+	}
+}
+
+// writeEvent 写入一个事件，调用方需持有 r.mu
+func (r *Recorder) writeEvent(typ, data string) {
+	r.begin()
+	t := time.Since(r.start).Seconds()
+	if t < r.last {
+		t = r.last
+	}
+	r.last = t
+	r.writeLine(Event{Time: t, Type: typ, Data: data})
+}
+
+// begin 写入文件头，调用方需持有 r.mu
+func (r *Recorder) begin() {
+	if r.started {
+		return
+	}
+	r.started = true
+	r.writeLine(r.header)
+}
+
+// writeLine 写入一行 JSON，写入失败后不再写入
+func (r *Recorder) writeLine(v interface{}) {
+	if r.err != nil {
+		return
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		r.err = err
+		return
+	}
+	n, err := r.w.Write(append(b, '\n'))
+	r.size += int64(n)
+	r.err = err
+}
+
+// completeUTF8 返回 p 中不含末尾不完整 UTF-8 字符的长度
+// 只检查最后 3 个字节，无效的字节按原样保留，由 JSON 编码替换
+func completeUTF8(p []byte) int {
+	for i := 1; i <= utf8.UTFMax-1 && i <= len(p); i++ {
+		c := p[len(p)-i]
+		if !utf8.RuneStart(c) {
+			continue
+		}
+		// 找到最后一个字符的起始字节，判断其后的字节是否足够
+		if c >= utf8.RuneSelf && !utf8.FullRune(p[len(p)-i:]) {
+			return len(p) - i
+		}
+		break
+	}
+	return len(p)
+}
+
+// Decoder 读取 asciinema v2 文件
+type Decoder struct {
+	scanner *bufio.Scanner
+	header  Header
+}
+
+// NewDecoder 读取并校验文件头
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	d := &Decoder{scanner: scanner}
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("录像文件为空")
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &d.header); err != nil {
+		return nil, fmt.Errorf("无效的录像文件头: %w", err)
+	}
+	if d.header.Version != 2 {
+		return nil, fmt.Errorf("不支持的录像版本: %d", d.header.Version)
+	}
+	return d, nil
+}
+
+// Header 返回文件头
+func (d *Decoder) Header() Header {
+	return d.header
+}
+
+// Next 读取下一个事件，读取完毕时返回 io.EOF
+func (d *Decoder) Next() (Event, error) {
+	var e Event
+	for d.scanner.Scan() {
+		line := d.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(line, &e); err != nil {
+			return e, fmt.Errorf("无效的录像事件: %w", err)
+		}
+		return e, nil
+	}
+	if err := d.scanner.Err(); err != nil {
+		return e, err
+	}
+	return e, io.EOF
+}
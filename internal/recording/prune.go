@@ -0,0 +1,123 @@
+package recording
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"rubick/internal/config"
+	"rubick/internal/model"
+	"rubick/internal/repository"
+)
+
+// pruneInterval 清理过期录像的间隔
+const pruneInterval = time.Hour
+
+// Pruner 按保留策略清理过期的终端会话录像
+type Pruner struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var (
+	pruner     *Pruner
+	prunerOnce sync.Once
+)
+
+// GetPruner 获取录像清理器单例
+func GetPruner() *Pruner {
+	prunerOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		pruner = &Pruner{ctx: ctx, cancel: cancel}
+	})
+	return pruner
+}
+
+// Start 启动清理循环
+func (p *Pruner) Start() {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+
+		for {
+			p.Prune()
+			select {
+			case <-p.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止清理循环
+func (p *Pruner) Stop() {
+	p.cancel()
+	p.wg.Wait()
+}
+
+// Retention 返回主机的录像保留时长，0 表示永久保留
+func Retention(host *model.Host) time.Duration {
+	if host != nil && host.RecordingRetentionDays != nil {
+		return time.Duration(*host.RecordingRetentionDays) * 24 * time.Hour
+	}
+	return config.Get().Recording.Retention
+}
+
+// Prune 删除超过保留时长的录像，已删除主机的录像使用全局配置
+// 进行中的会话不会被删除
+func (p *Pruner) Prune() {
+	hosts, err := repository.ListHosts()
+	if err != nil {
+		log.Printf("清理录像获取主机列表失败: %v", err)
+		return
+	}
+
+	now := time.Now()
+	deleted := 0
+	hostIDs := make([]string, 0, len(hosts))
+	for i := range hosts {
+		hostIDs = append(hostIDs, hosts[i].ID)
+		retention := Retention(&hosts[i])
+		if retention <= 0 {
+			continue
+		}
+		recordings, err := repository.ListRecordingsEndedBefore(hosts[i].ID, now.Add(-retention))
+		if err != nil {
+			log.Printf("获取主机 %s 的过期录像失败: %v", hosts[i].Name, err)
+			continue
+		}
+		deleted += deleteAll(recordings)
+	}
+
+	if retention := Retention(nil); retention > 0 {
+		recordings, err := repository.ListOrphanRecordingsEndedBefore(hostIDs, now.Add(-retention))
+		if err != nil {
+			log.Printf("获取已删除主机的过期录像失败: %v", err)
+		} else {
+			deleted += deleteAll(recordings)
+		}
+	}
+
+	if deleted > 0 {
+		log.Printf("已清理 %d 个过期的终端会话录像", deleted)
+	}
+}
+
+// deleteAll 删除录像，返回删除成功的数量
+func deleteAll(recordings []model.TerminalRecording) int {
+	deleted := 0
+	for i := range recordings {
+		if err := Delete(&recordings[i]); err != nil {
+			log.Printf("删除录像 %s 失败: %v", recordings[i].ID, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted
+}
@@ -0,0 +1,139 @@
+package recording
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"rubick/internal/config"
+	"rubick/internal/model"
+	"rubick/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// execInfoTTL 创建执行命令后等待终端连接的最长时间，超时的命令信息会被丢弃
+const execInfoTTL = time.Hour
+
+// ExecInfo 创建执行命令时的信息，Docker 的 exec inspect 接口不返回命令，需要在创建时记录
+type ExecInfo struct {
+	ContainerID string
+	Cmd         []string
+	User        string
+	created     time.Time
+}
+
+var (
+	execMu    sync.Mutex
+	execInfos = make(map[string]ExecInfo)
+)
+
+// RegisterExec 记录执行命令的信息，终端连接时用于填充录像的元数据
+func RegisterExec(execID string, info ExecInfo) {
+	execMu.Lock()
+	defer execMu.Unlock()
+
+	now := time.Now()
+	for id, i := range execInfos {
+		if now.Sub(i.created) > execInfoTTL {
+			delete(execInfos, id)
+		}
+	}
+	info.created = now
+	execInfos[execID] = info
+}
+
+// takeExec 取出执行命令的信息
+func takeExec(execID string) (ExecInfo, bool) {
+	execMu.Lock()
+	defer execMu.Unlock()
+	info, ok := execInfos[execID]
+	delete(execInfos, execID)
+	return info, ok
+}
+
+// Path 返回录像文件的完整路径
+func Path(r *model.TerminalRecording) string {
+	return filepath.Join(config.Get().Recording.Dir, filepath.FromSlash(r.FileName))
+}
+
+// Session 正在录制的终端会话
+type Session struct {
+	*Recorder
+	Recording *model.TerminalRecording
+	file      *os.File
+}
+
+// Start 开始录制终端会话，创建录像文件和记录
+// rec 需填写主机、容器和客户端信息，命令和用户从创建执行命令时记录的信息中获取
+func Start(rec *model.TerminalRecording) (*Session, error) {
+	if info, ok := takeExec(rec.ExecID); ok {
+		if rec.ContainerID == "" {
+			rec.ContainerID = info.ContainerID
+		}
+		rec.Command = strings.Join(info.Cmd, " ")
+		rec.User = info.User
+	}
+
+	rec.ID = uuid.New().String()
+	rec.StartedAt = time.Now().UTC()
+	rec.FileName = path.Join(rec.HostID, rec.StartedAt.Format("20060102"), rec.ID+".cast")
+
+	full := Path(rec)
+	if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+		return nil, fmt.Errorf("创建录像目录失败: %w", err)
+	}
+	f, err := os.OpenFile(full, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("创建录像文件失败: %w", err)
+	}
+
+	if err := repository.CreateRecording(rec); err != nil {
+		f.Close()
+		os.Remove(full)
+		return nil, fmt.Errorf("保存录像记录失败: %w", err)
+	}
+
+	title := rec.ContainerName
+	if title == "" {
+		title = rec.ContainerID
+	}
+	return &Session{
+		Recorder:  NewRecorder(f, rec.Command, title, map[string]string{"TERM": "xterm"}),
+		Recording: rec,
+		file:      f,
+	}, nil
+}
+
+// Finish 结束录制，保存录像的大小、时长和命令的退出码
+func (s *Session) Finish(exitCode *int) error {
+	err := s.Recorder.Close()
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	ended := time.Now().UTC()
+	s.Recording.Size = s.Recorder.Size()
+	s.Recording.Duration = s.Recorder.Duration()
+	s.Recording.ExitCode = exitCode
+	s.Recording.EndedAt = &ended
+	if saveErr := repository.FinishRecording(s.Recording); err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		return fmt.Errorf("保存录像失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除录像文件和记录
+func Delete(r *model.TerminalRecording) error {
+	if err := os.Remove(Path(r)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除录像文件失败: %w", err)
+	}
+	return repository.DeleteRecording(r.ID)
+}
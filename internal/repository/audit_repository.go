@@ -35,3 +35,12 @@ func ListAuditLogs(page, pageSize int, method, path string) ([]model.AuditLog, i
 
 	return logs, total, nil
 }
+
+// GetAuditLogByRecordingID 获取终端会话录像对应的审计日志
+func GetAuditLogByRecordingID(recordingID string) (*model.AuditLog, error) {
+	var log model.AuditLog
+	if err := database.GetDB().First(&log, "recording_id = ?", recordingID).Error; err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
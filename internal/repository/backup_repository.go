@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"rubick/internal/database"
+	"rubick/internal/model"
+)
+
+// ListVolumeBackups 获取卷备份列表，按创建时间倒序
+func ListVolumeBackups(hostID, volume string) ([]model.VolumeBackup, error) {
+	var backups []model.VolumeBackup
+	query := database.GetDB().Preload("Host")
+	if hostID != "" {
+		query = query.Where("host_id = ?", hostID)
+	}
+	if volume != "" {
+		query = query.Where("volume = ?", volume)
+	}
+	if err := query.Order("created_at DESC").Find(&backups).Error; err != nil {
+		return nil, err
+	}
+	return backups, nil
+}
+
+// ListVolumeBackupsByTask 获取定时任务产生的备份，按创建时间倒序
+func ListVolumeBackupsByTask(taskID string) ([]model.VolumeBackup, error) {
+	var backups []model.VolumeBackup
+	err := database.GetDB().Where("task_id = ?", taskID).Order("created_at DESC").Find(&backups).Error
+	if err != nil {
+		return nil, err
+	}
+	return backups, nil
+}
+
+// GetVolumeBackupByID 根据 ID 获取卷备份
+func GetVolumeBackupByID(id string) (*model.VolumeBackup, error) {
+	var backup model.VolumeBackup
+	if err := database.GetDB().First(&backup, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// CreateVolumeBackup 创建卷备份记录
+func CreateVolumeBackup(backup *model.VolumeBackup) error {
+	return database.GetDB().Create(backup).Error
+}
+
+// DeleteVolumeBackup 删除卷备份记录
+func DeleteVolumeBackup(id string) error {
+	return database.GetDB().Delete(&model.VolumeBackup{}, "id = ?", id).Error
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"rubick/internal/database"
+	"rubick/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ListHostGroups 获取所有主机分组及其成员
+func ListHostGroups() ([]model.HostGroup, error) {
+	var groups []model.HostGroup
+	if err := database.GetDB().Order("name ASC").Find(&groups).Error; err != nil {
+		return nil, err
+	}
+
+	var members []model.HostGroupMember
+	if err := database.GetDB().Find(&members).Error; err != nil {
+		return nil, err
+	}
+	byGroup := make(map[string][]string)
+	for _, m := range members {
+		byGroup[m.GroupID] = append(byGroup[m.GroupID], m.HostID)
+	}
+	for i := range groups {
+		groups[i].HostIDs = byGroup[groups[i].ID]
+		if groups[i].HostIDs == nil {
+			groups[i].HostIDs = []string{}
+		}
+	}
+	return groups, nil
+}
+
+// GetHostGroup 根据 ID 或名称获取主机分组及其成员
+func GetHostGroup(idOrName string) (*model.HostGroup, error) {
+	var group model.HostGroup
+	if err := database.GetDB().First(&group, "id = ? OR name = ?", idOrName, idOrName).Error; err != nil {
+		return nil, err
+	}
+
+	hostIDs := []string{}
+	err := database.GetDB().Model(&model.HostGroupMember{}).
+		Where("group_id = ?", group.ID).
+		Pluck("host_id", &hostIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	group.HostIDs = hostIDs
+	return &group, nil
+}
+
+// CreateHostGroup 创建主机分组及其成员
+func CreateHostGroup(group *model.HostGroup) error {
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+		return replaceHostGroupMembers(tx, group.ID, group.HostIDs)
+	})
+}
+
+// SaveHostGroup 保存主机分组并替换其成员
+func SaveHostGroup(group *model.HostGroup) error {
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(group).Error; err != nil {
+			return err
+		}
+		return replaceHostGroupMembers(tx, group.ID, group.HostIDs)
+	})
+}
+
+// DeleteHostGroup 删除主机分组及其成员关系
+func DeleteHostGroup(id string) error {
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.HostGroupMember{}, "group_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.HostGroup{}, "id = ?", id).Error
+	})
+}
+
+// replaceHostGroupMembers 用 hostIDs 替换分组的全部成员
+func replaceHostGroupMembers(tx *gorm.DB, groupID string, hostIDs []string) error {
+	if err := tx.Delete(&model.HostGroupMember{}, "group_id = ?", groupID).Error; err != nil {
+		return err
+	}
+	if len(hostIDs) == 0 {
+		return nil
+	}
+	members := make([]model.HostGroupMember, 0, len(hostIDs))
+	for _, hostID := range hostIDs {
+		members = append(members, model.HostGroupMember{GroupID: groupID, HostID: hostID})
+	}
+	return tx.Create(&members).Error
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"encoding/json"
+	"time"
+
 	"rubick/internal/crypto"
 	"rubick/internal/database"
 	"rubick/internal/model"
@@ -17,6 +20,47 @@ func ListHosts() ([]model.Host, error) {
 	return hosts, nil
 }
 
+// HostFilter 主机筛选条件，空字段表示不限
+type HostFilter struct {
+	GroupID     string
+	Environment string
+	Tags        []string // 必须包含全部标签
+}
+
+// IsEmpty 判断是否未设置任何筛选条件
+func (f HostFilter) IsEmpty() bool {
+	return f.GroupID == "" && f.Environment == "" && len(f.Tags) == 0
+}
+
+// FilterHosts 按分组、环境和标签筛选主机
+func FilterHosts(f HostFilter) ([]model.Host, error) {
+	query := database.GetDB().Order("is_default DESC, name ASC")
+	if f.GroupID != "" {
+		query = query.Where("id IN (?)", database.GetDB().Model(&model.HostGroupMember{}).
+			Select("host_id").Where("group_id = ?", f.GroupID))
+	}
+	if f.Environment != "" {
+		query = query.Where("environment = ?", f.Environment)
+	}
+
+	var hosts []model.Host
+	if err := query.Find(&hosts).Error; err != nil {
+		return nil, err
+	}
+	if len(f.Tags) == 0 {
+		return hosts, nil
+	}
+
+	// 标签以 JSON 数组保存，在内存中匹配
+	matched := make([]model.Host, 0, len(hosts))
+	for _, host := range hosts {
+		if host.HasTags(f.Tags...) {
+			matched = append(matched, host)
+		}
+	}
+	return matched, nil
+}
+
 // GetHostByID 根据 ID 获取主机
 func GetHostByID(id string) (*model.Host, error) {
 	var host model.Host
@@ -113,11 +157,83 @@ func UpdateHost(id string, updates *model.Host) error {
 		updateMap["tls_cert_id"] = updates.TLSCertID
 	}
 
+	// 分类标签，传入空数组表示清空
+	if updates.Tags != nil {
+		// map 更新不经过字段的 serializer，需要手动编码
+		tags, err := json.Marshal(updates.Tags)
+		if err != nil {
+			return err
+		}
+		updateMap["tags"] = string(tags)
+	}
+	// 环境，未传入时不修改，传入空字符串表示清空
+	if updates.Environment != nil {
+		updateMap["environment"] = *updates.Environment
+	}
+
+	// 录像保留天数，传入负数表示恢复为全局配置
+	if updates.RecordingRetentionDays != nil {
+		if *updates.RecordingRetentionDays < 0 {
+			updateMap["recording_retention_days"] = nil
+		} else {
+			updateMap["recording_retention_days"] = *updates.RecordingRetentionDays
+		}
+	}
+
 	return database.GetDB().Model(&model.Host{}).Where("id = ?", id).Updates(updateMap).Error
 }
 
-// DeleteHost 删除主机
+// UpdateHostInfo 保存主机的 Docker 信息快照
+func UpdateHostInfo(id string, info *model.HostInfo) error {
+	return database.GetDB().Model(&model.Host{ID: id}).Select("info").Updates(&model.Host{Info: info}).Error
+}
+
+// UpdateHostStatus 保存主机的健康检查状态
+func UpdateHostStatus(id, status string, lastSeen *time.Time, lastError string, latencyMs int64) error {
+	updates := map[string]interface{}{
+		"status":     status,
+		"last_error": lastError,
+		"latency_ms": latencyMs,
+	}
+	if lastSeen != nil {
+		updates["last_seen"] = lastSeen
+	}
+	return database.GetDB().Model(&model.Host{}).Where("id = ?", id).UpdateColumns(updates).Error
+}
+
+// CreateHostCheck 创建健康检查记录
+func CreateHostCheck(check *model.HostCheck) error {
+	return database.GetDB().Create(check).Error
+}
+
+// ListHostChecks 获取主机的健康检查记录，按时间倒序
+// transitionsOnly 为 true 时只返回状态变化的记录
+func ListHostChecks(hostID string, transitionsOnly bool, limit int) ([]model.HostCheck, error) {
+	var checks []model.HostCheck
+	query := database.GetDB().Where("host_id = ?", hostID)
+	if transitionsOnly {
+		query = query.Where("transition = ?", true)
+	}
+	if err := query.Order("checked_at DESC").Limit(limit).Find(&checks).Error; err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+// DeleteHostChecksBefore 删除指定时间之前的健康检查记录，状态变化记录同样删除
+func DeleteHostChecksBefore(before time.Time) (int64, error) {
+	result := database.GetDB().Where("checked_at < ?", before).Delete(&model.HostCheck{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteHost 删除主机及其健康检查记录和分组成员关系
 func DeleteHost(id string) error {
+	if err := database.GetDB().Delete(&model.HostCheck{}, "host_id = ?", id).Error; err != nil {
+		return err
+	}
+	if err := database.GetDB().Delete(&model.HostGroupMember{}, "host_id = ?", id).Error; err != nil {
+		return err
+	}
 	return database.GetDB().Delete(&model.Host{}, "id = ?", id).Error
 }
 
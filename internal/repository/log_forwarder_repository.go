@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"rubick/internal/database"
+	"rubick/internal/model"
+)
+
+// ListLogForwarders 获取日志转发器列表
+func ListLogForwarders() ([]model.LogForwarder, error) {
+	var forwarders []model.LogForwarder
+	if err := database.GetDB().Order("name ASC").Find(&forwarders).Error; err != nil {
+		return nil, err
+	}
+	return forwarders, nil
+}
+
+// ListEnabledLogForwarders 获取启用的日志转发器
+func ListEnabledLogForwarders() ([]model.LogForwarder, error) {
+	var forwarders []model.LogForwarder
+	if err := database.GetDB().Where("enabled = ?", true).Find(&forwarders).Error; err != nil {
+		return nil, err
+	}
+	return forwarders, nil
+}
+
+// GetLogForwarderByID 根据 ID 获取日志转发器
+func GetLogForwarderByID(id string) (*model.LogForwarder, error) {
+	var forwarder model.LogForwarder
+	if err := database.GetDB().First(&forwarder, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &forwarder, nil
+}
+
+// CreateLogForwarder 创建日志转发器
+func CreateLogForwarder(forwarder *model.LogForwarder) error {
+	return database.GetDB().Create(forwarder).Error
+}
+
+// SaveLogForwarder 保存日志转发器的全部字段
+func SaveLogForwarder(forwarder *model.LogForwarder) error {
+	return database.GetDB().Save(forwarder).Error
+}
+
+// DeleteLogForwarder 删除日志转发器
+func DeleteLogForwarder(id string) error {
+	return database.GetDB().Delete(&model.LogForwarder{}, "id = ?", id).Error
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"rubick/internal/database"
+	"rubick/internal/model"
+)
+
+// likeEscaper 转义 LIKE 模式中的通配符，配合 ESCAPE '\' 使用
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// LogQuery 日志查询条件
+// 日志时间以 UTC 存储，sqlite 按文本比较时间，查询时统一转换为 UTC
+type LogQuery struct {
+	HostID    string
+	Container string // 容器 ID 前缀或名称
+	Project   string
+	Service   string
+	Stream    string
+	Since     time.Time
+	Until     time.Time
+	Text      string // 包含该内容的行，忽略大小写
+	BeforeID  uint64 // 只返回 ID 小于该值的行，用于向前翻页
+	AfterID   uint64 // 只返回 ID 大于该值的行，用于追踪新日志
+	Limit     int
+}
+
+// LogStoreStats 日志存储统计
+type LogStoreStats struct {
+	Count  int64      `json:"count"`
+	Size   int64      `json:"size"` // 数据库文件中已使用的大小（字节），不含可回收的空闲页
+	Oldest *time.Time `json:"oldest,omitempty"`
+	Newest *time.Time `json:"newest,omitempty"`
+}
+
+// CreateLogEntries 批量写入日志
+func CreateLogEntries(entries []model.LogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return database.GetLogDB().CreateInBatches(entries, 500).Error
+}
+
+// ListLogEntries 查询日志，结果按时间顺序排列
+// 指定 AfterID 时返回其后最早的 Limit 行，否则返回最新的 Limit 行
+func ListLogEntries(q LogQuery) ([]model.LogEntry, error) {
+	query := database.GetLogDB().Model(&model.LogEntry{})
+
+	if q.HostID != "" {
+		query = query.Where("host_id = ?", q.HostID)
+	}
+	if q.Container != "" {
+		query = query.Where(`container_name = ? OR container_id LIKE ? ESCAPE '\'`, q.Container, likeEscaper.Replace(q.Container)+"%")
+	}
+	if q.Project != "" {
+		query = query.Where("project = ?", q.Project)
+	}
+	if q.Service != "" {
+		query = query.Where("service = ?", q.Service)
+	}
+	if q.Stream != "" {
+		query = query.Where("stream = ?", q.Stream)
+	}
+	if !q.Since.IsZero() {
+		query = query.Where("time >= ?", q.Since.UTC())
+	}
+	if !q.Until.IsZero() {
+		query = query.Where("time <= ?", q.Until.UTC())
+	}
+	if q.Text != "" {
+		// 按内容搜索无法使用索引，会扫描其他条件筛选后的所有行
+		query = query.Where(`message LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(q.Text)+"%")
+	}
+	if q.BeforeID > 0 {
+		query = query.Where("id < ?", q.BeforeID)
+	}
+
+	var entries []model.LogEntry
+	if q.AfterID > 0 {
+		err := query.Where("id > ?", q.AfterID).Order("id ASC").Limit(q.Limit).Find(&entries).Error
+		return entries, err
+	}
+
+	if err := query.Order("id DESC").Limit(q.Limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// LastLogTime 获取容器已存储的最新日志时间，没有日志时返回零值
+func LastLogTime(containerID string) (time.Time, error) {
+	var entry model.LogEntry
+	err := database.GetLogDB().Where("container_id = ?", containerID).
+		Order("time DESC").Limit(1).Find(&entry).Error
+	return entry.Time, err
+}
+
+// GetLogStoreStats 获取日志存储统计
+func GetLogStoreStats() (*LogStoreStats, error) {
+	db := database.GetLogDB()
+
+	var row struct {
+		Count  int64
+		Oldest string
+		Newest string
+	}
+	err := db.Model(&model.LogEntry{}).
+		Select("COUNT(*) AS count, COALESCE(MIN(time), '') AS oldest, COALESCE(MAX(time), '') AS newest").
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var pageSize, pageCount, freelist int64
+	if err := db.Raw("PRAGMA page_size").Scan(&pageSize).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Raw("PRAGMA page_count").Scan(&pageCount).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Raw("PRAGMA freelist_count").Scan(&freelist).Error; err != nil {
+		return nil, err
+	}
+
+	stats := &LogStoreStats{Count: row.Count, Size: (pageCount - freelist) * pageSize}
+	if t, ok := parseDBTime(row.Oldest); ok {
+		stats.Oldest = &t
+	}
+	if t, ok := parseDBTime(row.Newest); ok {
+		stats.Newest = &t
+	}
+	return stats, nil
+}
+
+// DeleteLogEntriesBefore 删除指定时间之前的日志
+func DeleteLogEntriesBefore(t time.Time) (int64, error) {
+	result := database.GetLogDB().Where("time < ?", t.UTC()).Delete(&model.LogEntry{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteOldestLogEntries 删除最早写入的 n 行日志
+func DeleteOldestLogEntries(n int) (int64, error) {
+	db := database.GetLogDB()
+	result := db.Where("id IN (?)", db.Model(&model.LogEntry{}).Select("id").Order("id ASC").Limit(n)).
+		Delete(&model.LogEntry{})
+	return result.RowsAffected, result.Error
+}
+
+// ReclaimLogSpace 将删除日志后的空闲页归还给文件系统，并截断 WAL 文件
+func ReclaimLogSpace() error {
+	db := database.GetLogDB()
+	// incremental_vacuum 每读取一行结果释放一页，需要读完所有结果
+	rows, err := db.Raw("PRAGMA incremental_vacuum").Rows()
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	return db.Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error
+}
+
+// parseDBTime 解析 sqlite 聚合函数返回的时间文本
+func parseDBTime(s string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999-07:00", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
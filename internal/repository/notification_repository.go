@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"rubick/internal/database"
+	"rubick/internal/model"
+)
+
+// ListNotificationHooks 获取通知 Webhook 列表
+func ListNotificationHooks() ([]model.NotificationHook, error) {
+	var hooks []model.NotificationHook
+	if err := database.GetDB().Order("name ASC").Find(&hooks).Error; err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// ListEnabledNotificationHooks 获取启用的、适用于指定主机的通知 Webhook
+func ListEnabledNotificationHooks(hostID string) ([]model.NotificationHook, error) {
+	var hooks []model.NotificationHook
+	err := database.GetDB().
+		Where("enabled = ?", true).
+		Where(database.GetDB().
+			Where("(host_id = '' OR host_id IS NULL) AND (group_id = '' OR group_id IS NULL)").
+			Or("host_id = ?", hostID).
+			Or("group_id IN (?)", database.GetDB().Model(&model.HostGroupMember{}).
+				Select("group_id").Where("host_id = ?", hostID))).
+		Find(&hooks).Error
+	if err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// GetNotificationHookByID 根据 ID 获取通知 Webhook
+func GetNotificationHookByID(id string) (*model.NotificationHook, error) {
+	var hook model.NotificationHook
+	if err := database.GetDB().First(&hook, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+// CreateNotificationHook 创建通知 Webhook
+func CreateNotificationHook(hook *model.NotificationHook) error {
+	return database.GetDB().Create(hook).Error
+}
+
+// SaveNotificationHook 保存通知 Webhook 的全部字段
+func SaveNotificationHook(hook *model.NotificationHook) error {
+	return database.GetDB().Save(hook).Error
+}
+
+// DeleteNotificationHook 删除通知 Webhook
+func DeleteNotificationHook(id string) error {
+	return database.GetDB().Delete(&model.NotificationHook{}, "id = ?", id).Error
+}
+
+// CountNotificationHooksByGroup 统计引用指定主机分组的通知 Webhook 数量
+func CountNotificationHooksByGroup(groupID string) (int64, error) {
+	var count int64
+	err := database.GetDB().Model(&model.NotificationHook{}).Where("group_id = ?", groupID).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"time"
+
+	"rubick/internal/database"
+	"rubick/internal/model"
+)
+
+// RecordingQuery 终端会话录像查询条件，零值字段不作为条件
+type RecordingQuery struct {
+	HostID      string
+	ContainerID string // 容器 ID 前缀或名称
+	Since       time.Time
+	Until       time.Time
+}
+
+// ListRecordings 分页获取终端会话录像，按开始时间倒序
+func ListRecordings(q RecordingQuery, page, pageSize int) ([]model.TerminalRecording, int64, error) {
+	var recordings []model.TerminalRecording
+	var total int64
+
+	query := database.GetDB().Model(&model.TerminalRecording{})
+	if q.HostID != "" {
+		query = query.Where("host_id = ?", q.HostID)
+	}
+	if q.ContainerID != "" {
+		query = query.Where("container_id LIKE ? OR container_name = ?", q.ContainerID+"%", q.ContainerID)
+	}
+	if !q.Since.IsZero() {
+		query = query.Where("started_at >= ?", q.Since.UTC())
+	}
+	if !q.Until.IsZero() {
+		query = query.Where("started_at < ?", q.Until.UTC())
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("started_at DESC").Offset(offset).Limit(pageSize).Find(&recordings).Error; err != nil {
+		return nil, 0, err
+	}
+	return recordings, total, nil
+}
+
+// GetRecordingByID 根据 ID 获取终端会话录像
+func GetRecordingByID(id string) (*model.TerminalRecording, error) {
+	var recording model.TerminalRecording
+	if err := database.GetDB().First(&recording, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &recording, nil
+}
+
+// CreateRecording 创建终端会话录像记录
+func CreateRecording(recording *model.TerminalRecording) error {
+	return database.GetDB().Create(recording).Error
+}
+
+// FinishRecording 保存会话结束时的录像信息
+func FinishRecording(recording *model.TerminalRecording) error {
+	return database.GetDB().Model(recording).
+		Select("size", "duration", "exit_code", "ended_at").
+		Updates(recording).Error
+}
+
+// DeleteRecording 删除终端会话录像记录
+func DeleteRecording(id string) error {
+	return database.GetDB().Delete(&model.TerminalRecording{}, "id = ?", id).Error
+}
+
+// ListRecordingsEndedBefore 获取主机上在指定时间之前结束的录像
+func ListRecordingsEndedBefore(hostID string, before time.Time) ([]model.TerminalRecording, error) {
+	var recordings []model.TerminalRecording
+	err := database.GetDB().
+		Where("host_id = ? AND ended_at < ?", hostID, before.UTC()).
+		Find(&recordings).Error
+	return recordings, err
+}
+
+// ListOrphanRecordingsEndedBefore 获取不属于给定主机（主机已删除）且在指定时间之前结束的录像
+func ListOrphanRecordingsEndedBefore(hostIDs []string, before time.Time) ([]model.TerminalRecording, error) {
+	var recordings []model.TerminalRecording
+	query := database.GetDB().Where("ended_at < ?", before.UTC())
+	if len(hostIDs) > 0 {
+		query = query.Where("host_id NOT IN ?", hostIDs)
+	}
+	err := query.Find(&recordings).Error
+	return recordings, err
+}
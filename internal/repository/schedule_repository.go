@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"time"
+
+	"rubick/internal/database"
+	"rubick/internal/model"
+)
+
+// ListScheduledTasks 获取定时任务列表
+func ListScheduledTasks(hostID string) ([]model.ScheduledTask, error) {
+	var tasks []model.ScheduledTask
+	query := database.GetDB().Preload("Host")
+	if hostID != "" {
+		query = query.Where("host_id = ?", hostID)
+	}
+	if err := query.Order("name ASC").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// GetScheduledTaskByID 根据 ID 获取定时任务
+func GetScheduledTaskByID(id string) (*model.ScheduledTask, error) {
+	var task model.ScheduledTask
+	if err := database.GetDB().Preload("Host").First(&task, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CreateScheduledTask 创建定时任务
+func CreateScheduledTask(task *model.ScheduledTask) error {
+	return database.GetDB().Create(task).Error
+}
+
+// SaveScheduledTask 保存定时任务的全部字段
+func SaveScheduledTask(task *model.ScheduledTask) error {
+	return database.GetDB().Omit("Host").Save(task).Error
+}
+
+// DeleteScheduledTask 删除定时任务及其执行记录
+func DeleteScheduledTask(id string) error {
+	if err := database.GetDB().Delete(&model.TaskRun{}, "task_id = ?", id).Error; err != nil {
+		return err
+	}
+	return database.GetDB().Delete(&model.ScheduledTask{}, "id = ?", id).Error
+}
+
+// ListDueScheduledTasks 获取已到执行时间的定时任务
+// next_run_at 以 UTC 保存，比较时同样使用 UTC
+func ListDueScheduledTasks(now time.Time) ([]model.ScheduledTask, error) {
+	var tasks []model.ScheduledTask
+	err := database.GetDB().Preload("Host").
+		Where("enabled = ? AND next_run_at IS NOT NULL AND next_run_at <= ?", true, now.UTC()).
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// ClaimScheduledTaskRun 抢占一次任务执行
+// 只有 next_run_at 仍为读取时的值才会更新成功，保证同一次调度只执行一次
+func ClaimScheduledTaskRun(id string, expected time.Time, next *time.Time, now time.Time) (bool, error) {
+	result := database.GetDB().Model(&model.ScheduledTask{}).
+		Where("id = ? AND next_run_at = ?", id, expected).
+		Updates(map[string]interface{}{
+			"next_run_at": next,
+			"last_run_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UpdateScheduledTaskStatus 更新任务最近一次执行的状态
+func UpdateScheduledTaskStatus(id, status string) error {
+	return database.GetDB().Model(&model.ScheduledTask{}).
+		Where("id = ?", id).
+		Update("last_status", status).Error
+}
+
+// CreateTaskRun 创建执行记录
+func CreateTaskRun(run *model.TaskRun) error {
+	return database.GetDB().Create(run).Error
+}
+
+// GetTaskRunByID 根据 ID 获取执行记录
+func GetTaskRunByID(id string) (*model.TaskRun, error) {
+	var run model.TaskRun
+	if err := database.GetDB().First(&run, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// SaveTaskRun 保存执行记录
+func SaveTaskRun(run *model.TaskRun) error {
+	return database.GetDB().Save(run).Error
+}
+
+// InterruptRunningTaskRuns 将状态为 running 的执行记录改为 interrupted
+// 服务启动时调用，处理上次进程退出时仍在执行的任务；状态值由调度器定义
+func InterruptRunningTaskRuns(running, interrupted string, now time.Time) (int64, error) {
+	result := database.GetDB().Model(&model.TaskRun{}).
+		Where("status = ?", running).
+		Updates(map[string]interface{}{
+			"status":      interrupted,
+			"error":       "服务重启，执行被中断",
+			"finished_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ListTaskRuns 获取任务执行记录列表
+func ListTaskRuns(page, pageSize int, taskID, status string) ([]model.TaskRun, int64, error) {
+	var runs []model.TaskRun
+	var total int64
+
+	query := database.GetDB().Model(&model.TaskRun{})
+
+	if taskID != "" {
+		query = query.Where("task_id = ?", taskID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("started_at DESC").Offset(offset).Limit(pageSize).Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return runs, total, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"time"
+
+	"rubick/internal/database"
+	"rubick/internal/model"
+)
+
+// ListUpdatePolicies 获取更新策略列表
+func ListUpdatePolicies(hostID string) ([]model.UpdatePolicy, error) {
+	var policies []model.UpdatePolicy
+	query := database.GetDB().Preload("Host")
+	if hostID != "" {
+		query = query.Where("host_id = ?", hostID)
+	}
+	if err := query.Order("name ASC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// GetUpdatePolicyByID 根据 ID 获取更新策略
+func GetUpdatePolicyByID(id string) (*model.UpdatePolicy, error) {
+	var policy model.UpdatePolicy
+	if err := database.GetDB().Preload("Host").First(&policy, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// CreateUpdatePolicy 创建更新策略
+func CreateUpdatePolicy(policy *model.UpdatePolicy) error {
+	return database.GetDB().Create(policy).Error
+}
+
+// SaveUpdatePolicy 保存更新策略的全部字段
+func SaveUpdatePolicy(policy *model.UpdatePolicy) error {
+	return database.GetDB().Omit("Host").Save(policy).Error
+}
+
+// DeleteUpdatePolicy 删除更新策略
+func DeleteUpdatePolicy(id string) error {
+	return database.GetDB().Delete(&model.UpdatePolicy{}, "id = ?", id).Error
+}
+
+// ListDueUpdatePolicies 获取已到执行时间的更新策略
+// next_run_at 以 UTC 保存，比较时同样使用 UTC
+func ListDueUpdatePolicies(now time.Time) ([]model.UpdatePolicy, error) {
+	var policies []model.UpdatePolicy
+	err := database.GetDB().Preload("Host").
+		Where("enabled = ? AND next_run_at IS NOT NULL AND next_run_at <= ?", true, now.UTC()).
+		Find(&policies).Error
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// ClaimUpdatePolicyRun 抢占一次策略执行
+// 只有 next_run_at 仍为读取时的值才会更新成功，保证同一次调度只执行一次
+func ClaimUpdatePolicyRun(id string, expected time.Time, next *time.Time, now time.Time) (bool, error) {
+	result := database.GetDB().Model(&model.UpdatePolicy{}).
+		Where("id = ? AND next_run_at = ?", id, expected).
+		Updates(map[string]interface{}{
+			"next_run_at": next,
+			"last_run_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// CreateUpdateRecord 创建更新记录
+func CreateUpdateRecord(record *model.UpdateRecord) error {
+	return database.GetDB().Create(record).Error
+}
+
+// ListUpdateRecords 获取更新记录列表
+func ListUpdateRecords(page, pageSize int, policyID, hostID string) ([]model.UpdateRecord, int64, error) {
+	var records []model.UpdateRecord
+	var total int64
+
+	query := database.GetDB().Model(&model.UpdateRecord{})
+
+	if policyID != "" {
+		query = query.Where("policy_id = ?", policyID)
+	}
+	if hostID != "" {
+		query = query.Where("host_id = ?", hostID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
@@ -0,0 +1,343 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"rubick/internal/backup"
+	"rubick/internal/cron"
+	"rubick/internal/docker"
+	"rubick/internal/model"
+	"rubick/internal/repository"
+	"rubick/internal/stream"
+)
+
+// 执行记录状态
+const (
+	RunRunning     = "running"
+	RunSucceeded   = "succeeded"
+	RunFailed      = "failed"
+	RunSkipped     = "skipped"
+	RunInterrupted = "interrupted"
+)
+
+// 触发方式
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+const (
+	// pollInterval 检查到期任务的间隔
+	pollInterval = 15 * time.Second
+	// misfireGrace 超过计划时间多久后不再补执行（例如服务停机期间错过的任务）
+	misfireGrace = 5 * time.Minute
+	// defaultTimeout 未指定超时时间时的默认值
+	defaultTimeout = 10 * time.Minute
+	// maxOutput 执行记录中保存的最大输出长度
+	maxOutput = 64 * 1024
+)
+
+// Scheduler 定时任务调度器
+type Scheduler struct {
+	runner *cron.Runner[model.ScheduledTask]
+}
+
+var (
+	scheduler     *Scheduler
+	schedulerOnce sync.Once
+)
+
+// GetScheduler 获取定时任务调度器单例
+func GetScheduler() *Scheduler {
+	schedulerOnce.Do(func() {
+		scheduler = &Scheduler{}
+		scheduler.runner = cron.NewRunner("定时任务", pollInterval, cron.Jobs[model.ScheduledTask]{
+			Due:      repository.ListDueScheduledTasks,
+			Describe: describe,
+			Claim:    repository.ClaimScheduledTaskRun,
+			Fire:     scheduler.fire,
+		})
+	})
+	return scheduler
+}
+
+// Start 启动调度循环
+func (s *Scheduler) Start() {
+	// 上次退出时仍在执行的任务不会继续，标记为中断，不自动重试
+	if n, err := repository.InterruptRunningTaskRuns(RunRunning, RunInterrupted, time.Now()); err != nil {
+		log.Printf("更新中断的任务执行记录失败: %v", err)
+	} else if n > 0 {
+		log.Printf("%d 个任务在上次退出时被中断", n)
+	}
+
+	s.runner.Start()
+}
+
+// Stop 停止调度并等待正在执行的任务结束
+func (s *Scheduler) Stop() {
+	s.runner.Stop()
+}
+
+// Trigger 立即执行任务，返回执行记录
+func (s *Scheduler) Trigger(taskID string) (*model.TaskRun, error) {
+	task, err := repository.GetScheduledTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	run, ok := s.launch(task, TriggerManual)
+	if !ok {
+		return nil, fmt.Errorf("任务正在执行中")
+	}
+	return run, nil
+}
+
+// IsRunning 判断任务是否正在执行
+func (s *Scheduler) IsRunning(taskID string) bool {
+	return s.runner.IsRunning(taskID)
+}
+
+// describe 返回任务的调度信息
+func describe(t *model.ScheduledTask) cron.Job {
+	return cron.Job{
+		ID:        t.ID,
+		Name:      t.Name,
+		Expr:      t.Schedule,
+		Timezone:  t.Timezone,
+		Enabled:   t.Enabled,
+		Scheduled: *t.NextRunAt,
+	}
+}
+
+// fire 执行已抢占的到期任务，错过太久或上一次执行未结束时记录跳过
+func (s *Scheduler) fire(task *model.ScheduledTask, scheduled, now time.Time) {
+	if now.Sub(scheduled) > misfireGrace {
+		s.skip(task, fmt.Sprintf("错过计划执行时间 %s", scheduled.Local().Format(time.DateTime)))
+		return
+	}
+
+	if _, ok := s.launch(task, TriggerSchedule); !ok {
+		s.skip(task, "上一次执行尚未结束")
+	}
+}
+
+// skip 记录一次跳过的执行
+func (s *Scheduler) skip(task *model.ScheduledTask, reason string) {
+	now := time.Now()
+	run := &model.TaskRun{
+		TaskID:     task.ID,
+		HostID:     task.HostID,
+		Action:     task.Action,
+		Target:     task.Target,
+		Trigger:    TriggerSchedule,
+		Status:     RunSkipped,
+		Error:      reason,
+		StartedAt:  now,
+		FinishedAt: &now,
+	}
+	if err := repository.CreateTaskRun(run); err != nil {
+		log.Printf("保存任务执行记录失败: %v", err)
+	}
+	repository.UpdateScheduledTaskStatus(task.ID, RunSkipped)
+}
+
+// launch 在后台执行任务，同一任务同时只执行一次
+func (s *Scheduler) launch(task *model.ScheduledTask, trigger string) (*model.TaskRun, bool) {
+	if !s.runner.Acquire(task.ID) {
+		return nil, false
+	}
+
+	run := &model.TaskRun{
+		TaskID:    task.ID,
+		HostID:    task.HostID,
+		Action:    task.Action,
+		Target:    task.Target,
+		Trigger:   trigger,
+		Status:    RunRunning,
+		StartedAt: time.Now(),
+	}
+	if err := repository.CreateTaskRun(run); err != nil {
+		log.Printf("保存任务执行记录失败: %v", err)
+	}
+	repository.UpdateScheduledTaskStatus(task.ID, RunRunning)
+
+	// 返回副本，避免调用方与后台执行并发读写
+	snapshot := *run
+	stream.PublishJob(&snapshot)
+
+	timeout := defaultTimeout
+	if task.Timeout > 0 {
+		timeout = time.Duration(task.Timeout) * time.Second
+	}
+	s.runner.Go(task.ID, timeout, func(ctx context.Context) {
+		output, exitCode, err := s.execute(ctx, task)
+		s.finish(task, run, output, exitCode, err)
+	})
+
+	return &snapshot, true
+}
+
+// finish 保存执行结果并写入审计日志
+func (s *Scheduler) finish(task *model.ScheduledTask, run *model.TaskRun, output string, exitCode *int, err error) {
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Output = truncate(output)
+	run.ExitCode = exitCode
+	run.Status = RunSucceeded
+	switch {
+	case err != nil:
+		run.Status = RunFailed
+		run.Error = err.Error()
+	case exitCode != nil && *exitCode != 0:
+		run.Status = RunFailed
+		run.Error = fmt.Sprintf("命令退出码为 %d", *exitCode)
+	}
+
+	if err := repository.SaveTaskRun(run); err != nil {
+		log.Printf("保存任务执行记录失败: %v", err)
+	}
+	repository.UpdateScheduledTaskStatus(task.ID, run.Status)
+	stream.PublishJob(run)
+
+	message := fmt.Sprintf("定时任务 %s (%s %s): %s", task.Name, task.Action, task.Target, run.Status)
+	status := http.StatusOK
+	if run.Status != RunSucceeded {
+		status = http.StatusInternalServerError
+		message += ": " + run.Error
+	}
+	repository.CreateAuditLog(&model.AuditLog{
+		Method:  "SYSTEM",
+		Path:    "/api/v1/schedules/" + task.ID,
+		Status:  status,
+		Latency: now.Sub(run.StartedAt).Milliseconds(),
+		Message: message,
+	})
+}
+
+// execute 执行任务动作，返回输出和退出码（仅 container_exec 有退出码）
+func (s *Scheduler) execute(ctx context.Context, task *model.ScheduledTask) (string, *int, error) {
+	host := task.Host
+	if host == nil {
+		var err error
+		if host, err = repository.GetHostByID(task.HostID); err != nil {
+			return "", nil, fmt.Errorf("主机不存在: %w", err)
+		}
+	}
+
+	switch task.Action {
+	case ActionComposeStart, ActionComposeStop, ActionComposeRestart:
+		return executeCompose(ctx, task, host)
+	case ActionVolumeBackup:
+		return executeBackup(ctx, task, host)
+	}
+
+	cli, err := docker.GetManager().GetDockerClient(ctx, host)
+	if err != nil {
+		return "", nil, err
+	}
+	containers := docker.NewContainerService(cli)
+
+	switch task.Action {
+	case ActionContainerStart:
+		return "", nil, containers.Start(ctx, task.Target)
+	case ActionContainerStop:
+		return "", nil, containers.Stop(ctx, task.Target, nil)
+	case ActionContainerRestart:
+		return "", nil, containers.Restart(ctx, task.Target, nil)
+	case ActionContainerExec:
+		result, err := containers.ExecRun(ctx, task.Target, docker.ExecOptions{
+			Cmd:       task.Command,
+			User:      task.User,
+			MaxOutput: maxOutput,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		output := result.Stdout
+		if result.Stderr != "" {
+			output += "\n[stderr]\n" + result.Stderr
+		}
+		return output, result.ExitCode, nil
+	case ActionImagePrune:
+		report, err := docker.NewImageService(cli).Prune(ctx, task.PruneAll)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("删除 %d 个镜像，释放 %d 字节", len(report.ImagesDeleted), report.SpaceReclaimed), nil, nil
+	default:
+		return "", nil, fmt.Errorf("无效的任务动作: %s", task.Action)
+	}
+}
+
+// executeCompose 执行 Compose 项目动作
+func executeCompose(ctx context.Context, task *model.ScheduledTask, host *model.Host) (string, *int, error) {
+	project, err := repository.GetComposeProjectByID(task.Target)
+	if err != nil {
+		return "", nil, fmt.Errorf("Compose 项目不存在: %w", err)
+	}
+	if project.HostID != host.ID {
+		return "", nil, fmt.Errorf("Compose 项目不属于任务所在主机")
+	}
+
+	executor, err := docker.NewHostExecutor(host)
+	if err != nil {
+		return "", nil, err
+	}
+	defer executor.Close()
+
+	compose := docker.NewComposeService(executor)
+	opts := docker.ComposeOptionsForProject(project)
+
+	var output []byte
+	status := "running"
+	switch task.Action {
+	case ActionComposeStart:
+		output, err = compose.Start(ctx, project.Content, opts, nil)
+	case ActionComposeStop:
+		output, err = compose.Stop(ctx, project.Content, opts, 0, nil)
+		status = "stopped"
+	case ActionComposeRestart:
+		output, err = compose.Restart(ctx, project.Content, opts, 0, nil)
+	}
+	if err != nil {
+		return string(output), nil, err
+	}
+
+	repository.UpdateComposeProjectStatus(project.ID, status)
+	return string(output), nil, nil
+}
+
+// executeBackup 备份卷，并按保留数量清理该任务的旧备份
+func executeBackup(ctx context.Context, task *model.ScheduledTask, host *model.Host) (string, *int, error) {
+	b, err := backup.Create(ctx, host, task.Target, backup.SourceSchedule, task.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	output := fmt.Sprintf("备份已保存: %s (%d 字节)", b.FileName, b.Size)
+
+	deleted, err := backup.Prune(task.ID, task.Retain)
+	if deleted > 0 {
+		output += fmt.Sprintf("\n清理 %d 个旧备份", deleted)
+	}
+	if err != nil {
+		return output, nil, fmt.Errorf("清理旧备份失败: %w", err)
+	}
+	return output, nil, nil
+}
+
+// truncate 截断过长的输出，保留末尾部分
+func truncate(output string) string {
+	if len(output) <= maxOutput {
+		return output
+	}
+	output = output[len(output)-maxOutput:]
+	if i := strings.IndexByte(output, '\n'); i >= 0 {
+		output = output[i+1:]
+	}
+	return "...(输出已截断)\n" + output
+}
@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"rubick/internal/backup"
+	"rubick/internal/cron"
+	"rubick/internal/model"
+)
+
+// 任务动作
+const (
+	ActionContainerStart   = "container_start"
+	ActionContainerStop    = "container_stop"
+	ActionContainerRestart = "container_restart"
+	ActionContainerExec    = "container_exec"
+	ActionComposeStart     = "compose_start"
+	ActionComposeStop      = "compose_stop"
+	ActionComposeRestart   = "compose_restart"
+	ActionImagePrune       = "image_prune"
+	ActionVolumeBackup     = "volume_backup"
+)
+
+// ValidateTask 校验定时任务配置
+func ValidateTask(t *model.ScheduledTask) error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("任务名称不能为空")
+	}
+	if t.HostID == "" {
+		return fmt.Errorf("主机 ID 不能为空")
+	}
+
+	switch t.Action {
+	case ActionContainerStart, ActionContainerStop, ActionContainerRestart:
+		if t.Target == "" {
+			return fmt.Errorf("必须指定目标容器")
+		}
+	case ActionContainerExec:
+		if t.Target == "" {
+			return fmt.Errorf("必须指定目标容器")
+		}
+		if len(t.Command) == 0 {
+			return fmt.Errorf("必须指定要执行的命令")
+		}
+	case ActionComposeStart, ActionComposeStop, ActionComposeRestart:
+		if t.Target == "" {
+			return fmt.Errorf("必须指定目标 Compose 项目")
+		}
+	case ActionImagePrune:
+	case ActionVolumeBackup:
+		if err := backup.ValidateVolumeName(t.Target); err != nil {
+			return err
+		}
+		if t.Retain < 0 {
+			return fmt.Errorf("保留数量不能为负数")
+		}
+	default:
+		return fmt.Errorf("无效的任务动作: %s", t.Action)
+	}
+
+	if t.Timeout < 0 {
+		return fmt.Errorf("超时时间不能为负数")
+	}
+	if err := cron.Validate(t.Schedule); err != nil {
+		return err
+	}
+	if _, err := cron.LoadLocation(t.Timezone); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NextRunAt 计算任务的下一次执行时间（UTC），任务未启用时返回 nil
+func NextRunAt(t *model.ScheduledTask, after time.Time) (*time.Time, error) {
+	return cron.NextRun(t.Schedule, t.Timezone, t.Enabled, after)
+}
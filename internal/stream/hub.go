@@ -0,0 +1,394 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// bufferSize 每个主题保留的最近消息数，断线重连后可以从中续传
+	bufferSize = 1000
+	// idleTimeout 主题没有订阅者后保留的时长，超时后停止生产者并丢弃缓冲
+	idleTimeout = time.Minute
+	// sweepInterval 清理空闲主题的间隔
+	sweepInterval = 10 * time.Second
+)
+
+// Message 主题中的一条消息，Seq 在主题内从 1 开始递增
+type Message struct {
+	Seq  uint64          `json:"seq"`
+	Time time.Time       `json:"time"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Batch 订阅者一次读取的结果
+type Batch struct {
+	Messages []Message
+	Skipped  uint64 // 读取过慢或续传位置过早，已被缓冲淘汰而跳过的消息数
+	Reset    bool   // 续传位置晚于主题当前序号，主题已重建，订阅者应丢弃之前的数据
+	Ended    bool   // 生产者已结束且消息已全部读取
+	Error    string // 生产者结束的原因
+}
+
+// Hub 按主题分发实时数据
+// 主题在第一次订阅时创建并启动生产者，所有订阅者共享同一个生产者和消息缓冲
+type Hub struct {
+	mu     sync.Mutex
+	topics map[string]*topic
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// topic 单个主题的状态
+type topic struct {
+	name   string
+	kind   *kind
+	args   []string
+	epoch  string // 主题创建时生成，主题被清理后重建时序号从 1 开始，续传时需要校验
+	mu     sync.Mutex
+	buf    []Message
+	seq    uint64
+	subs   map[*Subscription]bool
+	cancel context.CancelFunc // 正在运行的生产者，为空表示未运行
+	ended  bool
+	err    string
+	idle   time.Time // 最近一次变为无人订阅（推送型主题为最近一次发布）的时间
+}
+
+// Subscription 对主题的订阅
+type Subscription struct {
+	hub   *Hub
+	topic *topic
+	wake  chan struct{}
+}
+
+var (
+	hub     *Hub
+	hubOnce sync.Once
+)
+
+// GetHub 获取主题分发器单例
+func GetHub() *Hub {
+	hubOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		hub = &Hub{
+			topics: make(map[string]*topic),
+			ctx:    ctx,
+			cancel: cancel,
+		}
+	})
+	return hub
+}
+
+// Start 启动空闲主题清理
+func (h *Hub) Start() {
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+
+		ticker := time.NewTicker(sweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-h.ctx.Done():
+				return
+			case <-ticker.C:
+				h.sweep()
+			}
+		}
+	}()
+}
+
+// Stop 停止所有生产者
+func (h *Hub) Stop() {
+	h.cancel()
+	h.wg.Wait()
+}
+
+// Subscribe 订阅主题，有新消息时向 wake 发送信号
+// wake 应带缓冲，多个订阅可以共用一个 wake
+func (h *Hub) Subscribe(name string, wake chan struct{}) (*Subscription, error) {
+	k, args, err := parseTopic(name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := h.topics[name]
+	if !ok {
+		t = newTopic(name, k, args)
+		h.topics[name] = t
+	}
+
+	// 生产者已结束的主题（如容器停止后重新启动）在新的订阅时重新启动，序号继续递增
+	t.mu.Lock()
+	ended := t.ended
+	t.mu.Unlock()
+	if !ok || (ended && k.produce != nil) {
+		if err := h.start(t); err != nil {
+			if !ok {
+				delete(h.topics, name)
+			}
+			return nil, err
+		}
+	}
+
+	sub := &Subscription{hub: h, topic: t, wake: wake}
+	t.mu.Lock()
+	t.subs[sub] = true
+	t.mu.Unlock()
+	return sub, nil
+}
+
+// Publish 向推送型主题发布消息，主题不存在时创建
+func (h *Hub) Publish(name string, data interface{}) {
+	t, err := h.pushTopic(name)
+	if err != nil {
+		log.Printf("发布消息到主题 %s 失败: %v", name, err)
+		return
+	}
+	t.publish(data)
+}
+
+// End 结束推送型主题，订阅者读取完剩余消息后收到结束通知
+func (h *Hub) End(name string) {
+	t, err := h.pushTopic(name)
+	if err != nil {
+		return
+	}
+	t.finish(nil)
+}
+
+// pushTopic 获取或创建推送型主题
+func (h *Hub) pushTopic(name string) (*topic, error) {
+	k, args, err := parseTopic(name)
+	if err != nil {
+		return nil, err
+	}
+	if k.produce != nil {
+		return nil, fmt.Errorf("主题 %s 由服务端生产，不能直接发布", name)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	t, ok := h.topics[name]
+	if !ok {
+		t = newTopic(name, k, args)
+		t.idle = time.Now()
+		h.topics[name] = t
+	}
+	return t, nil
+}
+
+// newTopic 创建主题并生成新的 epoch
+func newTopic(name string, k *kind, args []string) *topic {
+	return &topic{
+		name:  name,
+		kind:  k,
+		args:  args,
+		epoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		subs:  make(map[*Subscription]bool),
+	}
+}
+
+// start 启动主题的生产者，调用方需持有 h.mu
+func (h *Hub) start(t *topic) error {
+	if t.kind.init != nil {
+		if err := t.kind.init(t, t.args); err != nil {
+			return err
+		}
+	}
+	if t.kind.produce == nil {
+		return nil
+	}
+
+	// 重新启动时把最后一条消息交给生产者，由生产者从该位置继续，避免重复发布
+	ctx, cancel := context.WithCancel(h.ctx)
+	t.mu.Lock()
+	t.cancel = cancel
+	t.ended = false
+	t.err = ""
+	var last json.RawMessage
+	if len(t.buf) > 0 {
+		last = t.buf[len(t.buf)-1].Data
+	}
+	t.mu.Unlock()
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		err := t.kind.produce(ctx, t.args, last, t.publish)
+		if ctx.Err() != nil && err != nil {
+			err = nil
+		}
+		t.finish(err)
+	}()
+	return nil
+}
+
+// sweep 删除空闲超时的主题并停止其生产者
+func (h *Hub) sweep() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for name, t := range h.topics {
+		t.mu.Lock()
+		expired := len(t.subs) == 0 && time.Since(t.idle) > idleTimeout
+		cancel := t.cancel
+		t.mu.Unlock()
+		if !expired {
+			continue
+		}
+		if cancel != nil {
+			cancel()
+		}
+		delete(h.topics, name)
+	}
+}
+
+// publish 追加消息并唤醒订阅者
+func (t *topic) publish(data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("序列化主题 %s 的消息失败: %v", t.name, err)
+		return
+	}
+
+	t.mu.Lock()
+	t.seq++
+	t.buf = append(t.buf, Message{Seq: t.seq, Time: time.Now(), Data: raw})
+	if len(t.buf) > bufferSize {
+		// 超出一倍时整体复制，避免底层数组无限增长
+		if len(t.buf) >= 2*bufferSize {
+			t.buf = append([]Message(nil), t.buf[len(t.buf)-bufferSize:]...)
+		} else {
+			t.buf = t.buf[1:]
+		}
+	}
+	if t.kind.produce == nil {
+		t.idle = time.Now()
+	}
+	t.notify()
+	t.mu.Unlock()
+}
+
+// finish 标记生产者结束并唤醒订阅者
+func (t *topic) finish(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ended = true
+	t.cancel = nil
+	if err != nil {
+		t.err = err.Error()
+	}
+	t.notify()
+}
+
+// notify 唤醒所有订阅者，调用方需持有 t.mu
+func (t *topic) notify() {
+	for sub := range t.subs {
+		select {
+		case sub.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Topic 获取订阅的主题名称
+func (s *Subscription) Topic() string {
+	return s.topic.name
+}
+
+// Epoch 获取主题的 epoch，序号只在同一 epoch 内有效
+func (s *Subscription) Epoch() string {
+	return s.topic.epoch
+}
+
+// Tail 返回从缓冲中最后 n 条消息开始读取时的续传位置
+func (s *Subscription) Tail(n int) uint64 {
+	t := s.topic
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n > len(t.buf) {
+		n = len(t.buf)
+	}
+	return t.seq - uint64(n)
+}
+
+// Read 读取序号大于 after 的消息，最多 max 条
+func (s *Subscription) Read(after uint64, max int) Batch {
+	t := s.topic
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var b Batch
+	if after > t.seq {
+		b.Reset = true
+		after = 0
+	}
+	oldest := t.seq + 1
+	if len(t.buf) > 0 {
+		oldest = t.buf[0].Seq
+	}
+	if after+1 < oldest {
+		if !b.Reset {
+			b.Skipped = oldest - after - 1
+		}
+		after = oldest - 1
+	}
+
+	start := len(t.buf) - int(t.seq-after)
+	end := start + max
+	if end > len(t.buf) {
+		end = len(t.buf)
+	}
+	if start < end {
+		b.Messages = append([]Message(nil), t.buf[start:end]...)
+		after = b.Messages[len(b.Messages)-1].Seq
+	}
+	if t.ended && after == t.seq {
+		b.Ended = true
+		b.Error = t.err
+	}
+	return b
+}
+
+// Close 取消订阅
+func (s *Subscription) Close() {
+	t := s.topic
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.subs, s)
+	if len(t.subs) == 0 {
+		t.idle = time.Now()
+	}
+}
+
+// parseTopic 解析主题名称，格式为 类型:参数1:参数2
+func parseTopic(name string) (*kind, []string, error) {
+	parts := strings.Split(name, ":")
+	k, ok := kinds[parts[0]]
+	if !ok {
+		return nil, nil, fmt.Errorf("不支持的主题类型: %s", parts[0])
+	}
+	args := parts[1:]
+	if len(args) != len(k.params) {
+		return nil, nil, fmt.Errorf("主题格式应为 %s:%s", parts[0], strings.Join(k.params, ":"))
+	}
+	for i, a := range args {
+		if a == "" {
+			return nil, nil, fmt.Errorf("主题参数 %s 不能为空", k.params[i])
+		}
+	}
+	return k, args, nil
+}
@@ -0,0 +1,126 @@
+package stream
+
+import (
+	"errors"
+	"testing"
+)
+
+// newTestSubscription 创建一个推送型主题并发布 n 条消息
+func newTestSubscription(n int) *Subscription {
+	t := newTopic("test", &kind{}, nil)
+	for i := 1; i <= n; i++ {
+		t.publish(i)
+	}
+	return &Subscription{topic: t, wake: make(chan struct{}, 1)}
+}
+
+func seqs(b Batch) []uint64 {
+	var out []uint64
+	for _, m := range b.Messages {
+		out = append(out, m.Seq)
+	}
+	return out
+}
+
+func TestSubscriptionRead(t *testing.T) {
+	tests := []struct {
+		name      string
+		published int
+		after     uint64
+		max       int
+		first     uint64 // 期望的第一条消息序号，0 表示没有消息
+		count     int
+		skipped   uint64
+		reset     bool
+	}{
+		{name: "从头读取", published: 5, after: 0, max: 10, first: 1, count: 5},
+		{name: "续传", published: 5, after: 3, max: 10, first: 4, count: 2},
+		{name: "限制条数", published: 5, after: 1, max: 2, first: 2, count: 2},
+		{name: "已读完", published: 5, after: 5, max: 10},
+		{name: "空主题", published: 0, after: 0, max: 10},
+		{name: "缓冲已满时从头读取", published: bufferSize + 10, after: 0, max: 5, first: 11, count: 5, skipped: 10},
+		{name: "续传位置被淘汰", published: bufferSize + 10, after: 4, max: 5, first: 11, count: 5, skipped: 6},
+		{name: "续传位置恰好是最早消息的前一条", published: bufferSize + 10, after: 10, max: 5, first: 11, count: 5},
+		{name: "续传位置在缓冲内", published: bufferSize + 10, after: bufferSize + 8, max: 5, first: bufferSize + 9, count: 2},
+		{name: "续传位置晚于当前序号", published: 5, after: 9, max: 10, first: 1, count: 5, reset: true},
+		{name: "重置时不计入跳过", published: bufferSize + 10, after: bufferSize + 20, max: 5, first: 11, count: 5, reset: true},
+		{name: "重置后的空主题", published: 0, after: 3, max: 10, reset: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestSubscription(tt.published).Read(tt.after, tt.max)
+			got := seqs(b)
+			if len(got) != tt.count {
+				t.Fatalf("读取到 %d 条消息 %v，期望 %d 条", len(got), got, tt.count)
+			}
+			for i, seq := range got {
+				if seq != tt.first+uint64(i) {
+					t.Fatalf("消息序号为 %v，期望从 %d 开始连续", got, tt.first)
+				}
+			}
+			if b.Skipped != tt.skipped {
+				t.Errorf("Skipped = %d，期望 %d", b.Skipped, tt.skipped)
+			}
+			if b.Reset != tt.reset {
+				t.Errorf("Reset = %v，期望 %v", b.Reset, tt.reset)
+			}
+			if b.Ended {
+				t.Error("主题未结束时 Ended 应为 false")
+			}
+		})
+	}
+}
+
+func TestSubscriptionReadEnded(t *testing.T) {
+	s := newTestSubscription(3)
+	s.topic.finish(errors.New("容器已删除"))
+
+	// 还有未读消息时不通知结束
+	b := s.Read(0, 2)
+	if b.Ended || len(b.Messages) != 2 {
+		t.Fatalf("Read(0, 2) = %d 条消息, Ended = %v", len(b.Messages), b.Ended)
+	}
+	// 读到最后一条时一并通知结束
+	b = s.Read(2, 10)
+	if !b.Ended || b.Error != "容器已删除" || len(b.Messages) != 1 {
+		t.Fatalf("Read(2, 10) = %d 条消息, Ended = %v, Error = %q", len(b.Messages), b.Ended, b.Error)
+	}
+	b = s.Read(3, 10)
+	if !b.Ended || len(b.Messages) != 0 {
+		t.Fatalf("Read(3, 10) = %d 条消息, Ended = %v", len(b.Messages), b.Ended)
+	}
+}
+
+func TestSubscriptionTail(t *testing.T) {
+	s := newTestSubscription(bufferSize + 10)
+	for _, tt := range []struct {
+		n    int
+		want uint64
+	}{
+		{0, bufferSize + 10},
+		{3, bufferSize + 7},
+		{bufferSize, 10},
+		{bufferSize + 5, 10},
+	} {
+		if got := s.Tail(tt.n); got != tt.want {
+			t.Errorf("Tail(%d) = %d，期望 %d", tt.n, got, tt.want)
+		}
+		if b := s.Read(s.Tail(tt.n), bufferSize); len(b.Messages) != min(tt.n, bufferSize) || b.Skipped != 0 {
+			t.Errorf("从 Tail(%d) 读取到 %d 条消息, Skipped = %d", tt.n, len(b.Messages), b.Skipped)
+		}
+	}
+}
+
+func TestTopicBufferCompaction(t *testing.T) {
+	s := newTestSubscription(3*bufferSize + 7)
+	tp := s.topic
+	if len(tp.buf) != bufferSize {
+		t.Fatalf("缓冲长度为 %d，期望 %d", len(tp.buf), bufferSize)
+	}
+	if cap(tp.buf) >= 3*bufferSize {
+		t.Fatalf("缓冲容量为 %d，底层数组没有回收", cap(tp.buf))
+	}
+	if first, last := tp.buf[0].Seq, tp.buf[len(tp.buf)-1].Seq; first != 2*bufferSize+8 || last != 3*bufferSize+7 {
+		t.Fatalf("缓冲序号范围为 %d-%d", first, last)
+	}
+}
@@ -0,0 +1,170 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"rubick/internal/docker"
+	"rubick/internal/model"
+	"rubick/internal/repository"
+
+	"github.com/docker/docker/api/types/events"
+)
+
+// 主题类型
+const (
+	KindLogs   = "logs"   // logs:<host_id>:<container_id> 容器日志
+	KindStats  = "stats"  // stats:<host_id>:<container_id> 容器资源统计
+	KindEvents = "events" // events:<host_id> 主机的 Docker 事件
+	KindJob    = "job"    // job:<run_id> 定时任务执行记录的状态和输出
+)
+
+// logsTail 日志主题启动时读取的历史行数
+const logsTail = "100"
+
+// kind 主题类型的定义
+type kind struct {
+	params []string
+	// init 创建主题时调用，可以发布初始消息
+	init func(t *topic, args []string) error
+	// produce 持续生产消息直到 ctx 取消或数据源结束，为空表示推送型主题，消息由 Hub.Publish 发布
+	// last 为重新启动前发布的最后一条消息，首次启动时为空
+	produce func(ctx context.Context, args []string, last json.RawMessage, publish func(interface{})) error
+}
+
+var kinds = map[string]*kind{
+	KindLogs:   {params: []string{"host_id", "container_id"}, produce: produceLogs},
+	KindStats:  {params: []string{"host_id", "container_id"}, produce: produceStats},
+	KindEvents: {params: []string{"host_id"}, produce: produceEvents},
+	KindJob:    {params: []string{"run_id"}, init: initJob},
+}
+
+// JobTopic 定时任务执行记录的主题名称
+func JobTopic(runID string) string {
+	return KindJob + ":" + runID
+}
+
+// Event Docker 事件
+type Event struct {
+	Type       string            `json:"type"`
+	Action     string            `json:"action"`
+	ID         string            `json:"id"`
+	Name       string            `json:"name,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Time       time.Time         `json:"time"`
+}
+
+// containerService 获取主机的容器服务
+func containerService(ctx context.Context, hostID string) (*docker.ContainerService, error) {
+	host, err := repository.GetHostByID(hostID)
+	if err != nil {
+		return nil, fmt.Errorf("主机不存在")
+	}
+	cli, err := docker.GetManager().GetDockerClient(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	return docker.NewContainerService(cli), nil
+}
+
+// produceLogs 跟踪容器日志
+// 重新启动时从最后一条日志的时间继续，不再重复读取最近的 logsTail 行
+func produceLogs(ctx context.Context, args []string, last json.RawMessage, publish func(interface{})) error {
+	svc, err := containerService(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	opts := docker.LogOptions{Follow: true, Timestamps: true, Tail: logsTail}
+	var after time.Time
+	if last != nil {
+		var line docker.LogLine
+		if json.Unmarshal(last, &line) == nil {
+			if t, err := time.Parse(time.RFC3339Nano, line.Timestamp); err == nil {
+				after = t
+				opts.Since = line.Timestamp
+				opts.Tail = "all"
+			}
+		}
+	}
+
+	return svc.StreamLogs(ctx, args[1], opts, func(line docker.LogLine) error {
+		// Docker 返回时间不早于 since 的日志，跳过已发布过的行
+		if !after.IsZero() {
+			if t, err := time.Parse(time.RFC3339Nano, line.Timestamp); err == nil && !t.After(after) {
+				return nil
+			}
+		}
+		publish(line)
+		return nil
+	})
+}
+
+// produceStats 持续读取容器资源统计
+func produceStats(ctx context.Context, args []string, _ json.RawMessage, publish func(interface{})) error {
+	svc, err := containerService(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return svc.StreamStats(ctx, args[1], func(stats *docker.ContainerStats) error {
+		publish(stats)
+		return nil
+	})
+}
+
+// produceEvents 订阅主机的 Docker 事件
+func produceEvents(ctx context.Context, args []string, _ json.RawMessage, publish func(interface{})) error {
+	host, err := repository.GetHostByID(args[0])
+	if err != nil {
+		return fmt.Errorf("主机不存在")
+	}
+	cli, err := docker.GetManager().GetDockerClient(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	messages, errs := cli.Events(ctx, events.ListOptions{})
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errs:
+			return err
+		case msg := <-messages:
+			publish(Event{
+				Type:       string(msg.Type),
+				Action:     string(msg.Action),
+				ID:         msg.Actor.ID,
+				Name:       strings.TrimPrefix(msg.Actor.Attributes["name"], "/"),
+				Attributes: msg.Actor.Attributes,
+				Time:       time.Unix(0, msg.TimeNano),
+			})
+		}
+	}
+}
+
+// initJob 发布执行记录的当前状态，已结束的执行记录同时结束主题
+// 之后的状态变化由调度器发布
+func initJob(t *topic, args []string) error {
+	run, err := repository.GetTaskRunByID(args[0])
+	if err != nil {
+		return fmt.Errorf("执行记录不存在")
+	}
+	t.publish(run)
+	if run.FinishedAt != nil {
+		t.finish(nil)
+	}
+	return nil
+}
+
+// PublishJob 发布执行记录的当前状态，执行结束时同时结束主题
+func PublishJob(run *model.TaskRun) {
+	h := GetHub()
+	h.Publish(JobTopic(run.ID), run)
+	if run.FinishedAt != nil {
+		h.End(JobTopic(run.ID))
+	}
+}
@@ -0,0 +1,122 @@
+package updater
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"rubick/internal/cron"
+	"rubick/internal/docker"
+	"rubick/internal/model"
+)
+
+// 更新目标类型
+const (
+	TargetContainer = "container"
+	TargetCompose   = "compose"
+)
+
+// ValidatePolicy 校验更新策略配置
+func ValidatePolicy(p *model.UpdatePolicy) error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("策略名称不能为空")
+	}
+	if p.HostID == "" {
+		return fmt.Errorf("主机 ID 不能为空")
+	}
+
+	switch p.TargetType {
+	case TargetContainer:
+		if len(docker.ParseLabelSelector(p.LabelSelector)) == 0 {
+			return fmt.Errorf("容器策略必须指定标签选择器")
+		}
+	case TargetCompose:
+		if p.ProjectID == "" {
+			return fmt.Errorf("Compose 策略必须指定项目 ID")
+		}
+		// Compose 项目的镜像标签以 compose 文件为准，只做同标签的摘要更新
+		if p.SemverConstraint != "" || p.TagPattern != "" {
+			return fmt.Errorf("Compose 策略不支持版本约束和标签匹配")
+		}
+	default:
+		return fmt.Errorf("无效的目标类型: %s", p.TargetType)
+	}
+
+	if err := cron.Validate(p.Schedule); err != nil {
+		return err
+	}
+	if _, err := cron.LoadLocation(p.Timezone); err != nil {
+		return err
+	}
+	if p.MaintenanceWindow != "" {
+		if _, _, err := parseWindow(p.MaintenanceWindow); err != nil {
+			return err
+		}
+	}
+	if p.SemverConstraint != "" {
+		if _, err := parseConstraint(p.SemverConstraint); err != nil {
+			return err
+		}
+	}
+	if p.TagPattern != "" {
+		if _, err := regexp.Compile(p.TagPattern); err != nil {
+			return fmt.Errorf("无效的标签匹配正则: %w", err)
+		}
+	}
+	if p.HealthGracePeriod < 0 {
+		return fmt.Errorf("健康检查宽限期不能为负数")
+	}
+	return nil
+}
+
+// NextRunAt 计算策略的下一次执行时间（UTC），策略未启用时返回 nil
+func NextRunAt(p *model.UpdatePolicy, after time.Time) (*time.Time, error) {
+	return cron.NextRun(p.Schedule, p.Timezone, p.Enabled, after)
+}
+
+// InMaintenanceWindow 判断指定时间是否处于策略的维护窗口内
+// 未配置维护窗口时总是返回 true
+func InMaintenanceWindow(p *model.UpdatePolicy, t time.Time) bool {
+	if p.MaintenanceWindow == "" {
+		return true
+	}
+	start, end, err := parseWindow(p.MaintenanceWindow)
+	if err != nil {
+		return false
+	}
+	loc, err := cron.LoadLocation(p.Timezone)
+	if err != nil {
+		return false
+	}
+
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	// 跨越午夜的窗口，如 22:00-04:00
+	return minute >= start || minute < end
+}
+
+// parseWindow 解析 HH:MM-HH:MM 格式的维护窗口，返回起止分钟数
+func parseWindow(window string) (int, int, error) {
+	startStr, endStr, ok := strings.Cut(window, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("维护窗口格式应为 HH:MM-HH:MM: %q", window)
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(startStr))
+	if err != nil {
+		return 0, 0, fmt.Errorf("维护窗口格式应为 HH:MM-HH:MM: %q", window)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(endStr))
+	if err != nil {
+		return 0, 0, fmt.Errorf("维护窗口格式应为 HH:MM-HH:MM: %q", window)
+	}
+	startMin := start.Hour()*60 + start.Minute()
+	endMin := end.Hour()*60 + end.Minute()
+	if startMin == endMin {
+		return 0, 0, fmt.Errorf("维护窗口起止时间不能相同: %q", window)
+	}
+	return startMin, endMin, nil
+}
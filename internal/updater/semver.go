@@ -0,0 +1,256 @@
+package updater
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// version 从镜像标签中解析出的版本号
+// 例如 v1.25.3-alpine 解析为 prefix=v, parts=[1 25 3], suffix=-alpine
+// 后缀以预发布标识开头时单独解析，如 1.2.0-rc.1-alpine 解析为 pre=rc, preNum=1, suffix=-alpine
+type version struct {
+	prefix string
+	parts  []int
+	pre    string // 预发布标识，正式版本为空
+	preNum int
+	suffix string
+}
+
+// comparator 单个版本约束，如 >=1.2、^1.4、~2.0、1.x
+type comparator struct {
+	op    string
+	parts []int
+	// specified 为明确指定的段数，x/* 及省略的段不计入
+	specified int
+}
+
+// constraint 版本约束，多个 comparator 之间为“与”关系
+type constraint []comparator
+
+var (
+	versionRegexp    = regexp.MustCompile(`^(v?)(\d+(?:\.\d+)*)([-_+].*)?$`)
+	comparatorRegexp = regexp.MustCompile(`^(!=|>=|<=|=|>|<|\^|~)?v?([0-9]+|[xX*])((?:\.(?:[0-9]+|[xX*]))*)$`)
+	operatorSpaceRe  = regexp.MustCompile(`(!=|>=|<=|=|>|<|\^|~)\s+`)
+	prereleaseRegexp = regexp.MustCompile(`(?i)^[-_.](alpha|beta|pre|rc)\.?(\d*)([-_+].*)?$`)
+)
+
+// preRank 预发布标识的先后顺序
+var preRank = map[string]int{"alpha": 1, "beta": 2, "pre": 3, "rc": 4}
+
+// parseVersion 解析镜像标签，无法解析时返回 false
+func parseVersion(tag string) (version, bool) {
+	m := versionRegexp.FindStringSubmatch(tag)
+	if m == nil {
+		return version{}, false
+	}
+
+	v := version{prefix: m[1], suffix: m[3]}
+	for _, p := range strings.Split(m[2], ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return version{}, false
+		}
+		v.parts = append(v.parts, n)
+	}
+
+	if m := prereleaseRegexp.FindStringSubmatch(v.suffix); m != nil {
+		v.pre = strings.ToLower(m[1])
+		v.preNum, _ = strconv.Atoi(m[2])
+		v.suffix = m[3]
+	}
+	return v, true
+}
+
+// sameVariant 判断两个版本是否属于同一变体（前缀、后缀、段数相同），不考虑预发布标识
+// 避免从 1.25-alpine 更新到 1.26 这样改变镜像变体的标签
+func (v version) sameVariant(o version) bool {
+	return v.prefix == o.prefix && v.suffix == o.suffix && len(v.parts) == len(o.parts)
+}
+
+// compare 比较两个版本，版本号相同时预发布版本低于正式版本
+func (v version) compare(o version) int {
+	if c := compareParts(v.parts, o.parts); c != 0 {
+		return c
+	}
+	switch {
+	case v.pre == o.pre:
+		return compareParts([]int{v.preNum}, []int{o.preNum})
+	case v.pre == "":
+		return 1
+	case o.pre == "":
+		return -1
+	}
+	return compareParts([]int{preRank[v.pre]}, []int{preRank[o.pre]})
+}
+
+// compareParts 比较两个版本号，缺少的段按 0 处理
+func compareParts(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseConstraint 解析版本约束，多个条件以空格或逗号分隔
+func parseConstraint(expr string) (constraint, error) {
+	expr = operatorSpaceRe.ReplaceAllString(strings.TrimSpace(expr), "$1")
+	fields := strings.FieldsFunc(expr, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("版本约束不能为空")
+	}
+
+	c := make(constraint, 0, len(fields))
+	for _, field := range fields {
+		m := comparatorRegexp.FindStringSubmatch(field)
+		if m == nil {
+			return nil, fmt.Errorf("无效的版本约束: %q", field)
+		}
+
+		cmp := comparator{op: m[1]}
+		if cmp.op == "" {
+			cmp.op = "="
+		}
+		wildcard := false
+		for _, p := range strings.Split(m[2]+m[3], ".") {
+			if p == "x" || p == "X" || p == "*" {
+				wildcard = true
+				cmp.parts = append(cmp.parts, 0)
+				continue
+			}
+			if wildcard {
+				return nil, fmt.Errorf("通配符之后不能再指定版本号: %q", field)
+			}
+			n, _ := strconv.Atoi(p)
+			cmp.parts = append(cmp.parts, n)
+			cmp.specified++
+		}
+		if cmp.specified == 0 && cmp.op != "=" {
+			return nil, fmt.Errorf("无效的版本约束: %q", field)
+		}
+		c = append(c, cmp)
+	}
+	return c, nil
+}
+
+// match 判断版本是否满足全部约束
+func (c constraint) match(v version) bool {
+	for _, cmp := range c {
+		if !cmp.match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// match 判断版本是否满足单个约束
+// 约束不含预发布标识，比较下界时预发布版本低于同版本号的正式版本，
+// 比较上界时只比较版本号，<2.0 不包含 2.0-rc1
+func (c comparator) match(v version) bool {
+	bound := version{parts: c.parts}
+	switch c.op {
+	case "=":
+		return c.prefixMatch(v.parts)
+	case "!=":
+		return !c.prefixMatch(v.parts)
+	case ">":
+		return v.compare(bound) > 0
+	case ">=":
+		return v.compare(bound) >= 0
+	case "<":
+		return compareParts(v.parts, c.parts) < 0
+	case "<=":
+		return v.compare(bound) <= 0
+	case "^":
+		// 不改变最左侧非零段：^1.2.3 => <2.0.0，^0.2.3 => <0.3.0
+		i := 0
+		for i < c.specified-1 && c.parts[i] == 0 {
+			i++
+		}
+		return v.compare(bound) >= 0 && compareParts(v.parts, c.upper(i)) < 0
+	case "~":
+		// 只允许补丁级更新：~1.2.3 => <1.3.0，~1 => <2.0.0
+		i := 1
+		if c.specified < 2 {
+			i = 0
+		}
+		return v.compare(bound) >= 0 && compareParts(v.parts, c.upper(i)) < 0
+	}
+	return false
+}
+
+// prefixMatch 判断版本号的前 specified 段是否与约束一致
+func (c comparator) prefixMatch(parts []int) bool {
+	if len(parts) < c.specified {
+		return false
+	}
+	for i := 0; i < c.specified; i++ {
+		if parts[i] != c.parts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// upper 返回第 i 段加一后的上界
+func (c comparator) upper(i int) []int {
+	if i < 0 {
+		i = 0
+	}
+	upper := make([]int, i+1)
+	copy(upper, c.parts[:min(i+1, len(c.parts))])
+	upper[i]++
+	return upper
+}
+
+// selectTag 从候选标签中选出满足策略且高于当前标签的最新版本
+// 当前标签是正式版本时不会更新到预发布版本，当前标签是预发布版本时可以更新到更高的预发布或正式版本
+// 当前标签无法解析为版本号（如 latest）时，选出满足条件的最高版本，
+// 此时未指定 pattern 则只考虑不带后缀的正式版本
+// 没有合适的标签时返回空字符串
+func selectTag(current string, tags []string, semver constraint, pattern *regexp.Regexp) string {
+	currentVersion, currentOK := parseVersion(current)
+
+	best := ""
+	var bestVersion version
+	for _, tag := range tags {
+		if pattern != nil && !pattern.MatchString(tag) {
+			continue
+		}
+		v, ok := parseVersion(tag)
+		if !ok {
+			continue
+		}
+		if currentOK {
+			if !v.sameVariant(currentVersion) || v.compare(currentVersion) <= 0 {
+				continue
+			}
+			if v.pre != "" && currentVersion.pre == "" {
+				continue
+			}
+		} else if pattern == nil && (v.suffix != "" || v.pre != "") {
+			// 无法判断当前变体时，只考虑不带后缀的正式版本
+			continue
+		}
+		if semver != nil && !semver.match(v) {
+			continue
+		}
+		if best == "" || v.compare(bestVersion) > 0 {
+			best, bestVersion = tag, v
+		}
+	}
+	return best
+}
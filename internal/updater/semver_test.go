@@ -0,0 +1,231 @@
+package updater
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		tag    string
+		ok     bool
+		prefix string
+		parts  []int
+		pre    string
+		preNum int
+		suffix string
+	}{
+		{tag: "1.25.3", ok: true, parts: []int{1, 25, 3}},
+		{tag: "v2.0", ok: true, prefix: "v", parts: []int{2, 0}},
+		{tag: "1.25-alpine", ok: true, parts: []int{1, 25}, suffix: "-alpine"},
+		{tag: "1.2.0-rc1", ok: true, parts: []int{1, 2, 0}, pre: "rc", preNum: 1},
+		{tag: "1.2.0-RC.2", ok: true, parts: []int{1, 2, 0}, pre: "rc", preNum: 2},
+		{tag: "1.2.0-beta-alpine", ok: true, parts: []int{1, 2, 0}, pre: "beta", suffix: "-alpine"},
+		{tag: "1.2.0-preview", ok: true, parts: []int{1, 2, 0}, suffix: "-preview"},
+		{tag: "latest", ok: false},
+		{tag: "alpine-1.2", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			v, ok := parseVersion(tt.tag)
+			if ok != tt.ok {
+				t.Fatalf("parseVersion(%q) ok = %v，期望 %v", tt.tag, ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if v.prefix != tt.prefix || compareParts(v.parts, tt.parts) != 0 || len(v.parts) != len(tt.parts) ||
+				v.pre != tt.pre || v.preNum != tt.preNum || v.suffix != tt.suffix {
+				t.Fatalf("parseVersion(%q) = %+v", tt.tag, v)
+			}
+		})
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// 按从低到高排列
+	ordered := []string{"1.2.0-alpha", "1.2.0-alpha.2", "1.2.0-beta.1", "1.2.0-rc1", "1.2.0-rc2", "1.2.0-rc10", "1.2.0", "1.2.1-rc1", "1.2.1"}
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := parseVersion(ordered[i])
+			b, _ := parseVersion(ordered[j])
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := a.compare(b); got != want {
+				t.Errorf("compare(%s, %s) = %d，期望 %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
+
+func TestConstraintMatch(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		// ^ 不改变最左侧非零段
+		{"^1.2.3", "1.2.3", true},
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "2.0.0", false},
+		{"^1.2.3", "1.2.2", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^0", "0.9.0", true},
+		{"^0", "1.0.0", false},
+		{"^0.x", "0.5", true},
+		{"^0.x", "1.0", false},
+		{"^0.2", "0.2.7", true},
+		{"^0.2", "0.3.0", false},
+		// ~ 只允许补丁级更新，只指定主版本号时允许次版本更新
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~1.2", "1.2.0", true},
+		{"~1.2", "1.3.0", false},
+		{"~1", "1.9", true},
+		{"~1", "2.0", false},
+		{"~0.2", "0.2.5", true},
+		{"~0.2", "0.3", false},
+		// 通配符和前缀匹配
+		{"1.x", "1.5.2", true},
+		{"1.x", "2.0.0", false},
+		{"1.2", "1.2.7", true},
+		{"1.2", "1.20", false},
+		{"*", "9.9.9", true},
+		{"!=1.2", "1.2.3", false},
+		{"!=1.2", "1.3.0", true},
+		// 多个条件同时满足，允许空格和逗号
+		{">=1.2 <2", "1.9.9", true},
+		{">= 1.2, < 2", "2.0", false},
+		{">1.2 <=1.4", "1.2.0", false},
+		{">1.2 <=1.4", "1.4.0", true},
+		// 预发布版本低于同版本号的正式版本，上界不包含其预发布版本
+		{">=1.2.0", "1.2.0-rc1", false},
+		{">=1.2.0", "1.2.1-rc1", true},
+		{"<=1.2.0", "1.2.0-rc1", true},
+		{"<2", "2.0.0-rc1", false},
+		{"^1.2", "2.0.0-beta", false},
+		{"~1.2.0", "1.2.1-rc1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			c, err := parseConstraint(tt.constraint)
+			if err != nil {
+				t.Fatalf("parseConstraint(%q) 失败: %v", tt.constraint, err)
+			}
+			v, ok := parseVersion(tt.version)
+			if !ok {
+				t.Fatalf("parseVersion(%q) 失败", tt.version)
+			}
+			if got := c.match(v); got != tt.want {
+				t.Fatalf("%q 匹配 %q = %v，期望 %v", tt.constraint, tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	for _, expr := range []string{"", "  ", ">=", "^x", "1.x.2", "~>1.2", "latest"} {
+		if _, err := parseConstraint(expr); err == nil {
+			t.Errorf("parseConstraint(%q) 应返回错误", expr)
+		}
+	}
+}
+
+func TestSelectTag(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    string
+		tags       []string
+		constraint string
+		pattern    string
+		want       string
+	}{
+		{
+			name:    "选出最高版本",
+			current: "1.1.0",
+			tags:    []string{"1.0.0", "1.1.0", "1.2.0", "1.10.0", "latest"},
+			want:    "1.10.0",
+		},
+		{
+			name:    "没有更高版本",
+			current: "2.0.0",
+			tags:    []string{"1.9.0", "2.0.0"},
+			want:    "",
+		},
+		{
+			name:    "保持变体",
+			current: "1.25-alpine",
+			tags:    []string{"1.26", "1.26-alpine", "1.26.1-alpine", "1.27-bookworm"},
+			want:    "1.26-alpine",
+		},
+		{
+			name:    "保持前缀",
+			current: "v1.2.0",
+			tags:    []string{"1.3.0", "v1.2.1"},
+			want:    "v1.2.1",
+		},
+		{
+			name:    "正式版本不更新到预发布版本",
+			current: "1.1.0",
+			tags:    []string{"1.2.0-rc1", "1.2.0", "1.3.0-beta"},
+			want:    "1.2.0",
+		},
+		{
+			name:    "预发布版本更新到正式版本",
+			current: "1.2.0-rc1",
+			tags:    []string{"1.2.0-rc2", "1.2.0", "1.1.9"},
+			want:    "1.2.0",
+		},
+		{
+			name:    "预发布版本更新到更高的预发布版本",
+			current: "1.2.0-beta.1",
+			tags:    []string{"1.2.0-alpha.5", "1.2.0-beta.2", "1.2.0-rc.1"},
+			want:    "1.2.0-rc.1",
+		},
+		{
+			name:       "版本约束",
+			current:    "1.2.0",
+			tags:       []string{"1.2.5", "1.3.0", "2.0.0"},
+			constraint: "~1.2",
+			want:       "1.2.5",
+		},
+		{
+			name:    "无法解析的当前标签只考虑正式版本",
+			current: "latest",
+			tags:    []string{"1.2.0", "1.3.0-rc1", "1.2.1-alpine", "1.2.1"},
+			want:    "1.2.1",
+		},
+		{
+			name:    "指定匹配规则时允许后缀",
+			current: "latest",
+			tags:    []string{"1.2.0", "1.2.1-alpine", "1.3.0-alpine"},
+			pattern: `-alpine$`,
+			want:    "1.3.0-alpine",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c constraint
+			if tt.constraint != "" {
+				var err error
+				if c, err = parseConstraint(tt.constraint); err != nil {
+					t.Fatalf("parseConstraint(%q) 失败: %v", tt.constraint, err)
+				}
+			}
+			var pattern *regexp.Regexp
+			if tt.pattern != "" {
+				pattern = regexp.MustCompile(tt.pattern)
+			}
+			if got := selectTag(tt.current, tt.tags, c, pattern); got != tt.want {
+				t.Fatalf("selectTag = %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
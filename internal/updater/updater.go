@@ -0,0 +1,413 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"rubick/internal/cron"
+	"rubick/internal/docker"
+	"rubick/internal/model"
+	"rubick/internal/notify"
+	"rubick/internal/repository"
+
+	"github.com/distribution/reference"
+	"github.com/docker/docker/client"
+)
+
+// 更新记录状态
+const (
+	StatusUpdated    = "updated"
+	StatusRolledBack = "rolled_back"
+	StatusFailed     = "failed"
+)
+
+// pollInterval 检查到期策略的间隔
+const pollInterval = 30 * time.Second
+
+// runTimeout 单次策略执行的最长时间
+const runTimeout = time.Hour
+
+// rollbackTimeout 回滚的最长时间，回滚不受执行超时影响，但不能因 Docker 无响应而一直占用执行权
+const rollbackTimeout = 2 * time.Minute
+
+// Updater 自动更新调度器
+type Updater struct {
+	runner *cron.Runner[model.UpdatePolicy]
+}
+
+var (
+	updater     *Updater
+	updaterOnce sync.Once
+)
+
+// GetUpdater 获取自动更新调度器单例
+func GetUpdater() *Updater {
+	updaterOnce.Do(func() {
+		updater = &Updater{}
+		updater.runner = cron.NewRunner("更新策略", pollInterval, cron.Jobs[model.UpdatePolicy]{
+			Due:      repository.ListDueUpdatePolicies,
+			Describe: describe,
+			Claim:    repository.ClaimUpdatePolicyRun,
+			Fire:     updater.fire,
+		})
+	})
+	return updater
+}
+
+// Start 启动调度循环
+func (u *Updater) Start() {
+	u.runner.Start()
+}
+
+// Stop 停止调度并等待正在执行的更新结束
+func (u *Updater) Stop() {
+	u.runner.Stop()
+}
+
+// Trigger 立即执行策略，不受维护窗口限制
+func (u *Updater) Trigger(policyID string) error {
+	policy, err := repository.GetUpdatePolicyByID(policyID)
+	if err != nil {
+		return err
+	}
+	if !u.launch(policy) {
+		return fmt.Errorf("策略正在执行中")
+	}
+	return nil
+}
+
+// IsRunning 判断策略是否正在执行
+func (u *Updater) IsRunning(policyID string) bool {
+	return u.runner.IsRunning(policyID)
+}
+
+// describe 返回策略的调度信息
+func describe(p *model.UpdatePolicy) cron.Job {
+	return cron.Job{
+		ID:        p.ID,
+		Name:      p.Name,
+		Expr:      p.Schedule,
+		Timezone:  p.Timezone,
+		Enabled:   p.Enabled,
+		Scheduled: *p.NextRunAt,
+	}
+}
+
+// fire 执行已抢占的到期策略，不在维护窗口内时跳过
+func (u *Updater) fire(policy *model.UpdatePolicy, scheduled, now time.Time) {
+	if !InMaintenanceWindow(policy, now) {
+		log.Printf("更新策略 %s 不在维护窗口内，跳过本次执行", policy.Name)
+		return
+	}
+	u.launch(policy)
+}
+
+// launch 在后台执行策略，同一策略同时只执行一次
+func (u *Updater) launch(policy *model.UpdatePolicy) bool {
+	if !u.runner.Acquire(policy.ID) {
+		return false
+	}
+
+	u.runner.Go(policy.ID, runTimeout, func(ctx context.Context) {
+		if err := u.run(ctx, policy); err != nil {
+			log.Printf("执行更新策略 %s 失败: %v", policy.Name, err)
+			u.finish(policy, &model.UpdateRecord{
+				TargetType: policy.TargetType,
+				Target:     policy.Name,
+				Status:     StatusFailed,
+				Message:    err.Error(),
+			})
+		}
+	})
+	return true
+}
+
+// run 执行一次策略
+func (u *Updater) run(ctx context.Context, policy *model.UpdatePolicy) error {
+	host := policy.Host
+	if host == nil {
+		var err error
+		if host, err = repository.GetHostByID(policy.HostID); err != nil {
+			return fmt.Errorf("主机不存在: %w", err)
+		}
+	}
+
+	cli, err := docker.GetManager().GetDockerClient(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	switch policy.TargetType {
+	case TargetContainer:
+		return u.runContainers(ctx, policy, docker.NewContainerService(cli), docker.NewImageService(cli))
+	case TargetCompose:
+		return u.runCompose(ctx, policy, host, cli)
+	default:
+		return fmt.Errorf("无效的目标类型: %s", policy.TargetType)
+	}
+}
+
+// runContainers 更新标签选择器匹配的所有运行中容器
+func (u *Updater) runContainers(ctx context.Context, policy *model.UpdatePolicy, containers *docker.ContainerService, images *docker.ImageService) error {
+	list, err := containers.ListByLabel(ctx, false, docker.ParseLabelSelector(policy.LabelSelector)...)
+	if err != nil {
+		return err
+	}
+
+	var semver constraint
+	if policy.SemverConstraint != "" {
+		if semver, err = parseConstraint(policy.SemverConstraint); err != nil {
+			return err
+		}
+	}
+	var pattern *regexp.Regexp
+	if policy.TagPattern != "" {
+		if pattern, err = regexp.Compile(policy.TagPattern); err != nil {
+			return fmt.Errorf("无效的标签匹配正则: %w", err)
+		}
+	}
+
+	for _, c := range list {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		record := u.updateContainer(ctx, policy, containers, images, c.ID, semver, pattern)
+		if record != nil {
+			u.finish(policy, record)
+		}
+	}
+	return nil
+}
+
+// updateContainer 更新单个容器，没有可用更新时返回 nil
+func (u *Updater) updateContainer(ctx context.Context, policy *model.UpdatePolicy, containers *docker.ContainerService, images *docker.ImageService, containerID string, semver constraint, pattern *regexp.Regexp) *model.UpdateRecord {
+	info, err := containers.Get(ctx, containerID)
+	if err != nil {
+		return &model.UpdateRecord{TargetType: TargetContainer, Target: containerID, Status: StatusFailed, Message: err.Error()}
+	}
+
+	record := &model.UpdateRecord{
+		TargetType: TargetContainer,
+		Target:     strings.TrimPrefix(info.Name, "/"),
+		OldImage:   info.Image,
+		NewImage:   info.Image,
+		OldImageID: info.ImageID,
+	}
+	fail := func(err error) *model.UpdateRecord {
+		record.Status = StatusFailed
+		record.Message = err.Error()
+		return record
+	}
+
+	if semver != nil || pattern != nil {
+		target, err := resolveTag(ctx, info.Image, semver, pattern)
+		if err != nil {
+			return fail(err)
+		}
+		if target != "" {
+			record.NewImage = target
+		}
+	}
+
+	if record.NewImage == record.OldImage {
+		status, err := images.CheckUpdate(ctx, docker.PullOptions{Image: record.OldImage})
+		if err != nil {
+			return fail(err)
+		}
+		// 本地标签可能已被其他操作更新，此时无需拉取也要重建
+		if !status.UpdateAvailable && (status.LocalID == "" || status.LocalID == info.ImageID) {
+			return nil
+		}
+	}
+
+	if err := images.PullAndWait(ctx, docker.PullOptions{Image: record.NewImage}); err != nil {
+		return fail(err)
+	}
+	newImage, err := images.Get(ctx, record.NewImage)
+	if err != nil {
+		return fail(err)
+	}
+	if newImage.ID == info.ImageID {
+		return nil
+	}
+	record.NewImageID = newImage.ID
+
+	result, err := containers.Recreate(ctx, containerID, docker.RecreateOptions{Image: record.NewImage})
+	if err != nil {
+		return fail(err)
+	}
+
+	if result.WasRunning {
+		grace := time.Duration(policy.HealthGracePeriod) * time.Second
+		if err := containers.WaitHealthy(ctx, result.NewID, grace); err != nil {
+			if policy.Rollback {
+				rbCtx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+				rbErr := containers.RollbackRecreate(rbCtx, result)
+				cancel()
+				if rbErr != nil {
+					return fail(fmt.Errorf("%v，回滚失败: %v", err, rbErr))
+				}
+				record.Status = StatusRolledBack
+				record.Message = err.Error()
+				return record
+			}
+			containers.CommitRecreate(ctx, result)
+			return fail(err)
+		}
+	}
+
+	if err := containers.CommitRecreate(ctx, result); err != nil {
+		record.Message = err.Error()
+	}
+	record.Status = StatusUpdated
+	return record
+}
+
+// resolveTag 查询镜像仓库，返回满足约束的新镜像引用，没有更新的标签时返回空字符串
+func resolveTag(ctx context.Context, image string, semver constraint, pattern *regexp.Regexp) (string, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", fmt.Errorf("解析镜像名称失败: %w", err)
+	}
+	tagged, ok := reference.TagNameOnly(named).(reference.Tagged)
+	if !ok {
+		// 通过摘要固定的镜像不参与标签更新
+		return "", nil
+	}
+
+	tags, err := docker.ListTags(ctx, docker.PullOptions{Image: image})
+	if err != nil {
+		return "", err
+	}
+
+	tag := selectTag(tagged.Tag(), tags, semver, pattern)
+	if tag == "" || tag == tagged.Tag() {
+		return "", nil
+	}
+
+	newRef, err := reference.WithTag(reference.TrimNamed(named), tag)
+	if err != nil {
+		return "", err
+	}
+	return reference.FamiliarString(newRef), nil
+}
+
+// runCompose 更新 Compose 项目中有新镜像的服务
+func (u *Updater) runCompose(ctx context.Context, policy *model.UpdatePolicy, host *model.Host, cli *client.Client) error {
+	project, err := repository.GetComposeProjectByID(policy.ProjectID)
+	if err != nil {
+		return fmt.Errorf("Compose 项目不存在: %w", err)
+	}
+	if project.HostID != policy.HostID {
+		return fmt.Errorf("Compose 项目不属于策略所在主机")
+	}
+
+	executor, err := docker.NewHostExecutor(host)
+	if err != nil {
+		return err
+	}
+	defer executor.Close()
+
+	service := docker.NewUpdateService(cli, executor)
+	opts := docker.ComposeOptionsForProject(project)
+
+	result, err := service.UpdateCompose(ctx, project.Content, opts, nil)
+	if err != nil {
+		return err
+	}
+
+	status, message := StatusUpdated, ""
+	if len(result.Updated) > 0 {
+		grace := time.Duration(policy.HealthGracePeriod) * time.Second
+		if err := service.WaitComposeHealthy(ctx, result, grace); err != nil {
+			status, message = StatusFailed, err.Error()
+			if policy.Rollback {
+				rbCtx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+				rbErr := service.RollbackCompose(rbCtx, project.Content, opts, result)
+				cancel()
+				if rbErr != nil {
+					message = fmt.Sprintf("%v，回滚失败: %v", err, rbErr)
+				} else {
+					status = StatusRolledBack
+				}
+			}
+		}
+	}
+
+	updated := make(map[string]bool, len(result.Updated))
+	for _, name := range result.Updated {
+		updated[name] = true
+	}
+	for _, svc := range result.Services {
+		record := &model.UpdateRecord{
+			TargetType: TargetCompose,
+			Target:     project.Name,
+			Service:    svc.Service,
+			OldImage:   svc.Image,
+			NewImage:   svc.Image,
+			OldImageID: svc.LocalID,
+		}
+		switch {
+		case updated[svc.Service]:
+			record.Status, record.Message = status, message
+		case svc.UpdateAvailable && svc.Error != "":
+			record.Status, record.Message = StatusFailed, svc.Error
+		default:
+			continue
+		}
+		u.finish(policy, record)
+	}
+	return nil
+}
+
+// finish 保存更新记录，写入审计日志并发送通知
+func (u *Updater) finish(policy *model.UpdatePolicy, record *model.UpdateRecord) {
+	record.PolicyID = policy.ID
+	record.HostID = policy.HostID
+	if err := repository.CreateUpdateRecord(record); err != nil {
+		log.Printf("保存更新记录失败: %v", err)
+	}
+
+	target := record.Target
+	if record.Service != "" {
+		target += "/" + record.Service
+	}
+	message := fmt.Sprintf("自动更新 %s: %s -> %s (%s)", target, record.OldImage, record.NewImage, record.Status)
+	if record.Message != "" {
+		message += ": " + record.Message
+	}
+
+	status := http.StatusOK
+	if record.Status != StatusUpdated {
+		status = http.StatusInternalServerError
+	}
+	repository.CreateAuditLog(&model.AuditLog{
+		Method:  "SYSTEM",
+		Path:    "/api/v1/updates/policies/" + policy.ID,
+		Status:  status,
+		Message: message,
+	})
+
+	event := notify.Event{
+		Type:    notify.EventUpdateSucceeded,
+		HostID:  policy.HostID,
+		Message: message,
+		Data:    record,
+	}
+	switch record.Status {
+	case StatusRolledBack:
+		event.Type = notify.EventUpdateRolledBack
+	case StatusFailed:
+		event.Type = notify.EventUpdateFailed
+	}
+	if policy.Host != nil {
+		event.HostName = policy.Host.Name
+	}
+	notify.Dispatch(event)
+}